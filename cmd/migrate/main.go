@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"log"
+	app_interface "seal/internal/app/interface"
 	"seal/internal/config"
 	"seal/internal/repository/pg"
 	"seal/internal/service/logger"
+	"seal/pkg/storage"
 
 	"os"
 )
@@ -35,9 +39,39 @@ func main() {
 	source := "file://" + cfg.Database.Psql.MigrationPath
 	if len(args) > 2 && args[1] == "create" {
 		pg.Create(cfg.Database.Psql.MigrationPath, args[2], appLog)
+	} else if len(args) > 3 && args[1] == "storage" {
+		migrateStorage(cfg, args[2], args[3], len(args) > 4 && args[4] == "remove", appLog)
 	} else if len(args) > 1 && args[1] == "down" {
 		pg.RunMigrateDown(source, cfg.Database.Psql.Dsn, appLog)
 	} else {
 		pg.RunMigrateUp(source, cfg.Database.Psql.Dsn, appLog)
 	}
 }
+
+// migrateStorage переносит файлы перевозок между хранилищами: storage <from> <to> [remove]
+func migrateStorage(cfg *config.Config, fromType, toType string, remove bool, appLog app_interface.Logger) {
+	ctx := context.Background()
+
+	from, err := storage.New(ctx, cfg.StorageParams(fromType))
+	if err != nil {
+		appLog.Fatal(err.Error())
+	}
+
+	to, err := storage.New(ctx, cfg.StorageParams(toType))
+	if err != nil {
+		appLog.Fatal(err.Error())
+	}
+
+	count, err := storage.Migrate(from, to, "", remove, func(key string, err error) {
+		if err != nil {
+			appLog.Error(fmt.Sprintf("Storage migrate %s: %v", key, err))
+		} else {
+			appLog.Info(fmt.Sprintf("Storage migrate %s: ok", key))
+		}
+	})
+	if err != nil {
+		appLog.Fatal(err.Error())
+	}
+
+	appLog.Info(fmt.Sprintf("Storage migrate %s -> %s: %d files", fromType, toType, count))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"seal/internal/config"
+	"seal/pkg/storage/storagetest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct {
+	t      *testing.T
+	errors int
+}
+
+func (l *testLogger) Fatal(msg string, args ...any) { l.t.Fatal(msg) }
+func (l *testLogger) Error(msg string, args ...any) { l.errors++ }
+func (l *testLogger) Info(msg string, args ...any)  {}
+func (l *testLogger) Debug(msg string, args ...any) {}
+func (l *testLogger) DebugOrError(err error, msg string, args ...any) {
+	if err != nil {
+		l.errors++
+	}
+}
+
+func TestMigrateStorage(t *testing.T) {
+	server := storagetest.NewS3()
+	defer server.Close()
+
+	cfg := &config.Config{ShippingFilesPath: t.TempDir()}
+	cfg.Storage.S3.Endpoint = server.Endpoint()
+	cfg.Storage.S3.Bucket = "files"
+	cfg.Storage.S3.Region = "us-east-1"
+	cfg.Storage.S3.Prefix = "shipping"
+
+	require.NoError(t, os.MkdirAll(filepath.Join(cfg.ShippingFilesPath, "1"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.ShippingFilesPath, "1", "act.pdf"), []byte("pdf"), 0640))
+
+	appLog := &testLogger{t: t}
+
+	// копирование: файл остаётся в исходном хранилище
+	migrateStorage(cfg, "local", "s3", false, appLog)
+	assert.Equal(t, []string{"shipping/1/act.pdf"}, server.Keys("files"))
+	assert.FileExists(t, filepath.Join(cfg.ShippingFilesPath, "1", "act.pdf"))
+
+	// перенос обратно с удалением из s3
+	require.NoError(t, os.RemoveAll(filepath.Join(cfg.ShippingFilesPath, "1")))
+	migrateStorage(cfg, "s3", "local", true, appLog)
+
+	data, err := os.ReadFile(filepath.Join(cfg.ShippingFilesPath, "1", "act.pdf"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pdf", string(data))
+	assert.Empty(t, server.Keys("files"))
+	assert.Equal(t, 0, appLog.errors)
+}
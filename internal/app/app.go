@@ -17,7 +17,9 @@ import (
 	"seal/internal/transport/rest"
 	"seal/internal/transport/rest/middleware"
 	pgClient "seal/pkg/client/pg"
+	"seal/pkg/storage"
 	"seal/pkg/validator"
+	"sync"
 	"syscall"
 	"time"
 
@@ -34,11 +36,16 @@ type App struct {
 	Usecase   *usecase.Usecase
 	Validator app_interface.Validator
 	Ctx       context.Context
+
+	stop  context.CancelFunc
+	tasks *sync.WaitGroup
 }
 
 func Get(cfg *config.Config) *App {
 
 	ctx := context.Background()
+	background, stop := context.WithCancel(ctx)
+	tasks := &sync.WaitGroup{}
 
 	db := pgClient.NewClient(ctx, cfg.Database.Psql.Dsn)
 
@@ -56,6 +63,11 @@ func Get(cfg *config.Config) *App {
 
 	appValidator := validator.NewValidator()
 
+	filesStorage, err := storage.New(ctx, cfg.StorageParams(""))
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	return &App{
 		cfg,
 		gin.New(),
@@ -63,25 +75,42 @@ func Get(cfg *config.Config) *App {
 		db,
 		appTransport.NewJwtWorker(cfg.Jwt.Secret, cfg.Jwt.Ttl, cfg.Jwt.TtlRefresh),
 		usecase.GetUsecase(usecase.Params{
-			Ctx:       ctx,
-			Db:        db,
-			Cfg:       cfg,
-			Logger:    appLog,
-			Validator: appValidator,
+			Ctx:        ctx,
+			Db:         db,
+			Cfg:        cfg,
+			Logger:     appLog,
+			Validator:  appValidator,
+			Storage:    filesStorage,
+			Background: background,
+			Tasks:      tasks,
 		}),
 		appValidator,
 		ctx,
+		stop,
+		tasks,
 	}
 }
 
 func (app *App) Clean() {
 	defer app.Db.Close()
+
+	// фоновые задачи дописывают результаты в БД и отправляют начатые команды, поэтому ждём их до закрытия
+	app.stop()
+	app.tasks.Wait()
+
+	if err := app.Usecase.CommandsService.Close(); err != nil {
+		app.Logger.Error(err.Error())
+	}
 }
 
 func (app *App) Run() {
 	source := "file://" + app.Cfg.Database.Psql.MigrationPath
 	pg.RunMigrateUp(source, app.Cfg.Database.Psql.Dsn, app.Logger)
 
+	if err := app.Usecase.CommandJob.Resume(); err != nil {
+		app.Logger.Error("Ошибка возобновления массовых отправок: " + err.Error())
+	}
+
 	if app.Cfg.Logger.Level == "debug" {
 		app.Router.Use(middleware.Logger(app.Logger))
 	}
@@ -109,12 +138,16 @@ func (app *App) Run() {
 	stopServices := make(chan bool)
 
 	if app.Cfg.RunCheckTelemetry {
-		service.RunCheckTelemetry(service.Params{
+		params := service.Params{
 			Ctx:      app.Ctx,
 			Db:       app.Db,
 			Logger:   app.Logger,
+			Usecase:  app.Usecase,
 			StopChan: stopServices,
-		})
+		}
+
+		service.RunCheckTelemetry(params)
+		service.RunCheckSchedules(params)
 	}
 
 	go func() {
@@ -130,7 +163,7 @@ func (app *App) Run() {
 	log.Println("Shutdown Server ...")
 
 	if app.Cfg.RunCheckTelemetry {
-		stopServices <- true
+		close(stopServices)
 	}
 
 	timeOut, err := time.ParseDuration(app.Cfg.ShutdownTimeout)
@@ -4,39 +4,70 @@ import (
 	"context"
 	app_interface "seal/internal/app/interface"
 	"seal/internal/config"
+	"seal/internal/domain/battery"
+	"seal/internal/domain/command"
+	"seal/internal/domain/command_job"
 	"seal/internal/domain/custom"
+	customEvent "seal/internal/domain/custom_event"
+	"seal/internal/domain/deviation"
+	"seal/internal/domain/eta"
+	"seal/internal/domain/incident"
 	"seal/internal/domain/modem"
 	modemData "seal/internal/domain/modem_data"
 	modemLogRaw "seal/internal/domain/modem_log_raw"
+	"seal/internal/domain/odometer"
+	"seal/internal/domain/report"
 	"seal/internal/domain/route"
+	"seal/internal/domain/schedule"
 	"seal/internal/domain/seal"
 	sealData "seal/internal/domain/seal_data"
 	"seal/internal/domain/seal_model"
 	"seal/internal/domain/secret_area"
 	"seal/internal/domain/shipping"
+	"seal/internal/domain/status_flag"
+	"seal/internal/domain/stop"
 	"seal/internal/domain/transport"
 	"seal/internal/domain/transport_type"
+	"seal/internal/domain/upload_token"
 	"seal/internal/domain/user"
 	"seal/internal/transport/commands"
 	"seal/pkg/hash"
+	"seal/pkg/storage"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Usecase struct {
+	Battery       battery.Usecase
+	Command       command.Usecase
+	CommandJob    command_job.Usecase
 	User          user.Usecase
 	Route         route.Usecase
 	Custom        custom.Usecase
+	CustomEvent   customEvent.Usecase
+	Deviation     deviation.Usecase
+	Eta           eta.Usecase
+	Incident      incident.Usecase
 	Seal          seal.Usecase
 	SealData      sealData.Usecase
 	SealModel     seal_model.Usecase
 	SecretArea    secret_area.Usecase
 	Shipping      shipping.Usecase
+	StatusFlag    status_flag.Usecase
+	Stop          stop.Usecase
 	Transport     transport.Usecase
 	TransportType transport_type.Usecase
+	UploadToken   upload_token.Usecase
 	Modem         modem.Usecase
 	ModemData     modemData.Usecase
 	ModemLogRaw   modemLogRaw.Usecase
+	Odometer      odometer.Usecase
+	Report        report.Usecase
+	Schedule      schedule.Usecase
+
+	CommandsService commands.Service
 }
 
 type Params struct {
@@ -45,6 +76,10 @@ type Params struct {
 	Cfg       *config.Config
 	Logger    app_interface.Logger
 	Validator app_interface.Validator
+	Storage   storage.Storage
+	// Background отменяется при остановке приложения, Tasks - фоновые задачи, которых ждёт остановка
+	Background context.Context
+	Tasks      *sync.WaitGroup
 }
 
 func GetUsecase(params Params) *Usecase {
@@ -77,30 +112,109 @@ func GetUsecase(params Params) *Usecase {
 	modemLogRawRepo := modemLogRaw.NewRepo(params.Ctx, params.Db, params.Logger)
 	usecase.ModemLogRaw = modemLogRaw.NewUsecase(modemLogRawRepo, params.Logger, params.Validator)
 
-	cmd := commands.NewGRPC(params.Ctx, params.Cfg.GRPCCommands.Addr, params.Cfg.GRPCCommands.Timeout, params.Logger)
+	cmd := commands.NewGRPC(params.Ctx, params.Cfg.GRPCCommands.Addr, params.Cfg.GRPCCommands.Timeout,
+		params.Cfg.GRPCCommands.Keepalive, params.Logger)
+	usecase.CommandsService = cmd
+
+	commandRepo := command.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.Command = command.NewUsecase(commandRepo, params.Logger, params.Validator,
+		params.Cfg.GRPCCommands.BulkConcurrency, command.CoreUseCase{Commands: cmd})
+
+	statusFlagRepo := status_flag.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.StatusFlag = status_flag.NewUsecase(statusFlagRepo, params.Logger)
+
+	batteryRepo := battery.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.Battery = battery.NewUsecase(batteryRepo, params.Logger, params.Validator)
 
 	modemRepo := modem.NewRepo(params.Ctx, params.Db, params.Logger)
-	usecase.Modem = modem.NewUsecase(modemRepo, params.Logger, params.Validator, modem.CoreUseCase{
+	usecase.Modem = modem.NewUsecase(modemRepo, params.Logger, params.Validator, modem.Connectivity{
+		PeriodUnit:    params.Cfg.Connectivity.PeriodUnit,
+		Grace:         params.Cfg.Connectivity.Grace,
+		OfflineFactor: params.Cfg.Connectivity.OfflineFactor,
+	}, modem.CoreUseCase{
+		Battery:     usecase.Battery,
+		Command:     usecase.Command,
 		Commands:    cmd,
 		ModemData:   usecase.ModemData,
 		ModemLogRaw: usecase.ModemLogRaw,
+		StatusFlag:  usecase.StatusFlag,
 	})
 
-	shippingRepo := shipping.NewRepo(params.Ctx, params.Db, params.Logger)
-	usecase.Shipping = shipping.NewUsecase(shippingRepo, params.Logger, params.Validator, params.Cfg.ShippingFilesPath, shipping.CoreUseCase{
-		User:      usecase.User,
-		Route:     usecase.Route,
-		Seal:      usecase.Seal,
-		Transport: usecase.Transport,
-		Modem:     usecase.Modem,
-		ModemData: usecase.ModemData,
+	commandJobRepo := command_job.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.CommandJob = command_job.NewUsecase(commandJobRepo, params.Logger, params.Validator,
+		params.Cfg.GRPCCommands.BulkConcurrency, params.Background, params.Tasks, command_job.CoreUseCase{
+			Catalog:  usecase.Command,
+			Commands: cmd,
+		})
+
+	scheduleRepo := schedule.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.Schedule = schedule.NewUsecase(scheduleRepo, params.Logger, params.Validator, schedule.CoreUseCase{
+		Command:  usecase.Command,
+		Commands: cmd,
 	})
 
 	sealDataRepo := sealData.NewRepo(params.Ctx, params.Db, params.Logger)
 	usecase.SealData = sealData.NewUsecase(sealDataRepo, params.Logger, params.Validator)
 
 	sealRepo := seal.NewRepo(params.Ctx, params.Db, params.Logger)
-	usecase.Seal = seal.NewUsecase(sealRepo, params.Logger, params.Validator, seal.CoreUseCase{SealData: usecase.SealData})
+	usecase.Seal = seal.NewUsecase(sealRepo, params.Logger, params.Validator, seal.CoreUseCase{
+		Battery:  usecase.Battery,
+		SealData: usecase.SealData,
+	})
+
+	shippingRepo := shipping.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.Shipping = shipping.NewUsecase(shippingRepo, params.Logger, params.Validator, params.Storage, shipping.CoreUseCase{
+		User:       usecase.User,
+		Route:      usecase.Route,
+		Seal:       usecase.Seal,
+		Transport:  usecase.Transport,
+		Modem:      usecase.Modem,
+		ModemData:  usecase.ModemData,
+		StatusFlag: usecase.StatusFlag,
+	})
+
+	uploadTokenRepo := upload_token.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.UploadToken = upload_token.NewUsecase(uploadTokenRepo, params.Logger, params.Validator, upload_token.CoreUseCase{
+		Shipping: usecase.Shipping,
+	})
+
+	deviationRepo := deviation.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.Deviation = deviation.NewUsecase(deviationRepo, params.Logger, params.Validator, deviation.CoreUseCase{
+		Shipping: usecase.Shipping,
+	})
+
+	usecase.Report = report.NewUsecase(params.Logger, params.Validator, report.CoreUseCase{
+		Shipping:  usecase.Shipping,
+		Deviation: usecase.Deviation,
+	})
+
+	customEventRepo := customEvent.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.CustomEvent = customEvent.NewUsecase(customEventRepo, params.Logger, params.Validator,
+		time.Duration(params.Cfg.Customs.AutoEndDwell)*time.Minute, customEvent.CoreUseCase{
+			Shipping: usecase.Shipping,
+		})
+
+	stopRepo := stop.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.Stop = stop.NewUsecase(stopRepo, params.Logger, params.Validator, stop.CoreUseCase{
+		Shipping: usecase.Shipping,
+		Modem:    usecase.Modem,
+	})
+
+	odometerRepo := odometer.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.Odometer = odometer.NewUsecase(odometerRepo, params.Logger, params.Validator, odometer.Filter{
+		MaxHdop:       params.Cfg.Odometer.MaxHdop,
+		MinSatellites: params.Cfg.Odometer.MinSatellites,
+		MaxSpeed:      params.Cfg.Odometer.MaxSpeed,
+	}, odometer.CoreUseCase{
+		Shipping: usecase.Shipping,
+		Modem:    usecase.Modem,
+	})
+
+	etaRepo := eta.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.Eta = eta.NewUsecase(etaRepo, params.Logger, params.Validator)
+
+	incidentRepo := incident.NewRepo(params.Ctx, params.Db, params.Logger)
+	usecase.Incident = incident.NewUsecase(incidentRepo, params.Logger, params.Validator)
 
 	return &usecase
 }
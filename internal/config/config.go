@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"seal/pkg/storage"
 	"sync"
 
 	"gopkg.in/yaml.v2"
@@ -39,8 +40,10 @@ type Config struct {
 		Port              int  `yaml:"port"`
 	} `yaml:"router"`
 	GRPCCommands struct {
-		Addr    string `yaml:"addr"`
-		Timeout uint8  `yaml:"timeout"`
+		Addr            string `yaml:"addr"`
+		Timeout         uint8  `yaml:"timeout"`
+		Keepalive       uint16 `yaml:"keepalive"`
+		BulkConcurrency int    `yaml:"bulk_concurrency"`
 	} `yaml:"grpc_commands"`
 	Test struct {
 		User struct {
@@ -48,6 +51,31 @@ type Config struct {
 			Password string `yaml:"password"`
 		}
 	} `json:"test"`
+	Customs struct {
+		AutoEndDwell int `yaml:"auto_end_dwell"`
+	} `yaml:"customs"`
+	Odometer struct {
+		MaxHdop       int `yaml:"max_hdop"`
+		MinSatellites int `yaml:"min_satellites"`
+		MaxSpeed      int `yaml:"max_speed"`
+	} `yaml:"odometer"`
+	Connectivity struct {
+		PeriodUnit    int     `yaml:"period_unit"`
+		Grace         float64 `yaml:"grace"`
+		OfflineFactor float64 `yaml:"offline_factor"`
+	} `yaml:"connectivity"`
+	Storage struct {
+		Type string `yaml:"type"`
+		S3   struct {
+			Endpoint  string `yaml:"endpoint"`
+			AccessKey string `yaml:"access_key"`
+			SecretKey string `yaml:"secret_key"`
+			Bucket    string `yaml:"bucket"`
+			Region    string `yaml:"region"`
+			Prefix    string `yaml:"prefix"`
+			UseSSL    bool   `yaml:"use_ssl"`
+		} `yaml:"s3"`
+	} `yaml:"storage"`
 	ShutdownTimeout   string `yaml:"shutdown_timeout"`
 	ShippingFilesPath string `yaml:"shipping_files_path"`
 	RunCheckTelemetry bool   `yaml:"run_check_telemetry"`
@@ -82,11 +110,56 @@ func Get(path string) *Config {
 	return instance
 }
 
+// StorageParams параметры хранилища файлов перевозок указанного типа, пустой тип - из конфига
+func (c *Config) StorageParams(storageType string) storage.Params {
+	if storageType == "" {
+		storageType = c.Storage.Type
+	}
+
+	return storage.Params{
+		Type: storageType,
+		Path: c.ShippingFilesPath,
+		S3: storage.S3Params{
+			Endpoint:  c.Storage.S3.Endpoint,
+			AccessKey: c.Storage.S3.AccessKey,
+			SecretKey: c.Storage.S3.SecretKey,
+			Bucket:    c.Storage.S3.Bucket,
+			Region:    c.Storage.S3.Region,
+			Prefix:    c.Storage.S3.Prefix,
+			UseSSL:    c.Storage.S3.UseSSL,
+		},
+	}
+}
+
 func setDefaults() {
 	if instance.ShutdownTimeout == "" {
 		instance.ShutdownTimeout = "5s"
 	}
 
+	if instance.Connectivity.PeriodUnit == 0 {
+		instance.Connectivity.PeriodUnit = 60
+	}
+
+	if instance.Connectivity.Grace == 0 {
+		instance.Connectivity.Grace = 1.5
+	}
+
+	if instance.Connectivity.OfflineFactor == 0 {
+		instance.Connectivity.OfflineFactor = 3
+	}
+
+	if instance.GRPCCommands.Keepalive == 0 {
+		instance.GRPCCommands.Keepalive = 300
+	}
+
+	if instance.GRPCCommands.BulkConcurrency == 0 {
+		instance.GRPCCommands.BulkConcurrency = 10
+	}
+
+	if instance.Storage.Type == "" {
+		instance.Storage.Type = storage.TYPE_LOCAL
+	}
+
 	if instance.Router.Port == 0 {
 		instance.Router.Port = 8080
 	}
@@ -0,0 +1,147 @@
+package battery
+
+import (
+	"math"
+	"seal/internal/domain/shipping_status"
+	"time"
+)
+
+// Forecast прогноз разряда батареи по линейной аппроксимации уровня заряда после последней зарядки
+type Forecast struct {
+	Level             *float64   `json:"level"`   // последний уровень заряда, %
+	Voltage           *float64   `json:"voltage"` // последнее напряжение, если устройство его передаёт
+	LastTime          *time.Time `json:"last_time"`
+	Samples           int        `json:"samples"`
+	RatePerDay        *float64   `json:"rate_per_day"`         // расход, % в сутки, nil - разряд не обнаружен или мало данных
+	VoltageRatePerDay *float64   `json:"voltage_rate_per_day"` // падение напряжения в сутки на том же отрезке
+	RemainingDays     *float64   `json:"remaining_days"`
+	EmptyAt           *time.Time `json:"empty_at"`
+	Shipping          *int       `json:"shipping"`      // ближайшая незавершённая перевозка устройства
+	RequiredDays      *float64   `json:"required_days"` // сколько суток до конца перевозки по плану маршрута
+	Insufficient      bool       `json:"insufficient"`  // заряда не хватит до конца перевозки
+}
+
+type sample struct {
+	Device  int       `db:"device"`
+	DevTime time.Time `db:"dev_time"`
+	Level   *float64  `db:"level"`
+	Voltage *float64  `db:"voltage"`
+}
+
+type planned struct {
+	Device     int        `db:"device"`
+	Shipping   int        `db:"shipping"`
+	Status     int        `db:"status"`
+	TimeStart  *time.Time `db:"time_start"`
+	TravelTime int        `db:"travel_time"` // минут
+}
+
+const day = float64(24 * time.Hour)
+
+// forecast samples одного устройства по возрастанию времени
+func forecast(samples []sample, now time.Time) Forecast {
+	var res Forecast
+
+	var levels []sample
+	for _, s := range samples {
+		if s.Level != nil {
+			levels = append(levels, s)
+		}
+	}
+
+	if len(samples) > 0 {
+		last := samples[len(samples)-1]
+		res.Voltage = last.Voltage
+		res.LastTime = &last.DevTime
+	}
+
+	if len(levels) == 0 {
+		return res
+	}
+
+	last := levels[len(levels)-1]
+	res.Level = last.Level
+
+	// разряд с максимума последней зарядки, в том числе постепенной, растянутой на много измерений
+	if charges, _ := detectCharges(levels); len(charges) > 0 {
+		end := charges[len(charges)-1].End
+		for i := range levels {
+			if !levels[i].DevTime.Before(end) {
+				levels = levels[i:]
+				break
+			}
+		}
+	}
+	res.Samples = len(levels)
+
+	if len(levels) < MIN_SAMPLES || last.DevTime.Sub(levels[0].DevTime) < MIN_SPAN {
+		return res
+	}
+
+	slope := fitSlope(levels, levels[0].DevTime, func(s sample) *float64 { return s.Level })
+	if slope == nil || *slope >= 0 {
+		return res
+	}
+
+	rate := -*slope
+	res.RatePerDay = round(rate)
+	if voltageSlope := fitSlope(levels, levels[0].DevTime, func(s sample) *float64 { return s.Voltage }); voltageSlope != nil {
+		res.VoltageRatePerDay = round(-*voltageSlope)
+	}
+
+	level := *last.Level - rate*float64(now.Sub(last.DevTime))/day
+	remaining := math.Max(0, level) / rate
+	emptyAt := now.Add(time.Duration(remaining * day))
+
+	res.RemainingDays = round(remaining)
+	res.EmptyAt = &emptyAt
+
+	return res
+}
+
+// fitSlope наклон прямой наименьших квадратов, единиц в сутки
+func fitSlope(samples []sample, from time.Time, value func(s sample) *float64) *float64 {
+	var n, sx, sy, sxx, sxy float64
+
+	for _, s := range samples {
+		v := value(s)
+		if v == nil {
+			continue
+		}
+
+		x := float64(s.DevTime.Sub(from)) / day
+		n++
+		sx += x
+		sy += *v
+		sxx += x * x
+		sxy += x * *v
+	}
+
+	d := n*sxx - sx*sx
+	if n < MIN_SAMPLES || d == 0 {
+		return nil
+	}
+
+	slope := (n*sxy - sx*sy) / d
+
+	return &slope
+}
+
+// withPlanned отмечает, хватит ли заряда до конца ближайшей перевозки
+func (f *Forecast) withPlanned(p planned, now time.Time) {
+	travel := time.Duration(p.TravelTime) * time.Minute
+	required := float64(travel) / day
+
+	if p.TimeStart != nil && p.Status != shipping_status.NEW {
+		required = math.Max(0, float64(p.TimeStart.Add(travel).Sub(now))/day)
+	}
+
+	f.Shipping = &p.Shipping
+	f.RequiredDays = round(required)
+	f.Insufficient = f.RemainingDays != nil && *f.RemainingDays < required
+}
+
+func round(v float64) *float64 {
+	v = math.Round(v*100) / 100
+	return &v
+}
@@ -0,0 +1,156 @@
+package battery
+
+import (
+	"seal/internal/domain/shipping_status"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func value(v float64) *float64 { return &v }
+
+func TestForecast(t *testing.T) {
+	// постепенная зарядка шагами меньше CHARGE_JUMP с 40 до 58, затем разряд 1% в час
+	gradual := levels(50, 45, 40, 43, 46, 49, 52, 55, 58, 57, 56, 55, 54, 53, 52)
+
+	tests := []struct {
+		name      string
+		samples   []sample
+		now       time.Time
+		count     int
+		rate      *float64
+		remaining *float64
+	}{
+		{"нет измерений", nil, at(0), 0, nil, nil},
+		{"разряд 1% в час", levels(60, 59, 58, 57, 56, 55, 54), at(6), 7, value(24), value(2.25)},
+		{"прогноз от текущего времени", levels(60, 59, 58, 57, 56, 55, 54), at(30), 7, value(24), value(1.25)},
+		{"постепенная зарядка", gradual, at(14), 7, value(24), value(2.17)},
+		{"резкая зарядка", levels(30, 20, 90, 89, 88, 87, 86, 85, 84), at(8), 7, value(24), value(3.5)},
+		{"зарядка ещё идёт", levels(50, 40, 45, 50, 55, 60, 65, 70), at(7), 1, nil, nil},
+		{"мало времени после зарядки", levels(20, 90, 89, 88, 87), at(4), 4, nil, nil},
+		{"уровень не падает", levels(50, 50, 51, 51, 52, 52, 53), at(6), 7, nil, nil},
+		{"разряжено", levels(6, 5, 4, 3, 2, 1, 0), at(30), 7, value(24), value(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := forecast(tt.samples, tt.now)
+
+			assert.Equal(t, tt.count, f.Samples)
+			assert.Equal(t, tt.rate, f.RatePerDay)
+			assert.Equal(t, tt.remaining, f.RemainingDays)
+
+			if len(tt.samples) == 0 {
+				assert.Nil(t, f.Level)
+				assert.Nil(t, f.LastTime)
+				return
+			}
+
+			last := tt.samples[len(tt.samples)-1]
+			assert.Equal(t, last.Level, f.Level)
+			assert.Equal(t, last.DevTime, *f.LastTime)
+
+			if tt.remaining == nil {
+				assert.Nil(t, f.EmptyAt)
+			} else {
+				// remaining округлён до сотых суток, это до 7.2 минуты
+				require.NotNil(t, f.EmptyAt)
+				assert.WithinDuration(t, tt.now.Add(time.Duration(*tt.remaining*day)), *f.EmptyAt, 8*time.Minute)
+			}
+		})
+	}
+
+	t.Run("напряжение", func(t *testing.T) {
+		samples := levels(60, 59, 58, 57, 56, 55, 54)
+		for i := range samples {
+			samples[i].Voltage = value(4.2 - float64(i)*0.01)
+		}
+		// последнее измерение без уровня заряда
+		samples = append(samples, sample{DevTime: at(7), Voltage: value(4.1)})
+
+		f := forecast(samples, at(7))
+
+		assert.Equal(t, value(54), f.Level)
+		assert.Equal(t, value(4.1), f.Voltage)
+		assert.Equal(t, at(7), *f.LastTime)
+		assert.Equal(t, value(24), f.RatePerDay)
+		assert.Equal(t, value(0.24), f.VoltageRatePerDay)
+	})
+}
+
+func TestFitSlope(t *testing.T) {
+	level := func(s sample) *float64 { return s.Level }
+	voltage := func(s sample) *float64 { return s.Voltage }
+
+	sameTime := levels(3, 2, 1)
+	for i := range sameTime {
+		sameTime[i].DevTime = t0
+	}
+
+	partial := levels(10, 20, 30, 40, 50)
+	partial[1].Voltage, partial[3].Voltage, partial[4].Voltage = value(4), value(3), value(2.5)
+
+	tests := []struct {
+		name    string
+		samples []sample
+		value   func(s sample) *float64
+		want    *float64
+	}{
+		{"нет значений", levels(3, 2, 1), voltage, nil},
+		{"меньше MIN_SAMPLES", levels(3, 2), level, nil},
+		{"все в одно время", sameTime, level, nil},
+		{"падение 1 в час", levels(3, 2, 1), level, value(-24)},
+		{"рост", levels(1, 3, 5, 7), level, value(48)},
+		{"пропуски не учитываются", partial, voltage, value(-12)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fitSlope(tt.samples, t0, tt.value)
+
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NotNil(t, got)
+			assert.InDelta(t, *tt.want, *got, 1e-9)
+		})
+	}
+}
+
+func TestWithPlanned(t *testing.T) {
+	now := at(100)
+	started := func(hours int) *time.Time { v := now.Add(-time.Duration(hours) * time.Hour); return &v }
+	threeDays := 3 * 24 * 60
+
+	tests := []struct {
+		name         string
+		remaining    *float64
+		planned      planned
+		required     float64
+		insufficient bool
+	}{
+		{"новая перевозка - весь маршрут", value(2), planned{Status: shipping_status.NEW, TravelTime: threeDays}, 3, true},
+		{"у новой время начала не учитывается", value(2), planned{Status: shipping_status.NEW, TimeStart: started(48), TravelTime: threeDays}, 3, true},
+		{"активная - остаток маршрута", value(2), planned{Status: shipping_status.ACTIVE, TimeStart: started(48), TravelTime: threeDays}, 1, false},
+		{"приостановленная - остаток маршрута", value(0.5), planned{Status: shipping_status.SUSPENDED, TimeStart: started(36), TravelTime: threeDays}, 1.5, true},
+		{"плановое время истекло", value(0), planned{Status: shipping_status.ACTIVE, TimeStart: started(100), TravelTime: threeDays}, 0, false},
+		{"нет прогноза разряда", nil, planned{Status: shipping_status.NEW, TravelTime: threeDays}, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.planned.Shipping = 7
+			f := Forecast{RemainingDays: tt.remaining}
+
+			f.withPlanned(tt.planned, now)
+
+			assert.Equal(t, 7, *f.Shipping)
+			assert.Equal(t, value(tt.required), f.RequiredDays)
+			assert.Equal(t, tt.insufficient, f.Insufficient)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package battery
+
+import (
+	"fmt"
+	"seal/pkg/app_error"
+	"time"
+)
+
+// CHARGES_WINDOW период истории зарядок по умолчанию
+const CHARGES_WINDOW = 90 * 24 * time.Hour
+
+// CHARGES_MAX_WINDOW наибольший период запроса истории зарядок
+const CHARGES_MAX_WINDOW = 366 * 24 * time.Hour
+
+// CHECK_MODEM_LIMIT модемов с новыми измерениями, обрабатываемых за одну проверку
+const CHECK_MODEM_LIMIT = 100
+
+// CHECK_SAMPLES измерений одного модема, обрабатываемых за одну проверку
+const CHECK_SAMPLES = 5000
+
+// CHARGE_NOISE падение уровня (%) от максимума, которое ещё не прерывает зарядку
+const CHARGE_NOISE = 2
+
+type ChargesQueryParams struct {
+	Id   int       `validate:"required,min=1"`
+	From time.Time `form:"from" validate:"ltfield=To"`
+	To   time.Time `form:"to"`
+}
+
+// Charges зарядки устройства за период и перевозки, начатые без зарядки после предыдущей
+type Charges struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Charges   []Charge  `json:"charges"`
+	Uncharged []int     `json:"uncharged"` // перевозки, перед которыми устройство не заряжали
+}
+
+// Charge сеанс зарядки от последнего измерения перед ростом уровня до достижения максимума
+type Charge struct {
+	Start      time.Time `json:"start" db:"time_start"`
+	End        time.Time `json:"end" db:"time_end"`
+	StartLevel float64   `json:"start_level" db:"start_level"`
+	EndLevel   float64   `json:"end_level" db:"end_level"`
+	Duration   int       `json:"duration"` // минут
+}
+
+// modemForCheck модем с измерениями после последней проверки зарядок
+type modemForCheck struct {
+	Id          int        `db:"id"`
+	LastDevTime time.Time  `db:"last_dev_time"`
+	ResumeFrom  *time.Time `db:"resume_from"`
+}
+
+type trip struct {
+	Id        int        `db:"id"`
+	TimeStart time.Time  `db:"time_start"`
+	TimeEnd   *time.Time `db:"time_end"`
+}
+
+// ModemCharges сохранённые зарядки модема за период, по умолчанию за CHARGES_WINDOW
+func (s *usecase) ModemCharges(params ChargesQueryParams) (Charges, error) {
+	now := time.Now()
+	if params.To.IsZero() || params.To.After(now) {
+		params.To = now
+	}
+
+	if params.From.IsZero() {
+		params.From = params.To.Add(-CHARGES_WINDOW)
+	}
+
+	if errs := s.validator.Struct(params); errs != nil {
+		return Charges{}, app_error.ValidationError(errs)
+	}
+
+	if params.To.Sub(params.From) > CHARGES_MAX_WINDOW {
+		return Charges{}, app_error.ValidationError(map[string]string{"from": "Период не больше 366 суток"})
+	}
+
+	if err := s.repo.Exists(DEVICE_MODEM, params.Id); err != nil {
+		return Charges{}, err
+	}
+
+	charges, err := s.repo.Charges(params.Id, params.From, params.To)
+	if err != nil {
+		return Charges{}, err
+	}
+
+	if charges == nil {
+		charges = []Charge{}
+	}
+
+	trips, err := s.repo.Trips(DEVICE_MODEM, params.Id, params.From, params.To)
+	if err != nil {
+		return Charges{}, err
+	}
+
+	return Charges{
+		From:      params.From,
+		To:        params.To,
+		Charges:   charges,
+		Uncharged: uncharged(trips, charges),
+	}, nil
+}
+
+// Check ищет зарядки модемов по новым измерениям и сохраняет их
+func (s *usecase) Check() error {
+	list, err := s.repo.ModemsForCheck(CHECK_MODEM_LIMIT)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range list {
+		if err := s.checkModem(m, time.Now()); err != nil {
+			s.logger.Error(fmt.Sprintf("Ошибка поиска зарядок модема %d: %v", m.Id, err))
+		}
+	}
+
+	return nil
+}
+
+// checkModem продолжает поиск с начала последней незакрытой зарядки:
+// она находится заново и обновляется, пока уровень продолжает расти
+func (s *usecase) checkModem(m modemForCheck, now time.Time) error {
+	from := now.Add(-CHARGES_WINDOW)
+	if m.ResumeFrom != nil {
+		from = *m.ResumeFrom
+	}
+
+	samples, err := s.repo.History(DEVICE_MODEM, m.Id, from, now, CHECK_SAMPLES)
+	if err != nil {
+		return err
+	}
+
+	if len(samples) == 0 {
+		return s.repo.SaveCharges(m.Id, nil, m.LastDevTime, from)
+	}
+
+	charges, resume := detectCharges(samples)
+
+	last := samples[len(samples)-1].DevTime
+	if resume.IsZero() || len(samples) == CHECK_SAMPLES && !resume.After(from) {
+		// зарядка длиннее пачки измерений: поиск продолжается с последнего, иначе он не сдвинется
+		resume = last
+	}
+
+	return s.repo.SaveCharges(m.Id, charges, last, resume)
+}
+
+// detectCharges зарядки по измерениям одного устройства по возрастанию времени.
+// Зарядкой считается рост от минимума не меньше CHARGE_JUMP, она заканчивается,
+// когда уровень опускается от достигнутого максимума больше чем на CHARGE_NOISE.
+// resume - время измерения, с которого начинается последняя незакрытая зарядка или её возможное начало
+func detectCharges(samples []sample) (res []Charge, resume time.Time) {
+	res = []Charge{}
+
+	var levels []sample
+	for _, s := range samples {
+		if s.Level != nil {
+			levels = append(levels, s)
+		}
+	}
+
+	if len(levels) == 0 {
+		return res, resume
+	}
+
+	level := func(i int) float64 { return *levels[i].Level }
+
+	start, peak := 0, 0
+	closeCharge := func() {
+		if level(peak)-level(start) < CHARGE_JUMP {
+			return
+		}
+
+		res = append(res, Charge{
+			Start:      levels[start].DevTime,
+			End:        levels[peak].DevTime,
+			StartLevel: level(start),
+			EndLevel:   level(peak),
+			Duration:   int(levels[peak].DevTime.Sub(levels[start].DevTime).Minutes()),
+		})
+	}
+
+	for i := 1; i < len(levels); i++ {
+		switch l := level(i); {
+		case l > level(peak):
+			peak = i
+		case level(peak)-level(start) < CHARGE_JUMP:
+			// зарядки ещё нет, началом считается последний минимум
+			if l <= level(start) {
+				start, peak = i, i
+			}
+		case level(peak)-l > CHARGE_NOISE:
+			closeCharge()
+			start, peak = i, i
+		}
+	}
+
+	closeCharge()
+
+	return res, levels[start].DevTime
+}
+
+// uncharged перевозки, между концом предыдущей перевозки и началом которых не было зарядки
+func uncharged(trips []trip, charges []Charge) []int {
+	res := []int{}
+
+	for i := 1; i < len(trips); i++ {
+		prevEnd := trips[i-1].TimeEnd
+		if prevEnd == nil || prevEnd.After(trips[i].TimeStart) {
+			continue
+		}
+
+		charged := false
+		for _, charge := range charges {
+			if charge.End.After(*prevEnd) && !charge.Start.After(trips[i].TimeStart) {
+				charged = true
+				break
+			}
+		}
+
+		if !charged {
+			res = append(res, trips[i].Id)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,160 @@
+package battery
+
+import (
+	"seal/pkg/app_error"
+	"seal/pkg/validator"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var t0 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func at(hours int) time.Time { return t0.Add(time.Duration(hours) * time.Hour) }
+
+// levels измерения по часу от t0
+func levels(values ...float64) []sample {
+	res := make([]sample, len(values))
+	for i := range values {
+		res[i] = sample{DevTime: at(i), Level: &values[i]}
+	}
+
+	return res
+}
+
+type savedCharges struct {
+	charges    []Charge
+	checkedAt  time.Time
+	resumeFrom time.Time
+}
+
+type fakeRepo struct {
+	Repo
+	samples []sample
+	from    time.Time
+	saved   []savedCharges
+}
+
+func (r *fakeRepo) History(device string, id int, from, to time.Time, limit int) ([]sample, error) {
+	r.from = from
+
+	var res []sample
+	for _, s := range r.samples {
+		if !s.DevTime.Before(from) && !s.DevTime.After(to) && len(res) < limit {
+			res = append(res, s)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *fakeRepo) SaveCharges(modemId int, charges []Charge, checkedAt, resumeFrom time.Time) error {
+	r.saved = append(r.saved, savedCharges{charges, checkedAt, resumeFrom})
+	return nil
+}
+
+func (r *fakeRepo) Exists(device string, id int) error { return nil }
+
+func TestDetectCharges(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []sample
+		starts  []int
+		ends    []int
+		resume  int
+	}{
+		{"разряд без зарядок", levels(90, 80, 70, 60), nil, nil, 3},
+		{"зарядка с последнего минимума", levels(50, 40, 60, 90, 85, 70), []int{1}, []int{3}, 5},
+		{"шум не прерывает зарядку", levels(40, 60, 59, 80, 50), []int{0}, []int{3}, 4},
+		{"незакрытая зарядка в конце", levels(60, 30, 50, 70), []int{1}, []int{3}, 1},
+		{"две зарядки", levels(30, 60, 40, 90, 80), []int{0, 2}, []int{1, 3}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charges, resume := detectCharges(tt.samples)
+
+			var starts, ends []int
+			for _, c := range charges {
+				starts = append(starts, int(c.Start.Sub(t0).Hours()))
+				ends = append(ends, int(c.End.Sub(t0).Hours()))
+			}
+
+			assert.Equal(t, tt.starts, starts)
+			assert.Equal(t, tt.ends, ends)
+			assert.Equal(t, at(tt.resume), resume)
+		})
+	}
+}
+
+func TestUncharged(t *testing.T) {
+	end := func(hours int) *time.Time { v := at(hours); return &v }
+	trips := []trip{{1, at(0), end(2)}, {2, at(5), end(8)}, {3, at(10), nil}}
+	charges := []Charge{{Start: at(3), End: at(4)}}
+
+	assert.Equal(t, []int{3}, uncharged(trips, charges))
+}
+
+func TestCheckModem(t *testing.T) {
+	now := at(100)
+	repo := &fakeRepo{samples: levels(60, 30, 50, 70)}
+	s := &usecase{repo: repo}
+
+	// первая проверка: зарядка ещё идёт, поиск продолжится с её начала
+	assert.NoError(t, s.checkModem(modemForCheck{Id: 1}, now))
+	assert.Equal(t, now.Add(-CHARGES_WINDOW), repo.from)
+	assert.Len(t, repo.saved[0].charges, 1)
+	assert.Equal(t, at(3), repo.saved[0].charges[0].End)
+	assert.Equal(t, at(3), repo.saved[0].checkedAt)
+	assert.Equal(t, at(1), repo.saved[0].resumeFrom)
+
+	// новые измерения: та же зарядка продлевается и закрывается
+	repo.samples = append(repo.samples, levels(60, 30, 50, 70, 95, 80)[4:]...)
+	resume := repo.saved[0].resumeFrom
+	assert.NoError(t, s.checkModem(modemForCheck{Id: 1, ResumeFrom: &resume}, now))
+	assert.Equal(t, at(1), repo.from)
+	assert.Len(t, repo.saved[1].charges, 1)
+	assert.Equal(t, at(1), repo.saved[1].charges[0].Start)
+	assert.Equal(t, at(4), repo.saved[1].charges[0].End)
+	assert.Equal(t, at(5), repo.saved[1].resumeFrom)
+}
+
+func TestCheckModemLongCharge(t *testing.T) {
+	values := make([]float64, CHECK_SAMPLES+10)
+	// непрерывный рост на всю пачку: без сдвига поиск бы не продвинулся
+	for i := range values {
+		values[i] = 10 + float64(i)*0.01
+	}
+
+	repo := &fakeRepo{samples: levels(values...)}
+	s := &usecase{repo: repo}
+	from := at(0)
+
+	assert.NoError(t, s.checkModem(modemForCheck{Id: 1, ResumeFrom: &from}, at(len(values))))
+	assert.Equal(t, at(CHECK_SAMPLES-1), repo.saved[0].resumeFrom)
+}
+
+func TestModemChargesValidation(t *testing.T) {
+	s := &usecase{repo: &fakeRepo{}, validator: validator.NewValidator()}
+	validation := app_error.ErrValidation.GetHttpCode()
+
+	tests := []struct {
+		name   string
+		params ChargesQueryParams
+	}{
+		{"нет модема", ChargesQueryParams{From: at(0), To: at(1)}},
+		{"from позже to", ChargesQueryParams{Id: 1, From: at(2), To: at(1)}},
+		{"период больше допустимого", ChargesQueryParams{Id: 1, From: at(0), To: at(0).Add(CHARGES_MAX_WINDOW + time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ModemCharges(tt.params)
+
+			var appErr *app_error.AppError
+			assert.ErrorAs(t, err, &appErr)
+			assert.Equal(t, validation, appErr.GetHttpCode())
+		})
+	}
+}
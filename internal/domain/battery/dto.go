@@ -0,0 +1,37 @@
+package battery
+
+import "time"
+
+const DEVICE_MODEM = "modem"
+const DEVICE_SEAL = "seal"
+
+// WINDOW глубина истории для подбора кривой разряда
+const WINDOW = 30 * 24 * time.Hour
+
+// MAX_SAMPLES последних измерений одного устройства, не больше
+const MAX_SAMPLES = 5000
+
+// MIN_SAMPLES и MIN_SPAN минимум измерений и их разброс во времени после последней зарядки
+const MIN_SAMPLES = 3
+const MIN_SPAN = 6 * time.Hour
+
+// CHARGE_JUMP рост уровня заряда от минимума (%), который считается зарядкой
+const CHARGE_JUMP = 5
+
+type Repo interface {
+	Samples(device string, ids []int, from time.Time) ([]sample, error)
+	Planned(device string, ids []int) ([]planned, error)
+	Exists(device string, id int) error
+	History(device string, id int, from, to time.Time, limit int) ([]sample, error)
+	Trips(device string, id int, from, to time.Time) ([]trip, error)
+	ModemsForCheck(limit int) ([]modemForCheck, error)
+	Charges(modemId int, from, to time.Time) ([]Charge, error)
+	SaveCharges(modemId int, charges []Charge, checkedAt, resumeFrom time.Time) error
+}
+
+type Usecase interface {
+	Modems(ids []int) (map[int]Forecast, error)
+	Seals(ids []int) (map[int]Forecast, error)
+	ModemCharges(params ChargesQueryParams) (Charges, error)
+	Check() error
+}
@@ -0,0 +1,254 @@
+package battery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping_status"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"seal/pkg/app_error"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+// Samples последние MAX_SAMPLES измерений заряда каждого устройства после from, по возрастанию времени
+func (r *repo) Samples(device string, ids []int, from time.Time) ([]sample, error) {
+	table, voltage := "modems_data", "nullif(d.battery_voltage, 'NaN')::float8"
+	if device == DEVICE_SEAL {
+		table, voltage = "seals_data", "null::float8"
+	}
+
+	q := fmt.Sprintf(`select t.device, t.dev_time, t.level, t.voltage
+		from unnest($1::int[]) i(id)
+		cross join lateral (
+			select d.%[1]s as device, d.dev_time, d.battery_level::float8 as level, %[3]s as voltage
+			from %[2]s d
+			where d.%[1]s = i.id and d.dev_time > $2 and d.dev_time <= now()
+			order by d.dev_time desc
+			limit $3
+		) t
+		order by t.device, t.dev_time
+	`, device, table, voltage)
+
+	qp := []any{ids, from, MAX_SAMPLES}
+
+	rows, err := r.db.Query(r.ctx, q, qp...)
+	if err != nil {
+		r.logger.Error(query.NewLogSql(q, qp...).SetError(err).GetMsg())
+		return nil, err
+	}
+
+	data, err := pgx.CollectRows(rows, pgx.RowToStructByName[sample])
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Planned ближайшая незавершённая перевозка каждого устройства: идущая, иначе самая ранняя новая
+func (r *repo) Planned(device string, ids []int) ([]planned, error) {
+	join := "s.modem = i.id"
+	if device == DEVICE_SEAL {
+		join = "exists (select 1 from shipping_seals ss where ss.shipping = s.id and ss.seal = i.id)"
+	}
+
+	q := fmt.Sprintf(`select distinct on (i.id) i.id as device, s.id as shipping, s.status, s.time_start, r.travel_time
+		from unnest($1::int[]) i(id)
+		inner join shipping s on %s
+		inner join routes r on r.id = s.route
+		where s.status in (%[2]d, %[3]d, %[4]d)
+		order by i.id, s.status = %[2]d, s.created_at
+	`, join, shipping_status.NEW, shipping_status.ACTIVE, shipping_status.SUSPENDED)
+
+	qp := []any{ids}
+
+	rows, err := r.db.Query(r.ctx, q, qp...)
+	if err != nil {
+		r.logger.Error(query.NewLogSql(q, qp...).SetError(err).GetMsg())
+		return nil, err
+	}
+
+	data, err := pgx.CollectRows(rows, pgx.RowToStructByName[planned])
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) Exists(device string, id int) error {
+	table := "modems"
+	if device == DEVICE_SEAL {
+		table = "seals"
+	}
+
+	q := query.New[struct {
+		Id int `db:"id"`
+	}](r.ctx, r.db).
+		Select("t.id", "").
+		From(table, "t").
+		Where(query.EQUEL, "t.id", id)
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return err
+}
+
+// History измерения заряда устройства за период по возрастанию времени
+func (r *repo) History(device string, id int, from, to time.Time, limit int) ([]sample, error) {
+	table, voltage := "modems_data", "nullif(d.battery_voltage, 'NaN')::float8"
+	if device == DEVICE_SEAL {
+		table, voltage = "seals_data", "null::float8"
+	}
+
+	q := query.New[sample](r.ctx, r.db).
+		Select("d."+device, "device").
+		AddSelect("d.dev_time", "").
+		AddSelect("d.battery_level::float8", "level").
+		AddSelect(voltage, "voltage").
+		From(table, "d").
+		Where(query.EQUEL, "d."+device, id).
+		AndWhere(query.GREAT_OR_EQ, "d.dev_time", from).
+		AndWhere(query.LITTLE_OR_EQ, "d.dev_time", to).
+		OrderBy("d.dev_time").
+		Limit(limit)
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Trips начатые перевозки устройства за период по возрастанию времени начала
+func (r *repo) Trips(device string, id int, from, to time.Time) ([]trip, error) {
+	q := query.New[trip](r.ctx, r.db).
+		Select("s.id", "").
+		AddSelect("s.time_start", "").
+		AddSelect("s.time_end", "").
+		From("shipping", "s").
+		Where(query.GREAT_OR_EQ, "s.time_start", from).
+		AndWhere(query.LITTLE_OR_EQ, "s.time_start", to).
+		OrderBy("s.time_start")
+
+	if device == DEVICE_SEAL {
+		q.AndWhereExists(fmt.Sprintf("select 1 from shipping_seals ss where ss.shipping = s.id and ss.seal = %d", id))
+	} else {
+		q.AndWhere(query.EQUEL, "s.modem", id)
+	}
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// ModemsForCheck модемы, у которых есть измерения после последней проверки зарядок
+func (r *repo) ModemsForCheck(limit int) ([]modemForCheck, error) {
+	q := `select m.id, m.last_dev_time, c.resume_from
+		from modems m
+		left join modem_charge_checks c on c.modem = m.id
+		where m.last_dev_time is not null and m.last_dev_time <= now()
+			and (c.checked_at is null or m.last_dev_time > c.checked_at)
+		order by c.checked_at nulls first, m.id
+		limit $1
+	`
+
+	rows, _ := r.db.Query(r.ctx, q, limit)
+
+	data, err := pgx.CollectRows(rows, pgx.RowToStructByName[modemForCheck])
+	r.logger.DebugOrError(err, query.NewLogSql(q, limit).SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Charges сохранённые зарядки модема, пересекающиеся с периодом, по возрастанию времени начала
+func (r *repo) Charges(modemId int, from, to time.Time) ([]Charge, error) {
+	q := query.New[Charge](r.ctx, r.db).
+		Select("c.time_start", "").
+		AddSelect("c.time_end", "").
+		AddSelect("c.start_level::float8", "start_level").
+		AddSelect("c.end_level::float8", "end_level").
+		AddSelect("c.duration", "").
+		From("modem_charges", "c").
+		Where(query.EQUEL, "c.modem", modemId).
+		AndWhere(query.GREAT_OR_EQ, "c.time_end", from).
+		AndWhere(query.LITTLE_OR_EQ, "c.time_start", to).
+		OrderBy("c.time_start")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// SaveCharges сохраняет найденные зарядки и место, с которого продолжится поиск.
+// Зарядка с тем же началом обновляется: незакрытая зарядка находится заново при каждой проверке
+func (r *repo) SaveCharges(modemId int, charges []Charge, checkedAt, resumeFrom time.Time) error {
+	var err error
+	var tx pgx.Tx
+
+	if tx, err = r.db.Begin(r.ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(r.ctx)
+		}
+	}()
+
+	q := `INSERT INTO modem_charges (modem, time_start, time_end, start_level, end_level, duration)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (modem, time_start) DO UPDATE SET
+			time_end = EXCLUDED.time_end,
+			start_level = EXCLUDED.start_level,
+			end_level = EXCLUDED.end_level,
+			duration = EXCLUDED.duration
+	`
+
+	for _, charge := range charges {
+		qp := []any{modemId, charge.Start, charge.End, charge.StartLevel, charge.EndLevel, charge.Duration}
+
+		_, err = tx.Exec(r.ctx, q, qp...)
+		r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	qc := `INSERT INTO modem_charge_checks (modem, checked_at, resume_from)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (modem) DO UPDATE SET
+			checked_at = EXCLUDED.checked_at,
+			resume_from = EXCLUDED.resume_from
+	`
+
+	qcp := []any{modemId, checkedAt, resumeFrom}
+
+	_, err = tx.Exec(r.ctx, qc, qcp...)
+	r.logger.DebugOrError(err, query.NewLogSql(qc, qcp...).SetError(err).GetMsg())
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(r.ctx)
+
+	return err
+}
@@ -0,0 +1,61 @@
+package battery
+
+import (
+	app_interface "seal/internal/app/interface"
+	"time"
+)
+
+type usecase struct {
+	repo      Repo
+	logger    app_interface.Logger
+	validator app_interface.Validator
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator) Usecase {
+	return &usecase{repo, logger, validator}
+}
+
+func (s *usecase) Modems(ids []int) (map[int]Forecast, error) {
+	return s.forecasts(DEVICE_MODEM, ids)
+}
+
+func (s *usecase) Seals(ids []int) (map[int]Forecast, error) {
+	return s.forecasts(DEVICE_SEAL, ids)
+}
+
+// forecasts прогнозы по устройствам одного вида, для устройств без данных - пустой прогноз
+func (s *usecase) forecasts(device string, ids []int) (map[int]Forecast, error) {
+	res := make(map[int]Forecast, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	now := time.Now()
+
+	samples, err := s.repo.Samples(device, ids, now.Add(-WINDOW))
+	if err != nil {
+		return nil, err
+	}
+
+	byDevice := make(map[int][]sample)
+	for _, sample := range samples {
+		byDevice[sample.Device] = append(byDevice[sample.Device], sample)
+	}
+
+	for _, id := range ids {
+		res[id] = forecast(byDevice[id], now)
+	}
+
+	plans, err := s.repo.Planned(device, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range plans {
+		f := res[p.Device]
+		f.withPlanned(p, now)
+		res[p.Device] = f
+	}
+
+	return res, nil
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// entry команда каталога со скомпилированной схемой, schemaErr - схема в БД некорректна
+type entry struct {
+	Template
+	schema    *jsonschema.Schema
+	schemaErr error
+}
+
+// catalog каталог команд в памяти, перечитывается из БД раз в CATALOG_TTL
+type catalog struct {
+	mu       sync.Mutex
+	loadedAt time.Time
+	entries  map[string]entry
+}
+
+// load актуальный каталог. Если БД недоступна, а каталог уже загружался, отдаётся прежний
+func (s *usecase) load() (map[string]entry, error) {
+	s.catalog.mu.Lock()
+	defer s.catalog.mu.Unlock()
+
+	if s.catalog.entries != nil && time.Since(s.catalog.loadedAt) < CATALOG_TTL {
+		return s.catalog.entries, nil
+	}
+
+	templates, err := s.repo.Catalog("")
+	if err != nil {
+		if s.catalog.entries != nil {
+			s.logger.Error(fmt.Sprintf("Command catalog: %v", err))
+			return s.catalog.entries, nil
+		}
+
+		return nil, err
+	}
+
+	entries := make(map[string]entry, len(templates))
+	for _, t := range templates {
+		schema, err := t.compile()
+		if err != nil {
+			s.logger.Error(err.Error())
+		}
+
+		entries[t.Name] = entry{t, schema, err}
+	}
+
+	s.catalog.entries = entries
+	s.catalog.loadedAt = time.Now()
+
+	return entries, nil
+}
+
+// list команды каталога устройства, пустое устройство - все, сортировка как в БД
+func list(entries map[string]entry, device string) []Template {
+	templates := []Template{}
+	for _, e := range entries {
+		if device == "" || e.Device == device {
+			templates = append(templates, e.Template)
+		}
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Device != templates[j].Device {
+			return templates[i].Device < templates[j].Device
+		}
+
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates
+}
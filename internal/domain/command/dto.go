@@ -1,6 +1,7 @@
 package command
 
 import (
+	"seal/internal/repository/pg/query"
 	"time"
 )
 
@@ -18,4 +19,94 @@ type Command struct {
 	Response     *string    `json:"response"`
 	RawRequest   *string    `json:"raw_request"`
 	RawResponse  *string    `json:"raw_response"`
+	Status       string     `json:"status"`
+	Modem        int        `json:"modem,omitempty"` // в общем списке команд всех модемов
+}
+
+const STATUS_PENDING = "pending"     // ждёт доставки
+const STATUS_DELIVERED = "delivered" // модем ответил
+const STATUS_FAILED = "failed"       // сервис прекратил попытки доставки
+const STATUS_ABORTED = "aborted"     // отменена до первой попытки
+
+// HISTORY_PAGE команд за один запрос к сервису команд
+const HISTORY_PAGE = 100
+
+// MAX_HISTORY команд одного модема, которые просматриваются для фильтров по периоду и статусу
+const MAX_HISTORY = 5000
+
+// FLEET_MODEMS модемов на странице общего списка, команды каждого запрашиваются отдельно
+const FLEET_MODEMS = 50
+
+// FLEET_WINDOW период общего списка по умолчанию
+const FLEET_WINDOW = 24 * time.Hour
+
+type HistoryQueryParams struct {
+	Limit   int       `form:"limit,omitempty" validate:"max=100"`
+	Offset  int       `form:"offset,omitempty" validate:"min=0,max=32767"`
+	NewOnly bool      `form:"new_only"`
+	From    time.Time `form:"from"`
+	To      time.Time `form:"to"`
+	Status  []string  `form:"status" validate:"dive,oneof=pending delivered failed aborted"`
+}
+
+// filtered нужен ли просмотр истории здесь: сервис команд не фильтрует по периоду и статусу
+func (p HistoryQueryParams) filtered() bool {
+	return !p.From.IsZero() || !p.To.IsZero() || len(p.Status) > 0
+}
+
+// FleetQueryParams общий список по странице модемов: Modem - конкретные модемы,
+// иначе модемы по поиску, ModemLimit/ModemOffset - страница модемов по id
+type FleetQueryParams struct {
+	HistoryQueryParams
+	Find        string `form:"find,omitempty" validate:"max=1024"`
+	FindType    uint8  `form:"find_type,omitempty" validate:"max=4"`
+	Modem       []int  `form:"modem" validate:"max=50,dive,min=1"`
+	ModemLimit  int    `form:"modem_limit,omitempty" validate:"min=0,max=50"`
+	ModemOffset int    `form:"modem_offset,omitempty" validate:"min=0,max=32767"`
+}
+
+// FleetList команды страницы модемов. Modems - модемы страницы, Errors - модемы,
+// команды которых не удалось получить, остальные команды возвращаются
+type FleetList struct {
+	query.List[Command]
+	Modems []int        `json:"modems"`
+	Errors []FleetError `json:"errors"`
+}
+
+type FleetError struct {
+	Modem int    `json:"modem"`
+	Error string `json:"error"`
+}
+
+// Device модем, команды которого попадают в общий список
+type Device struct {
+	Id   int    `db:"id"`
+	Imei string `db:"imei"`
+}
+
+// CATALOG_TTL сколько каталог команд хранится в памяти, изменения в command_templates видны не позже
+const CATALOG_TTL = time.Minute
+
+const DEVICE_MODEM = "modem"
+const DEVICE_SEAL = "seal"
+
+type CatalogQueryParams struct {
+	Device string `form:"device" validate:"omitempty,oneof=modem seal"`
+}
+
+type Repo interface {
+	Catalog(device string) ([]Template, error)
+	Devices(params FleetQueryParams) ([]Device, error)
+}
+
+type Usecase interface {
+	Catalog(params CatalogQueryParams) ([]Template, error)
+	Check(name string, params any, role int) (*Template, error)
+	History(imei string, params HistoryQueryParams) (query.List[Command], error)
+	Fleet(params FleetQueryParams) (FleetList, error)
+}
+
+// lister страница команд модема из сервиса команд, см. commands.grpcClient
+type lister interface {
+	List(imei string, offset int, limit int, newOnly bool) ([]Command, error)
 }
@@ -0,0 +1,203 @@
+package command
+
+import (
+	"seal/internal/repository/pg/query"
+	"seal/pkg/app_error"
+	"sort"
+	"sync"
+	"time"
+)
+
+// status по датам ответа и отмены: отменённая после попыток доставки считается неудачной
+func (c Command) status() string {
+	switch {
+	case c.ResponseDate != nil:
+		return STATUS_DELIVERED
+	case c.AbortDate != nil && c.TryNumber > 0:
+		return STATUS_FAILED
+	case c.AbortDate != nil:
+		return STATUS_ABORTED
+	}
+
+	return STATUS_PENDING
+}
+
+// History команды модема. Без фильтров по периоду и статусу страница запрашивается у сервиса команд
+// как есть, иначе история читается страницами до MAX_HISTORY или до начала периода, а фильтры и подсчёт - здесь
+func (s *usecase) History(imei string, params HistoryQueryParams) (query.List[Command], error) {
+	if errs := s.validator.Struct(params); errs != nil {
+		return query.List[Command]{}, app_error.ValidationError(errs)
+	}
+
+	if !params.filtered() {
+		return s.direct(imei, params)
+	}
+
+	commands, err := s.fetch(imei, params.NewOnly, params.From, MAX_HISTORY)
+	if err != nil {
+		return query.List[Command]{}, err
+	}
+
+	return page(commands, params), nil
+}
+
+// direct страница команд из сервиса команд. Общее число команд сервис не отдаёт,
+// поэтому records_* - команды до конца страницы плюс одна, если есть следующая
+func (s *usecase) direct(imei string, params HistoryQueryParams) (query.List[Command], error) {
+	limit := params.Limit
+	if limit == 0 {
+		limit = 10
+	}
+
+	commands, err := s.usecase.Commands.List(imei, params.Offset, limit+1, params.NewOnly)
+	if err != nil {
+		return query.List[Command]{}, err
+	}
+
+	total := int64(params.Offset + len(commands))
+	commands = commands[:min(len(commands), limit)]
+
+	for i := range commands {
+		commands[i].Status = commands[i].status()
+	}
+
+	return query.List[Command]{RecordsTotal: total, RecordsFiltered: total, Data: commands}, nil
+}
+
+// Fleet команды страницы модемов за период, по умолчанию за последние FLEET_WINDOW.
+// Ошибка одного модема не прерывает список, а попадает в Errors
+func (s *usecase) Fleet(params FleetQueryParams) (FleetList, error) {
+	if errs := s.validator.Struct(params); errs != nil {
+		return FleetList{}, app_error.ValidationError(errs)
+	}
+
+	if params.From.IsZero() {
+		params.From = time.Now().Add(-FLEET_WINDOW)
+	}
+
+	if params.ModemLimit == 0 {
+		params.ModemLimit = FLEET_MODEMS
+	}
+
+	devices, err := s.repo.Devices(params)
+	if err != nil {
+		return FleetList{}, err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		commands []Command
+	)
+
+	res := FleetList{Modems: make([]int, 0, len(devices)), Errors: []FleetError{}}
+	sem := make(chan struct{}, s.concurrency)
+
+	for _, device := range devices {
+		res.Modems = append(res.Modems, device.Id)
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(device Device) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			list, err := s.fetch(device.Imei, params.NewOnly, params.From, MAX_HISTORY)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				res.Errors = append(res.Errors, FleetError{Modem: device.Id, Error: err.Error()})
+				return
+			}
+
+			for _, c := range list {
+				c.Modem = device.Id
+				commands = append(commands, c)
+			}
+		}(device)
+	}
+
+	wg.Wait()
+
+	sort.Slice(res.Errors, func(i, j int) bool {
+		return res.Errors[i].Modem < res.Errors[j].Modem
+	})
+
+	res.List = page(commands, params.HistoryQueryParams)
+
+	return res, nil
+}
+
+// fetch команды модема страницами по HISTORY_PAGE, не больше limit. Сервис отдаёт команды
+// от новых к старым, поэтому чтение заканчивается на первой странице, дошедшей до from
+func (s *usecase) fetch(imei string, newOnly bool, from time.Time, limit int) ([]Command, error) {
+	var res []Command
+
+	for offset := 0; offset < limit; offset += HISTORY_PAGE {
+		list, err := s.usecase.Commands.List(imei, offset, min(HISTORY_PAGE, limit-offset), newOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, list...)
+
+		if len(list) < HISTORY_PAGE || !from.IsZero() && list[len(list)-1].Dateon.Before(from) {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// page фильтр по периоду и статусу, сортировка от новых к старым и страница limit/offset
+func page(commands []Command, params HistoryQueryParams) query.List[Command] {
+	limit := params.Limit
+	if limit == 0 {
+		limit = 10
+	}
+
+	statuses := make(map[string]bool, len(params.Status))
+	for _, status := range params.Status {
+		statuses[status] = true
+	}
+
+	filtered := make([]Command, 0, len(commands))
+	for _, c := range commands {
+		c.Status = c.status()
+
+		if !params.From.IsZero() && c.Dateon.Before(params.From) {
+			continue
+		}
+
+		if !params.To.IsZero() && c.Dateon.After(params.To) {
+			continue
+		}
+
+		if len(statuses) > 0 && !statuses[c.Status] {
+			continue
+		}
+
+		filtered = append(filtered, c)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Dateon.After(filtered[j].Dateon)
+	})
+
+	list := query.List[Command]{
+		RecordsTotal:    int64(len(commands)),
+		RecordsFiltered: int64(len(filtered)),
+		Data:            []Command{},
+	}
+
+	if params.Offset < len(filtered) {
+		list.Data = filtered[params.Offset:min(len(filtered), params.Offset+limit)]
+	}
+
+	return list
+}
@@ -0,0 +1,141 @@
+package command
+
+import (
+	"seal/pkg/validator"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var t0 = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+func hoursAgo(hours int) time.Time { return t0.Add(-time.Duration(hours) * time.Hour) }
+
+// fakeLister история модема от новых к старым, как отдаёт сервис команд, и запрошенные страницы
+type fakeLister struct {
+	commands []Command
+	offsets  []int
+}
+
+func (f *fakeLister) List(imei string, offset int, limit int, newOnly bool) ([]Command, error) {
+	f.offsets = append(f.offsets, offset)
+
+	if offset >= len(f.commands) {
+		return []Command{}, nil
+	}
+
+	return f.commands[offset:min(len(f.commands), offset+limit)], nil
+}
+
+// hourly n команд, по одной в час назад от t0
+func hourly(n int) []Command {
+	res := make([]Command, n)
+	for i := range res {
+		res[i] = Command{Id: int32(i), Dateon: hoursAgo(i)}
+	}
+
+	return res
+}
+
+func TestStatus(t *testing.T) {
+	now := t0
+
+	tests := []struct {
+		name    string
+		command Command
+		want    string
+	}{
+		{"ждёт доставки", Command{}, STATUS_PENDING},
+		{"ждёт после неудачной попытки", Command{TryNumber: 2}, STATUS_PENDING},
+		{"модем ответил", Command{TryNumber: 1, ResponseDate: &now}, STATUS_DELIVERED},
+		{"ответ важнее отмены", Command{TryNumber: 1, ResponseDate: &now, AbortDate: &now}, STATUS_DELIVERED},
+		{"отменена после попыток", Command{TryNumber: 3, AbortDate: &now}, STATUS_FAILED},
+		{"отменена до первой попытки", Command{AbortDate: &now}, STATUS_ABORTED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.command.status())
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+	now := t0
+	commands := hourly(30)
+	commands[1].ResponseDate = &now
+	commands[2].AbortDate = &now
+	commands[3].AbortDate, commands[3].TryNumber = &now, 1
+	// порядок от сервиса не гарантирован для общего списка нескольких модемов
+	commands[4], commands[5] = commands[5], commands[4]
+
+	tests := []struct {
+		name     string
+		params   HistoryQueryParams
+		ids      []int32
+		filtered int64
+	}{
+		{"по умолчанию 10 от новых к старым", HistoryQueryParams{}, []int32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 30},
+		{"страница", HistoryQueryParams{Limit: 3, Offset: 4}, []int32{4, 5, 6}, 30},
+		{"страница за концом", HistoryQueryParams{Offset: 30}, []int32{}, 30},
+		{"период", HistoryQueryParams{From: hoursAgo(12), To: hoursAgo(10)}, []int32{10, 11, 12}, 3},
+		{"статус", HistoryQueryParams{Status: []string{STATUS_DELIVERED, STATUS_FAILED}}, []int32{1, 3}, 2},
+		{"период и статус", HistoryQueryParams{To: hoursAgo(2), Status: []string{STATUS_ABORTED, STATUS_FAILED}}, []int32{2, 3}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := page(commands, tt.params)
+
+			ids := []int32{}
+			for _, c := range list.Data {
+				ids = append(ids, c.Id)
+				assert.Equal(t, c.status(), c.Status)
+			}
+
+			assert.Equal(t, tt.ids, ids)
+			assert.Equal(t, int64(len(commands)), list.RecordsTotal)
+			assert.Equal(t, tt.filtered, list.RecordsFiltered)
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	newService := func(lister *fakeLister) *usecase {
+		return &usecase{validator: validator.NewValidator(), usecase: CoreUseCase{Commands: lister}}
+	}
+
+	t.Run("без фильтров одна страница сервиса", func(t *testing.T) {
+		lister := &fakeLister{commands: hourly(250)}
+
+		list, err := newService(lister).History("imei", HistoryQueryParams{Limit: 5, Offset: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{10}, lister.offsets)
+		assert.Len(t, list.Data, 5)
+		assert.Equal(t, int64(16), list.RecordsTotal)
+	})
+
+	t.Run("чтение заканчивается на начале периода", func(t *testing.T) {
+		lister := &fakeLister{commands: hourly(250)}
+
+		list, err := newService(lister).History("imei", HistoryQueryParams{From: hoursAgo(150)})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, HISTORY_PAGE}, lister.offsets)
+		assert.Equal(t, int64(151), list.RecordsFiltered)
+		assert.Equal(t, int32(0), list.Data[0].Id)
+	})
+
+	t.Run("фильтр по статусу читает всю историю", func(t *testing.T) {
+		lister := &fakeLister{commands: hourly(250)}
+
+		list, err := newService(lister).History("imei", HistoryQueryParams{Status: []string{STATUS_PENDING}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, HISTORY_PAGE, 2 * HISTORY_PAGE}, lister.offsets)
+		assert.Equal(t, int64(250), list.RecordsFiltered)
+	})
+}
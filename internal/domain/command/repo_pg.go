@@ -0,0 +1,51 @@
+package command
+
+import (
+	"context"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+func (r *repo) Catalog(device string) ([]Template, error) {
+	q := query.New[Template](r.ctx, r.db).
+		Select("t.*", "").
+		From("command_templates", "t").
+		FilterWhere(query.EQUEL, "t.device", device).
+		OrderBy("t.device, t.name")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Devices страница модемов для общего списка команд, поиск по серийному номеру или IMEI
+func (r *repo) Devices(params FleetQueryParams) ([]Device, error) {
+	q := query.New[Device](r.ctx, r.db).
+		Select("m.id", "").
+		AddSelect("m.imei::text", "imei").
+		From("modems", "m").
+		FilterWhere(params.FindType, "m.serial", params.Find).
+		OrFilterWhere(params.FindType, "m.imei", params.Find).
+		Grouped().
+		AndFilterWhere(query.IN, "m.id", params.Modem).
+		OrderBy("m.id").
+		Limit(params.ModemLimit).
+		Offset(uint16(params.ModemOffset))
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
@@ -0,0 +1,106 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Template команда из каталога: описание и JSON Schema параметров для формы в интерфейсе
+type Template struct {
+	Name        string          `json:"name"`
+	Device      string          `json:"device"`
+	Description string          `json:"description"`
+	Params      json.RawMessage `json:"params" swaggertype:"object"`
+	Models      []string        `json:"models"` // аппаратные ревизии модемов, пусто - все
+	Roles       []int           `json:"roles"`  // роли пользователей, пусто - все
+}
+
+// Supports можно ли отправить команду модему этой аппаратной ревизии
+func (t Template) Supports(model string) bool {
+	if len(t.Models) == 0 {
+		return true
+	}
+
+	for _, m := range t.Models {
+		if strings.EqualFold(m, model) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allowed разрешена ли команда роли пользователя
+func (t Template) Allowed(role int) bool {
+	if len(t.Roles) == 0 {
+		return true
+	}
+
+	for _, r := range t.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compile схема параметров команды, компилируется один раз при загрузке каталога
+func (t Template) compile() (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(t.Name, bytes.NewReader(t.Params)); err != nil {
+		return nil, fmt.Errorf("command %s params schema: %w", t.Name, err)
+	}
+
+	schema, err := compiler.Compile(t.Name)
+	if err != nil {
+		return nil, fmt.Errorf("command %s params schema: %w", t.Name, err)
+	}
+
+	return schema, nil
+}
+
+// validateParams проверка параметров по схеме, ключ ошибки - путь к полю в параметрах
+func validateParams(schema *jsonschema.Schema, params any) (map[string]string, error) {
+	// параметры из запроса приводятся к тем же типам, что даёт json.Unmarshal
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+
+	err = schema.Validate(value)
+	if err == nil {
+		return nil, nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	errs := map[string]string{}
+	for _, e := range validationErr.BasicOutput().Errors {
+		if e.Error == "" || strings.HasPrefix(e.Error, "doesn't validate with") {
+			continue
+		}
+
+		field := "params" + strings.ReplaceAll(e.InstanceLocation, "/", ".")
+		if _, exists := errs[field]; !exists {
+			errs[field] = e.Error
+		}
+	}
+
+	if len(errs) == 0 {
+		errs["params"] = validationErr.Error()
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,71 @@
+package command
+
+import (
+	app_interface "seal/internal/app/interface"
+	"seal/pkg/app_error"
+)
+
+type CoreUseCase struct {
+	Commands lister
+}
+
+type usecase struct {
+	repo        Repo
+	logger      app_interface.Logger
+	validator   app_interface.Validator
+	concurrency int
+	usecase     CoreUseCase
+	catalog     catalog
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, concurrency int, coreUsecase CoreUseCase) Usecase {
+	return &usecase{repo: repo, logger: logger, validator: validator, concurrency: max(1, concurrency), usecase: coreUsecase}
+}
+
+func (s *usecase) Catalog(params CatalogQueryParams) ([]Template, error) {
+	if errs := s.validator.Struct(params); errs != nil {
+		return nil, app_error.ValidationError(errs)
+	}
+
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return list(entries, params.Device), nil
+}
+
+// Check проверка команды по каталогу до отправки: роль автора и параметры по схеме.
+// Команды не из каталога не проверяются, их параметры проверит сервис команд, тогда возвращается nil
+func (s *usecase) Check(name string, params any, role int) (*Template, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	e, ok := entries[name]
+	if !ok {
+		return nil, nil
+	}
+
+	if !e.Allowed(role) {
+		return nil, app_error.ErrUnauthorized
+	}
+
+	if e.schemaErr != nil {
+		return nil, app_error.InternalServerError(e.schemaErr)
+	}
+
+	errs, err := validateParams(e.schema, params)
+	if err != nil {
+		return nil, app_error.InternalServerError(err)
+	}
+
+	if len(errs) > 0 {
+		return nil, app_error.ValidationError(errs)
+	}
+
+	template := e.Template
+
+	return &template, nil
+}
@@ -0,0 +1,58 @@
+package command_job
+
+import (
+	"seal/internal/domain/user"
+	"time"
+)
+
+type Db struct {
+	Id         int        `json:"id"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	FinishedAt *time.Time `json:"finished_at" db:"finished_at"`
+	Name       string     `json:"name"`
+	Params     any        `json:"params"`
+	Filter     *Filter    `json:"filter"`
+	Author     *int       `json:"author"`
+	Total      int        `json:"total"`
+}
+
+// Job массовая отправка команды, пока FinishedAt пуст - команда ещё рассылается
+type Job struct {
+	Id         int          `json:"id"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	FinishedAt *time.Time   `json:"finished_at" db:"finished_at"`
+	Name       string       `json:"name"`
+	Params     any          `json:"params"`
+	Filter     *Filter      `json:"filter"`
+	Author     *user.Author `json:"author"`
+	Total      int          `json:"total"`
+	Pending    int          `json:"pending"`
+	Sent       int          `json:"sent"`
+	Failed     int          `json:"failed"`
+	Items      []Item       `json:"items"`
+}
+
+// Item результат отправки одному модему, Error - текст или ошибки проверки параметров от сервиса команд
+type Item struct {
+	Modem  int        `json:"modem"`
+	Serial uint64     `json:"serial"`
+	Imei   string     `json:"imei"`
+	Status int        `json:"status"`
+	Error  any        `json:"error"`
+	SentAt *time.Time `json:"sent_at" db:"sent_at"`
+}
+
+// stale незавершённое задание, рассылку которого никто не ведёт
+type stale struct {
+	Id     int    `db:"id"`
+	Name   string `db:"name"`
+	Params any    `db:"params"`
+	Author string `db:"author"`
+	Role   int    `db:"role"`
+}
+
+type Target struct {
+	Id               int    `db:"id"`
+	Imei             string `db:"imei"`
+	HardwareRevision string `db:"hardware_revision"`
+}
@@ -0,0 +1,62 @@
+package command_job
+
+import (
+	"seal/internal/domain/command"
+	"time"
+)
+
+const STATUS_PENDING = 0
+const STATUS_SENT = 1
+const STATUS_FAILED = 2
+
+// HEARTBEAT_STALE задание без признаков рассылки дольше этого считается брошенным и подхватывается
+const HEARTBEAT_STALE = time.Minute
+
+// HEARTBEAT_INTERVAL как часто идущая рассылка обновляет признак, одна отправка может длиться дольше HEARTBEAT_STALE
+const HEARTBEAT_INTERVAL = HEARTBEAT_STALE / 3
+
+// MAX_TARGETS модемов в одной массовой отправке, не больше
+const MAX_TARGETS = 5000
+
+type BulkRequest struct {
+	Ids    []int   `json:"ids" validate:"omitempty,max=5000,dive,min=1"`
+	Filter *Filter `json:"filter"`
+	Name   string  `json:"name" validate:"required,max=50,min=5"`
+	Params any     `json:"params" validate:"required"`
+}
+
+// Filter выбор модемов по версии прошивки и строке поиска по серийному номеру или IMEI
+type Filter struct {
+	SoftwareVersion  string `json:"software_version,omitempty" validate:"max=50"`
+	HardwareRevision string `json:"hardware_revision,omitempty" validate:"max=50"`
+	Find             string `json:"find,omitempty" validate:"max=1024"`
+	FindType         uint8  `json:"find_type,omitempty" validate:"max=4"`
+}
+
+type Repo interface {
+	Targets(ids []int, filter *Filter) ([]Target, error)
+	Create(data Db, targets []Target) (int, error)
+	GetById(id int) (Job, error)
+	SetResult(id int, modem int, status int, sendErr any) error
+	Heartbeat(id int) error
+	Finish(id int) error
+	Release(id int) error
+	Stale(after time.Duration) ([]stale, error)
+	Pending(id int) ([]Target, error)
+}
+
+type Usecase interface {
+	Bulk(data BulkRequest, userId int, author string, role int) (Job, error)
+	GetById(id int) (Job, error)
+	Resume() error
+}
+
+// sender отправка команды модему, см. commands.grpcClient
+type sender interface {
+	Send(imei string, name string, params any, author string) (bool, error)
+}
+
+type CoreUseCase struct {
+	Catalog  command.Usecase
+	Commands sender
+}
@@ -0,0 +1,226 @@
+package command_job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"seal/pkg/app_error"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+// Targets модемы по списку id или по фильтру, не больше MAX_TARGETS + 1, чтобы можно было понять, что их больше
+func (r *repo) Targets(ids []int, filter *Filter) ([]Target, error) {
+	q := query.New[Target](r.ctx, r.db).
+		Select("m.id", "").
+		AddSelect("m.imei::text", "imei").
+		AddSelect("coalesce(m.extra->>'hardware_revision', '')", "hardware_revision").
+		From("modems", "m")
+
+	if filter == nil {
+		q.Where(query.IN, "m.id", ids)
+	} else {
+		q.FilterWhere(filter.FindType, "m.serial", filter.Find).
+			OrFilterWhere(filter.FindType, "m.imei", filter.Find).
+			Grouped().
+			AndFilterWhere(query.EQUEL, "m.extra->>'software_version'", filter.SoftwareVersion).
+			AndFilterWhere(query.EQUEL, "m.extra->>'hardware_revision'", filter.HardwareRevision)
+	}
+
+	q.OrderBy("m.id").
+		Limit(MAX_TARGETS + 1)
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Create задание вместе с модемами одним запросом
+func (r *repo) Create(job Db, targets []Target) (int, error) {
+	q := `WITH j AS (
+			INSERT INTO command_jobs (name, params, filter, author, total, heartbeat_at)
+			VALUES ($1, $2, $3, $4, $5, now())
+			RETURNING id
+		), i AS (
+			INSERT INTO command_job_items (job, modem, imei)
+			SELECT j.id, t.modem, t.imei FROM j, unnest($6::int[], $7::text[]) t(modem, imei)
+		)
+		SELECT id FROM j
+	`
+
+	modems := make([]int, 0, len(targets))
+	imeis := make([]string, 0, len(targets))
+	for _, t := range targets {
+		modems = append(modems, t.Id)
+		imeis = append(imeis, t.Imei)
+	}
+
+	// строка в параметрах команды - тоже json, поэтому кодируется явно
+	params, err := json.Marshal(job.Params)
+	if err != nil {
+		return 0, err
+	}
+
+	qp := []any{job.Name, params, job.Filter, job.Author, len(targets), modems, imeis}
+
+	err = r.db.QueryRow(r.ctx, q, qp...).Scan(&job.Id)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(job.Id).SetError(err).GetMsg())
+
+	return job.Id, err
+}
+
+func (r *repo) GetById(id int) (Job, error) {
+	q := query.New[Job](r.ctx, r.db).
+		Select("j.id", "").
+		AddSelect("j.created_at", "").
+		AddSelect("j.finished_at", "").
+		AddSelect("j.name", "").
+		AddSelect("j.params", "").
+		AddSelect("j.filter", "").
+		AddSelect("case when ua.id is null then null else jsonb_build_object('id', ua.id, 'login', ua.login) end", "author").
+		AddSelect("j.total", "").
+		AddSelect(fmt.Sprintf("(select count(*) from command_job_items i where i.job = j.id and i.status = %d)", STATUS_PENDING), "pending").
+		AddSelect(fmt.Sprintf("(select count(*) from command_job_items i where i.job = j.id and i.status = %d)", STATUS_SENT), "sent").
+		AddSelect(fmt.Sprintf("(select count(*) from command_job_items i where i.job = j.id and i.status = %d)", STATUS_FAILED), "failed").
+		AddSelect("(select coalesce(jsonb_agg(t order by t.modem), '[]') from (select i.modem, m.serial, i.imei, i.status, i.error, i.sent_at "+
+			"from command_job_items i left join modems m on m.id = i.modem where i.job = j.id) t)", "items").
+		From("command_jobs", "j").
+		LeftJoin("ua", "users", "ua.id = j.author").
+		Where(query.EQUEL, "j.id", id)
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data.Id).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// SetResult результат отправки модему, заодно признак того, что рассылка идёт
+func (r *repo) SetResult(id int, modem int, status int, sendErr any) error {
+	q := `WITH i AS (
+			UPDATE command_job_items
+			set (status, error, sent_at) = ($3, $4, now())
+			where job = $1 and modem = $2
+		)
+		UPDATE command_jobs set heartbeat_at = now() where id = $1
+	`
+
+	var errJson []byte
+	if sendErr != nil {
+		errJson, _ = json.Marshal(sendErr)
+	}
+
+	qp := []any{id, modem, status, errJson}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
+
+// Heartbeat признак того, что рассылка идёт, пока отправки модемам ещё не завершились
+func (r *repo) Heartbeat(id int) error {
+	q := `UPDATE command_jobs set heartbeat_at = now() where id = $1 and finished_at is null`
+
+	qp := []any{id}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
+
+func (r *repo) Finish(id int) error {
+	q := `UPDATE command_jobs set finished_at = now(), heartbeat_at = null where id = $1`
+
+	qp := []any{id}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
+
+// Release отпускает задание при остановке, чтобы следующий запуск подхватил его сразу
+func (r *repo) Release(id int) error {
+	q := `UPDATE command_jobs set heartbeat_at = null where id = $1 and finished_at is null`
+
+	qp := []any{id}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
+
+// Stale захватывает незавершённые задания, рассылку которых никто не ведёт дольше after
+func (r *repo) Stale(after time.Duration) ([]stale, error) {
+	q := `UPDATE command_jobs j
+		SET heartbeat_at = now()
+		FROM (
+			SELECT id FROM command_jobs
+			WHERE finished_at IS NULL AND (heartbeat_at IS NULL OR heartbeat_at < now() - make_interval(secs => $1))
+			FOR UPDATE SKIP LOCKED
+		) s
+		WHERE j.id = s.id
+		RETURNING j.id, j.name, j.params,
+			coalesce((select u.login from users u where u.id = j.author), '') as author,
+			coalesce((select u.role from users u where u.id = j.author), -1) as role
+	`
+
+	qp := []any{after.Seconds()}
+
+	rows, err := r.db.Query(r.ctx, q, qp...)
+	if err != nil {
+		r.logger.Error(query.NewLogSql(q, qp...).SetError(err).GetMsg())
+		return nil, err
+	}
+
+	data, err := pgx.CollectRows(rows, pgx.RowToStructByName[stale])
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Pending модемы задания, которым команда ещё не отправлялась
+func (r *repo) Pending(id int) ([]Target, error) {
+	q := query.New[Target](r.ctx, r.db).
+		Select("i.modem", "id").
+		AddSelect("i.imei", "").
+		AddSelect("coalesce(m.extra->>'hardware_revision', '')", "hardware_revision").
+		From("command_job_items", "i").
+		LeftJoin("m", "modems", "m.id = i.modem").
+		Where(query.EQUEL, "i.job", id).
+		AndWhere(query.EQUEL, "i.status", STATUS_PENDING).
+		OrderBy("i.modem")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
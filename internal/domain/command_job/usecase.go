@@ -0,0 +1,246 @@
+package command_job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/command"
+	"seal/pkg/app_error"
+	"sync"
+	"time"
+)
+
+type usecase struct {
+	repo        Repo
+	logger      app_interface.Logger
+	validator   app_interface.Validator
+	concurrency int
+	usecase     CoreUseCase
+	ctx         context.Context
+	tasks       *sync.WaitGroup
+}
+
+// NewUsecase ctx отменяется при остановке приложения, tasks - рассылки, завершения которых ждёт остановка
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, concurrency int,
+	ctx context.Context, tasks *sync.WaitGroup, coreUsecase CoreUseCase) Usecase {
+	return &usecase{repo, logger, validator, max(1, concurrency), coreUsecase, ctx, tasks}
+}
+
+// Bulk создаёт задание и рассылает команду в фоне, ход выполнения - через GetById
+func (s *usecase) Bulk(data BulkRequest, userId int, author string, role int) (Job, error) {
+	if errs := s.validator.Struct(data); errs != nil {
+		return Job{}, app_error.ValidationError(errs)
+	}
+
+	if len(data.Ids) > 0 && data.Filter != nil {
+		return Job{}, app_error.ValidationError(map[string]string{"ids": "Укажите либо список модемов, либо фильтр"})
+	}
+
+	if data.Filter != nil {
+		if *data.Filter == (Filter{FindType: data.Filter.FindType}) {
+			return Job{}, app_error.ValidationError(map[string]string{"filter": "Пустой фильтр выбирает все модемы"})
+		}
+	} else if len(data.Ids) == 0 {
+		return Job{}, app_error.ValidationError(map[string]string{"ids": "Не выбраны модемы"})
+	}
+
+	template, err := s.usecase.Catalog.Check(data.Name, data.Params, role)
+	if err != nil {
+		return Job{}, err
+	}
+
+	targets, err := s.repo.Targets(data.Ids, data.Filter)
+	if err != nil {
+		return Job{}, err
+	}
+
+	if len(targets) > MAX_TARGETS {
+		return Job{}, app_error.ValidationError(map[string]string{
+			"filter": fmt.Sprintf("Под фильтр попадает больше %d модемов", MAX_TARGETS),
+		})
+	}
+
+	if missing := missingIds(data.Ids, targets); len(missing) > 0 {
+		return Job{}, app_error.ValidationError(map[string]any{"ids": missing})
+	}
+
+	if len(targets) == 0 {
+		return Job{}, app_error.ValidationError(map[string]string{"filter": "Нет модемов под фильтр"})
+	}
+
+	id, err := s.repo.Create(Db{
+		Name:   data.Name,
+		Params: data.Params,
+		Filter: data.Filter,
+		Author: &userId,
+	}, targets)
+	if err != nil {
+		return Job{}, err
+	}
+
+	s.start(id, targets, template, data.Name, data.Params, author)
+
+	return s.repo.GetById(id)
+}
+
+// Resume подхватывает задания, рассылка которых прервалась остановкой или падением экземпляра API.
+// Каталог проверяется заново с текущей ролью автора, при ошибке оставшимся модемам записывается она
+func (s *usecase) Resume() error {
+	jobs, err := s.repo.Stale(HEARTBEAT_STALE)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		targets, err := s.repo.Pending(job.Id)
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("Command job %d resume: %v", job.Id, err))
+			continue
+		}
+
+		template, err := s.usecase.Catalog.Check(job.Name, job.Params, job.Role)
+		if err != nil {
+			for _, target := range targets {
+				if err := s.repo.SetResult(job.Id, target.Id, STATUS_FAILED, errorBody(err)); err != nil {
+					s.logger.Error(fmt.Sprintf("Command job %d modem %d: %v", job.Id, target.Id, err))
+				}
+			}
+
+			targets = nil
+		}
+
+		s.logger.Info(fmt.Sprintf("Command job %d resumed, %d modems left", job.Id, len(targets)))
+		s.start(job.Id, targets, template, job.Name, job.Params, job.Author)
+	}
+
+	return nil
+}
+
+// start рассылка в фоне, остановка приложения дожидается отправок, которые уже начались
+func (s *usecase) start(id int, targets []Target, template *command.Template, name string, params any, author string) {
+	s.tasks.Add(1)
+
+	go func() {
+		defer s.tasks.Done()
+		s.dispatch(id, targets, template, name, params, author)
+	}()
+}
+
+func (s *usecase) GetById(id int) (Job, error) {
+	return s.repo.GetById(id)
+}
+
+// dispatch отправляет команду модемам задания, не больше concurrency одновременно.
+// Модемам моделей, которым команда из каталога не подходит, команда не отправляется.
+// При остановке приложения новые отправки не начинаются, оставшиеся модемы ждут Resume
+func (s *usecase) dispatch(id int, targets []Target, template *command.Template, name string, params any, author string) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.concurrency)
+	stopped := false
+
+	stopHeartbeat := s.heartbeat(id, HEARTBEAT_INTERVAL)
+
+loop:
+	for _, target := range targets {
+		select {
+		case <-s.ctx.Done():
+			stopped = true
+			break loop
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+
+		go func(target Target) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			status, sendErr := STATUS_SENT, any(nil)
+			if template != nil && !template.Supports(target.HardwareRevision) {
+				status, sendErr = STATUS_FAILED, "Команда не поддерживается моделью модема"
+			} else if _, err := s.usecase.Commands.Send(target.Imei, name, params, author); err != nil {
+				status, sendErr = STATUS_FAILED, errorBody(err)
+			}
+
+			if err := s.repo.SetResult(id, target.Id, status, sendErr); err != nil {
+				s.logger.Error(fmt.Sprintf("Command job %d modem %d: %v", id, target.Id, err))
+			}
+		}(target)
+	}
+
+	wg.Wait()
+	stopHeartbeat()
+
+	if stopped {
+		if err := s.repo.Release(id); err != nil {
+			s.logger.Error(fmt.Sprintf("Command job %d release: %v", id, err))
+		}
+
+		return
+	}
+
+	if err := s.repo.Finish(id); err != nil {
+		s.logger.Error(fmt.Sprintf("Command job %d finish: %v", id, err))
+	}
+}
+
+// heartbeat обновляет признак рассылки раз в interval, пока идут отправки: иначе долгая отправка
+// (таймаут команды с повторами) позволила бы Resume другого экземпляра подхватить задание и отправить команду повторно.
+// Возвращённая функция останавливает обновление и дожидается последнего
+func (s *usecase) heartbeat(id int, interval time.Duration) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.repo.Heartbeat(id); err != nil {
+					s.logger.Error(fmt.Sprintf("Command job %d heartbeat: %v", id, err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// errorBody тело ошибки проверки от сервиса команд или текст ошибки
+func errorBody(err error) any {
+	var appErr *app_error.AppError
+	if errors.As(err, &appErr) {
+		return appErr.GetBody()
+	}
+
+	return err.Error()
+}
+
+func missingIds(ids []int, targets []Target) []int {
+	found := make(map[int]bool, len(targets))
+	for _, t := range targets {
+		found[t.Id] = true
+	}
+
+	missing := []int{}
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	return missing
+}
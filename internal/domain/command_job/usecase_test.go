@@ -0,0 +1,132 @@
+package command_job
+
+import (
+	"context"
+	"errors"
+	"seal/internal/domain/command"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogger struct{}
+
+func (testLogger) Fatal(msg string, args ...any)                   {}
+func (testLogger) Error(msg string, args ...any)                   {}
+func (testLogger) Info(msg string, args ...any)                    {}
+func (testLogger) Debug(msg string, args ...any)                   {}
+func (testLogger) DebugOrError(err error, msg string, args ...any) {}
+
+type fakeRepo struct {
+	Repo
+	mu         sync.Mutex
+	heartbeats int
+	results    map[int]int
+	finished   bool
+	released   bool
+}
+
+func (r *fakeRepo) SetResult(id int, modem int, status int, sendErr any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results[modem] = status
+	return nil
+}
+
+func (r *fakeRepo) Heartbeat(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.heartbeats++
+	return nil
+}
+
+func (r *fakeRepo) Finish(id int) error {
+	r.finished = true
+	return nil
+}
+
+func (r *fakeRepo) Release(id int) error {
+	r.released = true
+	return nil
+}
+
+func (r *fakeRepo) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.heartbeats
+}
+
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (f *fakeSender) Send(imei string, name string, params any, author string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sent = append(f.sent, imei)
+	if imei == "bad" {
+		return false, errors.New("unavailable")
+	}
+
+	return true, nil
+}
+
+func newTestUsecase(ctx context.Context, repo *fakeRepo, sender *fakeSender) *usecase {
+	return &usecase{
+		repo:        repo,
+		logger:      testLogger{},
+		concurrency: 2,
+		usecase:     CoreUseCase{Commands: sender},
+		ctx:         ctx,
+		tasks:       &sync.WaitGroup{},
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	repo := &fakeRepo{}
+	s := newTestUsecase(context.Background(), repo, &fakeSender{})
+
+	stop := s.heartbeat(1, 5*time.Millisecond)
+	assert.Eventually(t, func() bool { return repo.count() >= 2 }, time.Second, time.Millisecond)
+	stop()
+
+	// после остановки признак больше не обновляется, иначе Release не отпустил бы задание
+	n := repo.count()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, n, repo.count())
+}
+
+func TestDispatch(t *testing.T) {
+	targets := []Target{{Id: 1, Imei: "ok"}, {Id: 2, Imei: "bad"}, {Id: 3, Imei: "ok", HardwareRevision: "B"}}
+	template := &command.Template{Models: []string{"A", "a1"}}
+
+	t.Run("рассылка завершена", func(t *testing.T) {
+		repo := &fakeRepo{results: map[int]int{}}
+		sender := &fakeSender{}
+		s := newTestUsecase(context.Background(), repo, sender)
+
+		s.dispatch(1, targets[:2], nil, "reboot", nil, "admin")
+
+		assert.Equal(t, map[int]int{1: STATUS_SENT, 2: STATUS_FAILED}, repo.results)
+		assert.True(t, repo.finished)
+		assert.False(t, repo.released)
+	})
+
+	t.Run("модель не поддерживается", func(t *testing.T) {
+		repo := &fakeRepo{results: map[int]int{}}
+		sender := &fakeSender{}
+		s := newTestUsecase(context.Background(), repo, sender)
+
+		s.dispatch(1, targets[2:], template, "reboot", nil, "admin")
+
+		assert.Equal(t, map[int]int{3: STATUS_FAILED}, repo.results)
+		assert.Empty(t, sender.sent)
+	})
+}
@@ -1,15 +1,37 @@
 package custom
 
 import (
+	"fmt"
 	"seal/internal/repository/pg/query"
 	"seal/internal/transport"
 	"time"
 )
 
 type Db struct {
-	Id        int       `json:"id"`
-	Title     string    `json:"title" validate:"required,max=50,min=1"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Id        int          `json:"id"`
+	Title     string       `json:"title" validate:"required,max=50,min=1"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	Latitude  *float32     `json:"latitude" validate:"omitempty,max=90,min=-90"`
+	Longitude *float32     `json:"longitude" validate:"omitempty,max=180,min=-180"`
+	Radius    int          `json:"radius" validate:"max=100000,min=0"`
+	Area      [][2]float32 `json:"area"`
+}
+
+// ZONE_DEPARTURE зона таможни отправления маршрута
+const ZONE_DEPARTURE = 0
+
+// ZONE_DESTINATION зона таможни назначения маршрута
+const ZONE_DESTINATION = 1
+
+// InZoneSql условие нахождения точки (latitude, longitude) в зоне таможни с псевдонимом zone:
+// полигон, если задан, иначе круг радиусом zone.radius
+func InZoneSql(zone, latitude, longitude string) string {
+	return fmt.Sprintf(`case 
+		when %[1]s.area is not null then %[1]s.area @> point(%[2]s, %[3]s)
+		when %[1]s.radius > 0 and %[1]s.latitude is not null and %[1]s.longitude is not null 
+			then (point(%[1]s.longitude, %[1]s.latitude) <@> point(%[3]s, %[2]s)) * 1609.34 <= %[1]s.radius
+		else false
+	end`, zone, latitude, longitude)
 }
 
 type Repo interface {
@@ -3,9 +3,17 @@ package custom
 type Custom = Db
 
 type CreateRequest struct {
-	Title string `json:"title" validate:"required,max=50,min=1"`
+	Title     string       `json:"title" validate:"required,max=50,min=1"`
+	Latitude  *float32     `json:"latitude,omitempty"`
+	Longitude *float32     `json:"longitude,omitempty"`
+	Radius    int          `json:"radius,omitempty"`
+	Area      [][2]float32 `json:"area,omitempty"`
 }
 
 type UpdateRequest struct {
-	Title string `json:"title,omitempty" validate:"max=50"`
+	Title     string        `json:"title,omitempty" validate:"max=50"`
+	Latitude  *float32      `json:"latitude,omitempty"`
+	Longitude *float32      `json:"longitude,omitempty"`
+	Radius    *int          `json:"radius,omitempty"`
+	Area      *[][2]float32 `json:"area,omitempty"`
 }
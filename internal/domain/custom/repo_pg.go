@@ -3,15 +3,20 @@ package custom
 import (
 	"context"
 	"errors"
+	"fmt"
 	app_interface "seal/internal/app/interface"
 	"seal/internal/repository/pg"
 	"seal/internal/repository/pg/query"
 	"seal/internal/transport"
 	"seal/pkg/app_error"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 )
 
+// areaSelect полигон в виде json-массива точек [[широта, долгота], ...]
+const areaSelect = "translate(c.area::text, '()', '[]')::jsonb"
+
 type repo struct {
 	db     pg.DbClient
 	logger app_interface.Logger
@@ -22,63 +27,73 @@ func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) R
 	return &repo{db, logger, ctx}
 }
 
+// areaForQuery полигон зоны в формате postgres, nil если полигон не задан
+func areaForQuery(area [][2]float32) any {
+	if len(area) == 0 {
+		return nil
+	}
+
+	var points []string
+	for _, v := range area {
+		points = append(points, fmt.Sprintf("(%f,%f)", v[0], v[1]))
+	}
+
+	return fmt.Sprintf("(%s)", strings.Join(points, ", "))
+}
+
 func (r *repo) Create(custom Db) (Custom, error) {
 	q := `INSERT INTO customs 
-		(title)
-		VALUES ($1)
-		RETURNING *
+		(title, latitude, longitude, radius, area)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
 	`
 
-	qp := []any{custom.Title}
+	qp := []any{custom.Title, custom.Latitude, custom.Longitude, custom.Radius, areaForQuery(custom.Area)}
 
-	rows, _ := r.db.Query(r.ctx, q, qp...)
+	err := r.db.QueryRow(r.ctx, q, qp...).Scan(&custom.Id)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(custom.Id).SetError(err).GetMsg())
 
-	data, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[Custom])
-	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(data).SetError(err).GetMsg())
+	if err != nil {
+		return Custom{}, err
+	}
 
-	return data, err
+	return r.GetById(custom.Id)
 }
 
 func (r *repo) Update(custom Db) (Custom, error) {
 	q := `UPDATE customs 
-			set title = $2
+			set (title, latitude, longitude, radius, area) = ($2, $3, $4, $5, $6)
 		where id = $1
-		RETURNING *
+		RETURNING id
 	`
 
-	qp := []any{custom.Id, custom.Title}
+	qp := []any{custom.Id, custom.Title, custom.Latitude, custom.Longitude, custom.Radius, areaForQuery(custom.Area)}
 
-	rows, _ := r.db.Query(r.ctx, q, qp...)
+	err := r.db.QueryRow(r.ctx, q, qp...).Scan(&custom.Id)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(custom.Id).SetError(err).GetMsg())
 
-	data, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[Custom])
-	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(data).SetError(err).GetMsg())
+	if err != nil {
+		return Custom{}, err
+	}
 
-	return data, err
+	return r.GetById(custom.Id)
 }
 
 func (r *repo) GetById(id int) (Custom, error) {
-	q := query.New[Custom](r.ctx, r.db).
-		Select("*", "").
-		From("customs", "").
-		Where(query.EQUEL, "id", id)
-
-	data, err := q.One()
-
-	if errors.Is(err, pgx.ErrNoRows) {
-		return data, app_error.ErrNotFound
-	}
-
-	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
-
-	return data, err
-
+	return r.GetDbById(id)
 }
 
 func (r *repo) GetDbById(id int) (Db, error) {
 	q := query.New[Db](r.ctx, r.db).
-		Select("*", "").
-		From("customs", "").
-		Where(query.EQUEL, "id", id)
+		Select("c.id", "").
+		AddSelect("c.title", "").
+		AddSelect("c.created_at", "").
+		AddSelect("c.latitude", "").
+		AddSelect("c.longitude", "").
+		AddSelect("c.radius", "").
+		AddSelect(areaSelect, "area").
+		From("customs", "c").
+		Where(query.EQUEL, "c.id", id)
 
 	data, err := q.One()
 
@@ -93,8 +108,14 @@ func (r *repo) GetDbById(id int) (Db, error) {
 
 func (r *repo) List(params transport.QueryParams) (query.List[Custom], error) {
 	q := query.New[Custom](r.ctx, r.db).
-		Select("*", "").
-		From("customs", "").
+		Select("c.id", "").
+		AddSelect("c.title", "").
+		AddSelect("c.created_at", "").
+		AddSelect("c.latitude", "").
+		AddSelect("c.longitude", "").
+		AddSelect("c.radius", "").
+		AddSelect(areaSelect, "area").
+		From("customs", "c").
 		FilterWhere(params.FindType, "title", params.Find).
 		OrderBy("title").
 		Limit(params.Limit)
@@ -11,6 +11,11 @@ func (s *usecase) validate(model Db) (map[string]string, error) {
 		return errs, nil
 	}
 
+	if errs := zoneErrors(model); len(errs) > 0 {
+		s.logger.Debug("Ошибки валидации", errs)
+		return errs, nil
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	errs := map[string]string{}
@@ -47,3 +52,25 @@ func (s *usecase) existsByUnique(wg *sync.WaitGroup, ch chan domain.Res, model D
 		ch <- domain.Res{Errs: map[string]string{"title": "Не уникально"}, Err: nil}
 	}
 }
+
+// zoneErrors проверяет зону таможни: полигон не менее чем из трёх точек либо центр с радиусом
+func zoneErrors(model Db) map[string]string {
+	errs := map[string]string{}
+
+	if len(model.Area) > 0 && len(model.Area) < 3 {
+		errs["area"] = "Полигон должен содержать не менее трёх точек"
+	}
+
+	for _, p := range model.Area {
+		if p[0] < -90 || p[0] > 90 || p[1] < -180 || p[1] > 180 {
+			errs["area"] = "Некорректные координаты полигона"
+			break
+		}
+	}
+
+	if model.Radius > 0 && (model.Latitude == nil || model.Longitude == nil) {
+		errs["radius"] = "Для зоны с радиусом необходимо указать координаты центра"
+	}
+
+	return errs
+}
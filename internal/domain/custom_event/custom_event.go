@@ -0,0 +1,46 @@
+package custom_event
+
+import "time"
+
+type CustomEvent struct {
+	Id     int `json:"id"`
+	Custom struct {
+		Id    int    `json:"id"`
+		Title string `json:"title"`
+	} `json:"custom"`
+	Zone      int       `json:"zone"`
+	Type      int       `json:"type"`
+	DevTime   time.Time `json:"dev_time" db:"dev_time"`
+	Latitude  float32   `json:"latitude"`
+	Longitude float32   `json:"longitude"`
+}
+
+type activeShipping struct {
+	Id                   int        `json:"id"`
+	Modem                int        `json:"modem"`
+	Author               int        `json:"author"`
+	TimeStart            time.Time  `json:"time_start" db:"time_start"`
+	CustomDeparture      *int       `json:"custom_departure" db:"custom_departure"`
+	CustomDestination    *int       `json:"custom_destination" db:"custom_destination"`
+	CheckedAt            *time.Time `json:"checked_at" db:"checked_at"`
+	InDeparture          bool       `json:"in_departure" db:"in_departure"`
+	InDestination        bool       `json:"in_destination" db:"in_destination"`
+	DestinationEnteredAt *time.Time `json:"destination_entered_at" db:"destination_entered_at"`
+}
+
+type coordinate struct {
+	DevTime       time.Time `json:"dev_time" db:"dev_time"`
+	Latitude      float32   `json:"latitude"`
+	Longitude     float32   `json:"longitude"`
+	InDeparture   bool      `json:"in_departure" db:"in_departure"`
+	InDestination bool      `json:"in_destination" db:"in_destination"`
+}
+
+// check состояние проверки перевозки, сохраняемое между запусками
+type check struct {
+	Shipping             int
+	CheckedAt            time.Time
+	InDeparture          bool
+	InDestination        bool
+	DestinationEnteredAt *time.Time
+}
@@ -0,0 +1,35 @@
+package custom_event
+
+import (
+	"time"
+)
+
+type Db struct {
+	Id        int       `json:"id"`
+	Shipping  int       `json:"shipping"`
+	Custom    int       `json:"custom"`
+	Zone      int       `json:"zone"`
+	Type      int       `json:"type"`
+	DevTime   time.Time `json:"dev_time" db:"dev_time"`
+	Latitude  float32   `json:"latitude"`
+	Longitude float32   `json:"longitude"`
+}
+
+const TYPE_ENTER = 0
+const TYPE_EXIT = 1
+
+// CHECK_BATCH_SIZE количество координат перевозки, обрабатываемых за одну проверку
+const CHECK_BATCH_SIZE = 5000
+
+type Repo interface {
+	Create(data Db) error
+	ListByShipping(shippingId int) ([]CustomEvent, error)
+	ListActiveShipping() ([]activeShipping, error)
+	Coordinates(sh activeShipping, from time.Time, limit int) ([]coordinate, error)
+	SaveCheck(data check) error
+}
+
+type Usecase interface {
+	ListByShipping(shippingId int) ([]CustomEvent, error)
+	Check() error
+}
@@ -0,0 +1,127 @@
+package custom_event
+
+import (
+	"context"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/custom"
+	"seal/internal/domain/shipping"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// inZone условие нахождения координаты c в зоне таможни z
+var inZone = custom.InZoneSql("z", "c.latitude", "c.longitude")
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+func (r *repo) Create(data Db) error {
+	q := `INSERT INTO shipping_custom_events
+		(shipping, custom, zone, type, dev_time, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	qp := []any{data.Shipping, data.Custom, data.Zone, data.Type, data.DevTime, data.Latitude, data.Longitude}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
+
+func (r *repo) ListByShipping(shippingId int) ([]CustomEvent, error) {
+	q := query.New[CustomEvent](r.ctx, r.db).
+		Select("e.id", "").
+		AddSelect("jsonb_build_object('id', c.id, 'title', c.title)", "custom").
+		AddSelect("e.zone", "").
+		AddSelect("e.type", "").
+		AddSelect("e.dev_time", "").
+		AddSelect("e.latitude", "").
+		AddSelect("e.longitude", "").
+		From("shipping_custom_events", "e").
+		LeftJoin("c", "customs", "c.id = e.custom").
+		Where(query.EQUEL, "e.shipping", shippingId).
+		OrderBy("e.dev_time, e.id")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) ListActiveShipping() ([]activeShipping, error) {
+	q := query.New[activeShipping](r.ctx, r.db).
+		Select("s.id", "").
+		AddSelect("s.modem", "").
+		AddSelect("s.author", "").
+		AddSelect("s.time_start", "").
+		AddSelect("r.custom_departure", "").
+		AddSelect("r.custom_destination", "").
+		AddSelect("c.checked_at", "").
+		AddSelect("coalesce(c.in_departure, false)", "in_departure").
+		AddSelect("coalesce(c.in_destination, false)", "in_destination").
+		AddSelect("c.destination_entered_at", "").
+		From("shipping", "s").
+		InnerJoin("r", "routes", "r.id = s.route").
+		LeftJoin("c", "shipping_custom_checks", "c.shipping = s.id").
+		Where(query.EQUEL, "s.status", shipping.STATUS_ACTIVE).
+		AndWhere(query.IS_NOT_NULL, "s.modem", nil).
+		AndWhere(query.IS_NOT_NULL, "s.time_start", nil).
+		AndWhereExists("select 1 from routes rc where rc.id = s.route " +
+			"and (rc.custom_departure is not null or rc.custom_destination is not null)").
+		OrderBy("s.id")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) Coordinates(sh activeShipping, from time.Time, limit int) ([]coordinate, error) {
+	q := `select c.dev_time, c.latitude, c.longitude,
+			coalesce((select ` + inZone + ` from customs z where z.id = $3), false) in_departure,
+			coalesce((select ` + inZone + ` from customs z where z.id = $4), false) in_destination
+		from coordinates c
+		where c.modem = $1 and c.dev_time > $2 and c.dev_time <= now() 
+			and c.latitude != 'NaN' and c.longitude != 'NaN'
+		order by c.dev_time
+		limit $5
+	`
+
+	qp := []any{sh.Modem, from, sh.CustomDeparture, sh.CustomDestination, limit}
+
+	rows, _ := r.db.Query(r.ctx, q, qp...)
+
+	data, err := pgx.CollectRows(rows, pgx.RowToStructByName[coordinate])
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) SaveCheck(data check) error {
+	q := `INSERT INTO shipping_custom_checks (shipping, checked_at, in_departure, in_destination, destination_entered_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (shipping) DO UPDATE SET 
+			checked_at = EXCLUDED.checked_at,
+			in_departure = EXCLUDED.in_departure,
+			in_destination = EXCLUDED.in_destination,
+			destination_entered_at = EXCLUDED.destination_entered_at
+	`
+
+	qp := []any{data.Shipping, data.CheckedAt, data.InDeparture, data.InDestination, data.DestinationEnteredAt}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
@@ -0,0 +1,142 @@
+package custom_event
+
+import (
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/custom"
+	"seal/internal/domain/shipping"
+	"seal/pkg/app_error"
+	"time"
+)
+
+type CoreUseCase struct {
+	Shipping shipping.Usecase
+}
+
+type usecase struct {
+	repo         Repo
+	logger       app_interface.Logger
+	validator    app_interface.Validator
+	autoEndDwell time.Duration
+	usecase      CoreUseCase
+}
+
+// NewUsecase autoEndDwell - время нахождения в зоне таможни назначения, после которого перевозка
+// завершается автоматически, 0 - не завершать
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, autoEndDwell time.Duration, coreUsecase CoreUseCase) Usecase {
+	return &usecase{repo, logger, validator, autoEndDwell, coreUsecase}
+}
+
+func (s *usecase) ListByShipping(shippingId int) ([]CustomEvent, error) {
+	if exists, err := s.usecase.Shipping.Exists(shippingId); err != nil {
+		return []CustomEvent{}, err
+	} else if !exists {
+		return []CustomEvent{}, app_error.ErrNotFound
+	}
+
+	return s.repo.ListByShipping(shippingId)
+}
+
+// Check фиксирует въезд и выезд активных перевозок из зон таможен отправления и назначения маршрута
+func (s *usecase) Check() error {
+	list, err := s.repo.ListActiveShipping()
+	if err != nil {
+		return err
+	}
+
+	for _, sh := range list {
+		if err := s.checkShipping(sh); err != nil {
+			s.logger.Error(fmt.Sprintf("Ошибка проверки зон таможен перевозки %d: %v", sh.Id, err))
+		}
+	}
+
+	return nil
+}
+
+func (s *usecase) checkShipping(sh activeShipping) error {
+	from := sh.TimeStart
+	if sh.CheckedAt != nil && sh.CheckedAt.After(from) {
+		from = *sh.CheckedAt
+	}
+
+	coords, err := s.repo.Coordinates(sh, from, CHECK_BATCH_SIZE)
+	if err != nil || len(coords) == 0 {
+		return err
+	}
+
+	state := check{
+		Shipping:             sh.Id,
+		InDeparture:          sh.InDeparture,
+		InDestination:        sh.InDestination,
+		DestinationEnteredAt: sh.DestinationEnteredAt,
+	}
+
+	for _, c := range coords {
+		if sh.CustomDeparture != nil && c.InDeparture != state.InDeparture {
+			if err := s.repo.Create(newEvent(sh.Id, *sh.CustomDeparture, custom.ZONE_DEPARTURE, c.InDeparture, c)); err != nil {
+				return err
+			}
+			state.InDeparture = c.InDeparture
+		}
+
+		if sh.CustomDestination != nil && c.InDestination != state.InDestination {
+			if err := s.repo.Create(newEvent(sh.Id, *sh.CustomDestination, custom.ZONE_DESTINATION, c.InDestination, c)); err != nil {
+				return err
+			}
+			state.InDestination = c.InDestination
+
+			if c.InDestination {
+				enteredAt := c.DevTime
+				state.DestinationEnteredAt = &enteredAt
+			} else {
+				state.DestinationEnteredAt = nil
+			}
+		}
+	}
+
+	last := coords[len(coords)-1].DevTime
+	state.CheckedAt = last
+
+	if err := s.repo.SaveCheck(state); err != nil {
+		return err
+	}
+
+	if s.autoEndDwell > 0 && state.InDestination && state.DestinationEnteredAt != nil &&
+		last.Sub(*state.DestinationEnteredAt) >= s.autoEndDwell {
+		return s.autoEnd(sh, last.Sub(*state.DestinationEnteredAt))
+	}
+
+	return nil
+}
+
+// autoEnd завершает перевозку, находящуюся в зоне таможни назначения дольше заданного времени.
+// Автором перехода записывается автор перевозки
+func (s *usecase) autoEnd(sh activeShipping, dwell time.Duration) error {
+	model, err := s.usecase.Shipping.GetDbById(sh.Id)
+	if err != nil {
+		return err
+	}
+
+	reason := fmt.Sprintf("Автоматическое завершение: транспорт находится в зоне таможни назначения %d мин", int(dwell.Minutes()))
+
+	_, err = s.usecase.Shipping.End(model, sh.Author, reason)
+
+	return err
+}
+
+func newEvent(shippingId, customId, zone int, inside bool, c coordinate) Db {
+	eventType := TYPE_EXIT
+	if inside {
+		eventType = TYPE_ENTER
+	}
+
+	return Db{
+		Shipping:  shippingId,
+		Custom:    customId,
+		Zone:      zone,
+		Type:      eventType,
+		DevTime:   c.DevTime,
+		Latitude:  c.Latitude,
+		Longitude: c.Longitude,
+	}
+}
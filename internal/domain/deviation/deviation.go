@@ -0,0 +1,24 @@
+package deviation
+
+import "time"
+
+type Deviation struct {
+	Id          int        `json:"id"`
+	TimeStart   time.Time  `json:"time_start" db:"time_start"`
+	TimeEnd     *time.Time `json:"time_end" db:"time_end"`
+	MaxDistance int        `json:"max_distance" db:"max_distance"`
+	Duration    int        `json:"duration"`
+}
+
+type activeShipping struct {
+	Id        int        `json:"id"`
+	Modem     int        `json:"modem"`
+	TimeStart time.Time  `json:"time_start" db:"time_start"`
+	Corridor  int        `json:"corridor"`
+	CheckedAt *time.Time `json:"checked_at" db:"checked_at"`
+}
+
+type coordinate struct {
+	DevTime            time.Time `json:"dev_time" db:"dev_time"`
+	MinDistanceToRoute int       `json:"min_distance_to_route" db:"min_distance_to_route"`
+}
@@ -0,0 +1,32 @@
+package deviation
+
+import (
+	"time"
+)
+
+type Db struct {
+	Id          int        `json:"id"`
+	Shipping    int        `json:"shipping"`
+	TimeStart   time.Time  `json:"time_start" db:"time_start"`
+	TimeEnd     *time.Time `json:"time_end" db:"time_end"`
+	MaxDistance int        `json:"max_distance" db:"max_distance"`
+}
+
+// CHECK_BATCH_SIZE количество координат перевозки, обрабатываемых за одну проверку
+const CHECK_BATCH_SIZE = 5000
+
+type Repo interface {
+	Create(data Db) (Db, error)
+	Update(data Db) error
+	GetOpenByShipping(shippingId int) (Db, error)
+	ListByShipping(shippingId int) ([]Deviation, error)
+	ListActiveShipping() ([]activeShipping, error)
+	Coordinates(modemId int, from time.Time, limit int) ([]coordinate, error)
+	SetCheckedAt(shippingId int, checkedAt time.Time) error
+	CloseEnded() (int64, error)
+}
+
+type Usecase interface {
+	ListByShipping(shippingId int) ([]Deviation, error)
+	Check() error
+}
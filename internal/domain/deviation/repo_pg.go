@@ -0,0 +1,157 @@
+package deviation
+
+import (
+	"context"
+	"errors"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"seal/pkg/app_error"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+func (r *repo) Create(d Db) (Db, error) {
+	q := `INSERT INTO shipping_deviations
+		(shipping, time_start, time_end, max_distance)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	qp := []any{d.Shipping, d.TimeStart, d.TimeEnd, d.MaxDistance}
+
+	err := r.db.QueryRow(r.ctx, q, qp...).Scan(&d.Id)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(d.Id).SetError(err).GetMsg())
+
+	return d, err
+}
+
+func (r *repo) Update(d Db) error {
+	q := `UPDATE shipping_deviations
+		set (time_end, max_distance) = ($2, $3)
+		where id = $1
+	`
+
+	qp := []any{d.Id, d.TimeEnd, d.MaxDistance}
+
+	commandTag, err := r.db.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(commandTag.RowsAffected()).SetError(err).GetMsg())
+
+	return err
+}
+
+func (r *repo) GetOpenByShipping(shippingId int) (Db, error) {
+	q := query.New[Db](r.ctx, r.db).
+		Select("d.*", "").
+		From("shipping_deviations", "d").
+		Where(query.EQUEL, "d.shipping", shippingId).
+		AndWhere(query.IS_NULL, "d.time_end", nil).
+		OrderBy("d.time_start DESC")
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) ListByShipping(shippingId int) ([]Deviation, error) {
+	q := query.New[Deviation](r.ctx, r.db).
+		Select("d.id", "").
+		AddSelect("d.time_start", "").
+		AddSelect("d.time_end", "").
+		AddSelect("d.max_distance", "").
+		AddSelect("extract(epoch from coalesce(d.time_end, now()) - d.time_start)::int", "duration").
+		From("shipping_deviations", "d").
+		Where(query.EQUEL, "d.shipping", shippingId).
+		OrderBy("d.time_start")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) ListActiveShipping() ([]activeShipping, error) {
+	q := query.New[activeShipping](r.ctx, r.db).
+		Select("s.id", "").
+		AddSelect("s.modem", "").
+		AddSelect("s.time_start", "").
+		AddSelect("r.corridor", "").
+		AddSelect("c.checked_at", "").
+		From("shipping", "s").
+		InnerJoin("r", "routes", "r.id = s.route").
+		LeftJoin("c", "shipping_deviation_checks", "c.shipping = s.id").
+		Where(query.EQUEL, "s.status", shipping.STATUS_ACTIVE).
+		AndWhere(query.IS_NOT_NULL, "s.modem", nil).
+		AndWhere(query.IS_NOT_NULL, "s.time_start", nil).
+		OrderBy("s.id")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) Coordinates(modemId int, from time.Time, limit int) ([]coordinate, error) {
+	q := query.New[coordinate](r.ctx, r.db).
+		Select("c.dev_time", "").
+		AddSelect("c.min_distance_to_route", "").
+		From("coordinates", "c").
+		Where(query.EQUEL, "c.modem", modemId).
+		AndWhere(query.GREAT, "c.dev_time", from).
+		AndWhere(query.LITTLE_OR_EQ, "c.dev_time", time.Now()).
+		AndWhere(query.IS_NOT_NULL, "c.min_distance_to_route", nil).
+		OrderBy("c.dev_time").
+		Limit(limit)
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) SetCheckedAt(shippingId int, checkedAt time.Time) error {
+	q := `INSERT INTO shipping_deviation_checks (shipping, checked_at)
+		VALUES ($1, $2)
+		ON CONFLICT (shipping) DO UPDATE SET checked_at = EXCLUDED.checked_at
+	`
+
+	qp := []any{shippingId, checkedAt}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
+
+// CloseEnded закрывает отклонения перевозок, которые уже не активны
+func (r *repo) CloseEnded() (int64, error) {
+	q := `UPDATE shipping_deviations d
+		set time_end = coalesce(s.time_end, now())
+		from shipping s
+		where s.id = d.shipping and d.time_end is null and s.status <> $1
+	`
+
+	commandTag, err := r.db.Exec(r.ctx, q, shipping.STATUS_ACTIVE)
+	r.logger.DebugOrError(err, query.NewLogSql(q, shipping.STATUS_ACTIVE).SetResult(commandTag.RowsAffected()).SetError(err).GetMsg())
+
+	return commandTag.RowsAffected(), err
+}
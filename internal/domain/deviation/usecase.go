@@ -0,0 +1,111 @@
+package deviation
+
+import (
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping"
+	"seal/pkg/app_error"
+)
+
+type CoreUseCase struct {
+	Shipping shipping.Usecase
+}
+
+type usecase struct {
+	repo      Repo
+	logger    app_interface.Logger
+	validator app_interface.Validator
+	usecase   CoreUseCase
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, coreUsecase CoreUseCase) Usecase {
+	return &usecase{repo, logger, validator, coreUsecase}
+}
+
+func (s *usecase) ListByShipping(shippingId int) ([]Deviation, error) {
+	if exists, err := s.usecase.Shipping.Exists(shippingId); err != nil {
+		return []Deviation{}, err
+	} else if !exists {
+		return []Deviation{}, app_error.ErrNotFound
+	}
+
+	return s.repo.ListByShipping(shippingId)
+}
+
+// Check сравнивает удаление от маршрута новых координат активных перевозок с коридором маршрута,
+// открывает отклонение при выходе из коридора и закрывает его при возврате
+func (s *usecase) Check() error {
+	if _, err := s.repo.CloseEnded(); err != nil {
+		return err
+	}
+
+	list, err := s.repo.ListActiveShipping()
+	if err != nil {
+		return err
+	}
+
+	for _, sh := range list {
+		if err := s.checkShipping(sh); err != nil {
+			s.logger.Error(fmt.Sprintf("Ошибка проверки отклонений перевозки %d: %v", sh.Id, err))
+		}
+	}
+
+	return nil
+}
+
+func (s *usecase) checkShipping(sh activeShipping) error {
+	from := sh.TimeStart
+	if sh.CheckedAt != nil && sh.CheckedAt.After(from) {
+		from = *sh.CheckedAt
+	}
+
+	coords, err := s.repo.Coordinates(sh.Modem, from, CHECK_BATCH_SIZE)
+	if err != nil || len(coords) == 0 {
+		return err
+	}
+
+	var current *Db
+	if open, err := s.repo.GetOpenByShipping(sh.Id); err == nil {
+		current = &open
+	} else if !errors.Is(err, app_error.ErrNotFound) {
+		return err
+	}
+
+	changed := false
+
+	for _, c := range coords {
+		if c.MinDistanceToRoute > sh.Corridor {
+			if current == nil {
+				created, err := s.repo.Create(Db{Shipping: sh.Id, TimeStart: c.DevTime, MaxDistance: c.MinDistanceToRoute})
+				if err != nil {
+					return err
+				}
+				current = &created
+			} else if c.MinDistanceToRoute > current.MaxDistance {
+				current.MaxDistance = c.MinDistanceToRoute
+				changed = true
+			}
+
+			continue
+		}
+
+		if current != nil {
+			timeEnd := c.DevTime
+			current.TimeEnd = &timeEnd
+			if err := s.repo.Update(*current); err != nil {
+				return err
+			}
+			current = nil
+			changed = false
+		}
+	}
+
+	if current != nil && changed {
+		if err := s.repo.Update(*current); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.SetCheckedAt(sh.Id, coords[len(coords)-1].DevTime)
+}
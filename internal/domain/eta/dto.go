@@ -0,0 +1,36 @@
+package eta
+
+import (
+	"time"
+)
+
+type Db struct {
+	Shipping          int       `json:"shipping"`
+	DevTime           time.Time `json:"dev_time" db:"dev_time"`
+	RemainingDistance int       `json:"remaining_distance" db:"remaining_distance"`
+	AverageSpeed      int       `json:"average_speed" db:"average_speed"`
+	ArrivalTime       time.Time `json:"arrival_time" db:"arrival_time"`
+}
+
+// SPEED_WINDOW период перед последней координатой, по которому считается средняя скорость.
+// Доля времени стоянок за этот период закладывается в прогноз на оставшийся путь
+const SPEED_WINDOW = 2 * time.Hour
+
+// MIN_SPEED_SAMPLES минимальное количество координат в окне для расчёта по фактической скорости
+const MIN_SPEED_SAMPLES = 5
+
+// MIN_SPEED скорость (км/ч), ниже которой транспорт считается стоящим.
+// Если средняя скорость с учётом стоянок ниже, прогноз строится по плановой скорости маршрута
+const MIN_SPEED = 5
+
+type Repo interface {
+	ListActiveShipping() ([]activeShipping, error)
+	LastPosition(modemId int, from time.Time) (position, error)
+	Speed(modemId int, from, to time.Time) (speed, error)
+	RemainingDistance(routeId int, pos position) (float64, error)
+	Save(data Db) error
+}
+
+type Usecase interface {
+	Check() error
+}
@@ -0,0 +1,28 @@
+package eta
+
+import "time"
+
+type activeShipping struct {
+	Id         int        `json:"id"`
+	Modem      int        `json:"modem"`
+	Route      int        `json:"route"`
+	TimeStart  time.Time  `json:"time_start" db:"time_start"`
+	Length     int        `json:"length"`                         // routes.length, в километрах
+	PathLength float64    `json:"path_length" db:"path_length"`   // длина по route_points, в метрах
+	TravelTime int        `json:"travel_time" db:"travel_time"`   // в минутах
+	EtaDevTime *time.Time `json:"eta_dev_time" db:"eta_dev_time"` // координата последнего расчёта
+}
+
+type position struct {
+	DevTime   time.Time `json:"dev_time" db:"dev_time"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+}
+
+// speed скорость за окно с разделением времени на движение и стоянки
+type speed struct {
+	Moving     float64 `json:"moving"`                       // средняя скорость в движении (км/ч), взвешенная по времени
+	MovingTime float64 `json:"moving_time" db:"moving_time"` // в секундах
+	StopTime   float64 `json:"stop_time" db:"stop_time"`     // в секундах
+	Count      int     `json:"count"`
+}
@@ -0,0 +1,145 @@
+package eta
+
+import (
+	"context"
+	"errors"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"seal/pkg/app_error"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+func (r *repo) ListActiveShipping() ([]activeShipping, error) {
+	q := query.New[activeShipping](r.ctx, r.db).
+		Select("s.id", "").
+		AddSelect("s.modem", "").
+		AddSelect("s.route", "").
+		AddSelect("s.time_start", "").
+		AddSelect("r.length", "").
+		AddSelect("r.travel_time", "").
+		AddSelect(`coalesce((select sum(d.distance) from (
+			select (point(longitude, latitude) <@> lag(point(longitude, latitude)) over (order by number)) * 1609.34 distance
+			from route_points where route = r.id) d), 0)`, "path_length").
+		AddSelect("e.dev_time", "eta_dev_time").
+		From("shipping", "s").
+		InnerJoin("r", "routes", "r.id = s.route").
+		LeftJoin("e", "shipping_eta", "e.shipping = s.id").
+		Where(query.IN, "s.status", []int{shipping.STATUS_ACTIVE, shipping.STATUS_SUSPENDED}).
+		AndWhere(query.IS_NOT_NULL, "s.modem", nil).
+		AndWhere(query.IS_NOT_NULL, "s.time_start", nil).
+		OrderBy("s.id")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) LastPosition(modemId int, from time.Time) (position, error) {
+	q := `select dev_time, latitude, longitude
+		from coordinates
+		where modem = $1 and dev_time > $2 and dev_time <= now() and latitude != 'NaN' and longitude != 'NaN'
+		order by dev_time desc
+		limit 1
+	`
+
+	qp := []any{modemId, from}
+
+	var data position
+	err := r.db.QueryRow(r.ctx, q, qp...).Scan(&data.DevTime, &data.Latitude, &data.Longitude)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Speed средняя скорость в движении и время движения и стоянок за период.
+// Каждая координата действует до следующей, стоянка – координата со скоростью ниже MIN_SPEED
+func (r *repo) Speed(modemId int, from, to time.Time) (speed, error) {
+	q := `with points as (
+			select speed, extract(epoch from lead(dev_time) over (order by dev_time) - dev_time) duration
+			from coordinates
+			where modem = $1 and dev_time > $2 and dev_time <= $3 and latitude != 'NaN' and longitude != 'NaN'
+		)
+		select coalesce(sum(speed * duration) filter (where speed >= $4) / nullif(sum(duration) filter (where speed >= $4), 0), 0),
+			coalesce(sum(duration) filter (where speed >= $4), 0),
+			coalesce(sum(duration) filter (where speed < $4), 0),
+			count(*)
+		from points
+	`
+
+	qp := []any{modemId, from, to, MIN_SPEED}
+
+	var data speed
+	err := r.db.QueryRow(r.ctx, q, qp...).Scan(&data.Moving, &data.MovingTime, &data.StopTime, &data.Count)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// RemainingDistance расстояние (в метрах) от точки до ближайшей точки маршрута
+// и далее по точкам маршрута до его окончания
+func (r *repo) RemainingDistance(routeId int, pos position) (float64, error) {
+	q := `with nearest as (
+			select number, (point(longitude, latitude) <@> point($2, $3)) * 1609.34 distance
+			from route_points
+			where route = $1
+			order by point(longitude, latitude) <-> point($2, $3)
+			limit 1
+		), segments as (
+			select number, 
+				(point(longitude, latitude) <@> lag(point(longitude, latitude)) over (order by number)) * 1609.34 distance
+			from route_points
+			where route = $1
+		)
+		select coalesce((select distance from nearest), 0) + 
+			coalesce((select sum(s.distance) from segments s where s.number > (select number from nearest)), 0)
+	`
+
+	qp := []any{routeId, pos.Longitude, pos.Latitude}
+
+	var distance float64
+	err := r.db.QueryRow(r.ctx, q, qp...).Scan(&distance)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(distance).SetError(err).GetMsg())
+
+	return distance, err
+}
+
+func (r *repo) Save(data Db) error {
+	q := `INSERT INTO shipping_eta (shipping, calculated_at, dev_time, remaining_distance, average_speed, arrival_time)
+		VALUES ($1, now(), $2, $3, $4, $5)
+		ON CONFLICT (shipping) DO UPDATE SET 
+			calculated_at = EXCLUDED.calculated_at,
+			dev_time = EXCLUDED.dev_time,
+			remaining_distance = EXCLUDED.remaining_distance,
+			average_speed = EXCLUDED.average_speed,
+			arrival_time = EXCLUDED.arrival_time
+	`
+
+	qp := []any{data.Shipping, data.DevTime, data.RemainingDistance, data.AverageSpeed, data.ArrivalTime}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
@@ -0,0 +1,115 @@
+package eta
+
+import (
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/pkg/app_error"
+	"time"
+)
+
+type usecase struct {
+	repo      Repo
+	logger    app_interface.Logger
+	validator app_interface.Validator
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator) Usecase {
+	return &usecase{repo, logger, validator}
+}
+
+// Check пересчитывает прогноз прибытия активных перевозок по положению последней координаты
+// на маршруте, оставшемуся расстоянию и средней скорости за последнее время.
+// Перевозки без новых координат с прошлого расчёта пропускаются
+func (s *usecase) Check() error {
+	list, err := s.repo.ListActiveShipping()
+	if err != nil {
+		return err
+	}
+
+	for _, sh := range list {
+		if err := s.checkShipping(sh); err != nil {
+			s.logger.Error(fmt.Sprintf("Ошибка расчёта времени прибытия перевозки %d: %v", sh.Id, err))
+		}
+	}
+
+	return nil
+}
+
+func (s *usecase) checkShipping(sh activeShipping) error {
+	from := sh.TimeStart
+	if sh.EtaDevTime != nil && sh.EtaDevTime.After(from) {
+		from = *sh.EtaDevTime
+	}
+
+	pos, err := s.repo.LastPosition(sh.Modem, from)
+	if errors.Is(err, app_error.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	remaining, err := s.repo.RemainingDistance(sh.Route, pos)
+	if err != nil {
+		return err
+	}
+
+	actual, err := s.repo.Speed(sh.Modem, pos.DevTime.Add(-SPEED_WINDOW), pos.DevTime)
+	if err != nil {
+		return err
+	}
+
+	kmh := travelSpeed(actual)
+	if actual.Count < MIN_SPEED_SAMPLES || kmh < MIN_SPEED {
+		kmh = plannedSpeed(sh)
+	}
+
+	if kmh <= 0 {
+		return nil
+	}
+
+	return s.repo.Save(Db{
+		Shipping:          sh.Id,
+		DevTime:           pos.DevTime,
+		RemainingDistance: int(remaining),
+		AverageSpeed:      int(kmh),
+		ArrivalTime:       predict(pos.DevTime, remaining, kmh, time.Now()),
+	})
+}
+
+// travelSpeed средняя скорость (км/ч) с учётом стоянок: скорость в движении, умноженная на долю времени движения
+func travelSpeed(sp speed) float64 {
+	if sp.MovingTime+sp.StopTime <= 0 {
+		return 0
+	}
+
+	return sp.Moving * sp.MovingTime / (sp.MovingTime + sp.StopTime)
+}
+
+// plannedSpeed средняя скорость (км/ч) по длине маршрута и плановому времени (в минутах).
+// Длина берётся по точкам маршрута (в метрах), routes.length (в километрах) – если точек нет
+func plannedSpeed(sh activeShipping) float64 {
+	if sh.TravelTime <= 0 {
+		return 0
+	}
+
+	km := sh.PathLength / 1000
+	if km <= 0 {
+		km = float64(sh.Length)
+	}
+
+	return km * 60 / float64(sh.TravelTime)
+}
+
+// predict время прибытия при движении с заданной скоростью от момента последней координаты.
+// Если с последней координаты транспорт не выходил на связь дольше, чем требуется на остаток пути,
+// время стоянки переносит прогноз на текущий момент
+func predict(from time.Time, remaining, kmh float64, now time.Time) time.Time {
+	arrival := from.Add(time.Duration(remaining / (kmh * 1000) * float64(time.Hour)))
+
+	if arrival.Before(now) && remaining > 0 {
+		return now
+	}
+
+	return arrival
+}
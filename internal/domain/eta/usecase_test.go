@@ -0,0 +1,95 @@
+package eta
+
+import (
+	"seal/pkg/app_error"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRepo struct {
+	Repo
+	last  position
+	from  time.Time
+	speed speed
+	saved []Db
+}
+
+func (r *fakeRepo) LastPosition(modemId int, from time.Time) (position, error) {
+	r.from = from
+	if !r.last.DevTime.After(from) {
+		return position{}, app_error.ErrNotFound
+	}
+
+	return r.last, nil
+}
+
+func (r *fakeRepo) Speed(modemId int, from, to time.Time) (speed, error) { return r.speed, nil }
+
+func (r *fakeRepo) RemainingDistance(routeId int, pos position) (float64, error) { return 60000, nil }
+
+func (r *fakeRepo) Save(data Db) error {
+	r.saved = append(r.saved, data)
+	return nil
+}
+
+func TestTravelSpeed(t *testing.T) {
+	tests := []struct {
+		name  string
+		speed speed
+		kmh   float64
+	}{
+		{"нет данных", speed{}, 0},
+		{"без стоянок", speed{Moving: 80, MovingTime: 3600}, 80},
+		{"стоянка четверть времени", speed{Moving: 80, MovingTime: 5400, StopTime: 1800}, 60},
+		{"только стоянка", speed{StopTime: 3600}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.kmh, travelSpeed(tt.speed), 0.001)
+		})
+	}
+}
+
+func TestPlannedSpeed(t *testing.T) {
+	assert.InDelta(t, 60, plannedSpeed(activeShipping{PathLength: 120000, Length: 1, TravelTime: 120}), 0.001)
+	assert.InDelta(t, 50, plannedSpeed(activeShipping{Length: 100, TravelTime: 120}), 0.001)
+	assert.Equal(t, float64(0), plannedSpeed(activeShipping{Length: 100}))
+}
+
+func TestCheckShipping(t *testing.T) {
+	// последняя координата только что получена, прогноз не сдвигается на текущий момент
+	last := time.Now()
+	t0 := last.Add(-time.Hour)
+
+	t.Run("новая координата", func(t *testing.T) {
+		repo := &fakeRepo{last: position{DevTime: last}, speed: speed{Moving: 80, MovingTime: 5400, StopTime: 1800, Count: 10}}
+		s := &usecase{repo: repo}
+
+		assert.NoError(t, s.checkShipping(activeShipping{TimeStart: t0}))
+		assert.Equal(t, t0, repo.from)
+		assert.Len(t, repo.saved, 1)
+		assert.Equal(t, 60, repo.saved[0].AverageSpeed)
+		assert.Equal(t, last.Add(time.Hour), repo.saved[0].ArrivalTime)
+	})
+
+	t.Run("координата уже учтена", func(t *testing.T) {
+		repo := &fakeRepo{last: position{DevTime: last}}
+		s := &usecase{repo: repo}
+
+		assert.NoError(t, s.checkShipping(activeShipping{TimeStart: t0, EtaDevTime: &last}))
+		assert.Equal(t, last, repo.from)
+		assert.Empty(t, repo.saved)
+	})
+
+	t.Run("долгая стоянка – плановая скорость", func(t *testing.T) {
+		repo := &fakeRepo{last: position{DevTime: last}, speed: speed{Moving: 40, MovingTime: 600, StopTime: 6600, Count: 10}}
+		s := &usecase{repo: repo}
+
+		assert.NoError(t, s.checkShipping(activeShipping{TimeStart: t0, PathLength: 120000, TravelTime: 120}))
+		assert.Len(t, repo.saved, 1)
+		assert.Equal(t, 60, repo.saved[0].AverageSpeed)
+	})
+}
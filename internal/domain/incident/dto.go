@@ -0,0 +1,68 @@
+package incident
+
+import (
+	"seal/internal/repository/pg/query"
+	"seal/internal/transport"
+	"time"
+)
+
+type Db struct {
+	Id             int        `json:"id"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	Shipping       int        `json:"shipping"`
+	Seal           int        `json:"seal"`
+	SealSerial     uint64     `json:"seal_serial" db:"seal_serial"`
+	Modem          int        `json:"modem"`
+	DevTime        time.Time  `json:"dev_time" db:"dev_time"`
+	Type           int        `json:"type"`
+	Flag           int64      `json:"flag"`
+	Status         int64      `json:"status"`
+	Errors         int16      `json:"errors"`
+	Latitude       *float32   `json:"latitude"`
+	Longitude      *float32   `json:"longitude"`
+	State          int        `json:"state"`
+	AcknowledgedBy *int       `json:"acknowledged_by" db:"acknowledged_by"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at" db:"acknowledged_at"`
+	ResolvedBy     *int       `json:"resolved_by" db:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at" db:"resolved_at"`
+	Comment        string     `json:"comment" validate:"max=1024"`
+}
+
+const TYPE_CABLE_CUT = 0
+const TYPE_CASE_OPENED = 1
+const TYPE_SENSOR_ERROR = 2
+
+const STATE_NEW = 0
+const STATE_ACKNOWLEDGED = 1
+const STATE_RESOLVED = 2
+
+// CHECK_BATCH_SIZE количество пакетов пломб перевозки, обрабатываемых за одну проверку
+const CHECK_BATCH_SIZE = 5000
+
+type QueryParams struct {
+	transport.QueryParams
+	Shipping []int `form:"shipping"`
+	Seal     []int `form:"seal"`
+	Type     []int `form:"type"`
+	State    []int `form:"state"`
+}
+
+type Repo interface {
+	Create(data Db) error
+	Update(data Db) (Incident, error)
+	GetById(id int) (Incident, error)
+	GetDbById(id int) (Db, error)
+	List(params QueryParams) (query.List[Incident], error)
+	ListActiveShipping() ([]activeShipping, error)
+	SealPackets(shippingId, modemId int, from time.Time, limit int) ([]sealPacket, error)
+	LastSealPacket(sealId, modemId int, before time.Time) (sealPacket, error)
+	SetCheckedAt(shippingId int, checkedAt time.Time) error
+}
+
+type Usecase interface {
+	GetById(id int) (Incident, error)
+	List(params QueryParams) (query.List[Incident], error)
+	Acknowledge(id int, data UpdateStateRequest, userId int) (Incident, error)
+	Resolve(id int, data UpdateStateRequest, userId int) (Incident, error)
+	Check() error
+}
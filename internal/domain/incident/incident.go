@@ -0,0 +1,51 @@
+package incident
+
+import (
+	"seal/internal/domain/user"
+	"time"
+)
+
+type Incident struct {
+	Id        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Shipping  int       `json:"shipping"`
+	Seal      struct {
+		Id     int    `json:"id"`
+		Serial uint64 `json:"serial"`
+	} `json:"seal"`
+	Modem          int          `json:"modem"`
+	DevTime        time.Time    `json:"dev_time" db:"dev_time"`
+	Type           int          `json:"type"`
+	Flag           int64        `json:"flag"`
+	Status         int64        `json:"status"`
+	Errors         int16        `json:"errors"`
+	Latitude       *float32     `json:"latitude"`
+	Longitude      *float32     `json:"longitude"`
+	State          int          `json:"state"`
+	AcknowledgedBy *user.Author `json:"acknowledged_by" db:"acknowledged_by"`
+	AcknowledgedAt *time.Time   `json:"acknowledged_at" db:"acknowledged_at"`
+	ResolvedBy     *user.Author `json:"resolved_by" db:"resolved_by"`
+	ResolvedAt     *time.Time   `json:"resolved_at" db:"resolved_at"`
+	Comment        string       `json:"comment"`
+}
+
+type UpdateStateRequest struct {
+	Comment string `json:"comment,omitempty" validate:"max=1024"`
+}
+
+type activeShipping struct {
+	Id        int        `json:"id"`
+	Modem     int        `json:"modem"`
+	TimeStart time.Time  `json:"time_start" db:"time_start"`
+	CheckedAt *time.Time `json:"checked_at" db:"checked_at"`
+}
+
+type sealPacket struct {
+	DevTime    time.Time `json:"dev_time" db:"dev_time"`
+	Seal       int       `json:"seal"`
+	SealSerial uint64    `json:"seal_serial" db:"seal_serial"`
+	Status     int64     `json:"status"`
+	Errors     int16     `json:"errors"`
+	Latitude   *float32  `json:"latitude"`
+	Longitude  *float32  `json:"longitude"`
+}
@@ -0,0 +1,235 @@
+package incident
+
+import (
+	"context"
+	"errors"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"seal/pkg/app_error"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+func (r *repo) Create(i Db) error {
+	q := `INSERT INTO seal_incidents
+		(shipping, seal, seal_serial, modem, dev_time, type, flag, status, errors, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT DO NOTHING
+	`
+
+	qp := []any{i.Shipping, i.Seal, i.SealSerial, i.Modem, i.DevTime, i.Type, i.Flag, i.Status, i.Errors,
+		i.Latitude, i.Longitude}
+
+	commandTag, err := r.db.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(commandTag.RowsAffected()).SetError(err).GetMsg())
+
+	return err
+}
+
+func (r *repo) Update(i Db) (Incident, error) {
+	q := `UPDATE seal_incidents
+		set (state, acknowledged_by, acknowledged_at, resolved_by, resolved_at, comment) =
+		    ($2, $3, $4, $5, $6, $7)
+		    where id = $1
+		RETURNING id
+	`
+
+	qp := []any{i.Id, i.State, i.AcknowledgedBy, i.AcknowledgedAt, i.ResolvedBy, i.ResolvedAt, i.Comment}
+
+	err := r.db.QueryRow(r.ctx, q, qp...).Scan(&i.Id)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(i.Id).SetError(err).GetMsg())
+
+	if err != nil {
+		return Incident{}, err
+	}
+
+	return r.GetById(i.Id)
+}
+
+func (r *repo) GetById(id int) (Incident, error) {
+	q := query.New[Incident](r.ctx, r.db).
+		Select("i.id", "").
+		AddSelect("i.created_at", "").
+		AddSelect("i.shipping", "").
+		AddSelect("jsonb_build_object('id', i.seal, 'serial', i.seal_serial)", "seal").
+		AddSelect("i.modem", "").
+		AddSelect("i.dev_time", "").
+		AddSelect("i.type", "").
+		AddSelect("i.flag", "").
+		AddSelect("i.status", "").
+		AddSelect("i.errors", "").
+		AddSelect("i.latitude", "").
+		AddSelect("i.longitude", "").
+		AddSelect("i.state", "").
+		AddSelect("case when ua.id is null then null else jsonb_build_object('id', ua.id, 'login', ua.login) end", "acknowledged_by").
+		AddSelect("i.acknowledged_at", "").
+		AddSelect("case when ur.id is null then null else jsonb_build_object('id', ur.id, 'login', ur.login) end", "resolved_by").
+		AddSelect("i.resolved_at", "").
+		AddSelect("i.comment", "").
+		From("seal_incidents", "i").
+		LeftJoin("ua", "users", "ua.id = i.acknowledged_by").
+		LeftJoin("ur", "users", "ur.id = i.resolved_by").
+		Where(query.EQUEL, "i.id", id)
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) GetDbById(id int) (Db, error) {
+	q := query.New[Db](r.ctx, r.db).
+		Select("*", "").
+		From("seal_incidents", "i").
+		Where(query.EQUEL, "i.id", id)
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) List(params QueryParams) (query.List[Incident], error) {
+	q := query.New[Incident](r.ctx, r.db).
+		Select("i.id", "").
+		AddSelect("i.created_at", "").
+		AddSelect("i.shipping", "").
+		AddSelect("jsonb_build_object('id', i.seal, 'serial', i.seal_serial)", "seal").
+		AddSelect("i.modem", "").
+		AddSelect("i.dev_time", "").
+		AddSelect("i.type", "").
+		AddSelect("i.flag", "").
+		AddSelect("i.status", "").
+		AddSelect("i.errors", "").
+		AddSelect("i.latitude", "").
+		AddSelect("i.longitude", "").
+		AddSelect("i.state", "").
+		AddSelect("case when ua.id is null then null else jsonb_build_object('id', ua.id, 'login', ua.login) end", "acknowledged_by").
+		AddSelect("i.acknowledged_at", "").
+		AddSelect("case when ur.id is null then null else jsonb_build_object('id', ur.id, 'login', ur.login) end", "resolved_by").
+		AddSelect("i.resolved_at", "").
+		AddSelect("i.comment", "").
+		From("seal_incidents", "i").
+		LeftJoin("ua", "users", "ua.id = i.acknowledged_by").
+		LeftJoin("ur", "users", "ur.id = i.resolved_by").
+		FilterWhere(params.FindType, "i.seal_serial", params.Find).
+		AndFilterWhere(query.IN, "i.shipping", params.Shipping).
+		AndFilterWhere(query.IN, "i.seal", params.Seal).
+		AndFilterWhere(query.IN, "i.type", params.Type).
+		AndFilterWhere(query.IN, "i.state", params.State).
+		OrderBy("i.state, i.dev_time DESC").
+		Limit(params.Limit).
+		Offset(params.Offset)
+
+	data, err := q.GetList()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) ListActiveShipping() ([]activeShipping, error) {
+	q := query.New[activeShipping](r.ctx, r.db).
+		Select("s.id", "").
+		AddSelect("s.modem", "").
+		AddSelect("s.time_start", "").
+		AddSelect("c.checked_at", "").
+		From("shipping", "s").
+		LeftJoin("c", "shipping_incident_checks", "c.shipping = s.id").
+		Where(query.EQUEL, "s.status", shipping.STATUS_ACTIVE).
+		AndWhere(query.IS_NOT_NULL, "s.modem", nil).
+		AndWhere(query.IS_NOT_NULL, "s.time_start", nil).
+		OrderBy("s.id")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// SealPackets пакеты пломб, привязанных к перевозке, которые получены модемом перевозки
+func (r *repo) SealPackets(shippingId, modemId int, from time.Time, limit int) ([]sealPacket, error) {
+	q := query.New[sealPacket](r.ctx, r.db).
+		Select("sd.dev_time", "").
+		AddSelect("sd.seal", "").
+		AddSelect("s.serial", "seal_serial").
+		AddSelect("sd.status", "").
+		AddSelect("sd.errors", "").
+		AddSelect("nullif(md.latitude, 'NaN')", "latitude").
+		AddSelect("nullif(md.longitude, 'NaN')", "longitude").
+		From("seals_data", "sd").
+		InnerJoin("s", "seals", "s.id = sd.seal").
+		InnerJoin("ss", "shipping_seals", "ss.seal = sd.seal").
+		LeftJoin("md", "modems_data", "md.dev_time = sd.modem_time and md.modem = sd.modem").
+		Where(query.EQUEL, "sd.modem", modemId).
+		AndWhere(query.EQUEL, "ss.shipping", shippingId).
+		AndWhere(query.GREAT, "sd.dev_time", from).
+		AndWhere(query.LITTLE_OR_EQ, "sd.dev_time", time.Now()).
+		OrderBy("sd.dev_time, sd.seal").
+		Limit(limit)
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) LastSealPacket(sealId, modemId int, before time.Time) (sealPacket, error) {
+	q := query.New[sealPacket](r.ctx, r.db).
+		Select("sd.dev_time", "").
+		AddSelect("sd.seal", "").
+		AddSelect("sd.status", "").
+		AddSelect("sd.errors", "").
+		From("seals_data", "sd").
+		Where(query.EQUEL, "sd.seal", sealId).
+		AndWhere(query.EQUEL, "sd.modem", modemId).
+		AndWhere(query.LITTLE, "sd.dev_time", before).
+		OrderBy("sd.dev_time DESC")
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) SetCheckedAt(shippingId int, checkedAt time.Time) error {
+	q := `INSERT INTO shipping_incident_checks (shipping, checked_at)
+		VALUES ($1, $2)
+		ON CONFLICT (shipping) DO UPDATE SET checked_at = EXCLUDED.checked_at
+	`
+
+	qp := []any{shippingId, checkedAt}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
@@ -0,0 +1,186 @@
+package incident
+
+import (
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/seal"
+	"seal/internal/repository/pg/query"
+	"seal/pkg/app_error"
+	"time"
+)
+
+type usecase struct {
+	repo      Repo
+	logger    app_interface.Logger
+	validator app_interface.Validator
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator) Usecase {
+	return &usecase{repo, logger, validator}
+}
+
+func (s *usecase) GetById(id int) (Incident, error) {
+	return s.repo.GetById(id)
+}
+
+func (s *usecase) List(queryParams QueryParams) (query.List[Incident], error) {
+	if errs := s.validator.Struct(queryParams); errs != nil {
+		return query.List[Incident]{}, app_error.ValidationError(errs)
+	}
+
+	return s.repo.List(queryParams)
+}
+
+func (s *usecase) Acknowledge(id int, data UpdateStateRequest, userId int) (Incident, error) {
+	incident, err := s.repo.GetDbById(id)
+
+	if err != nil {
+		return Incident{}, err
+	}
+
+	if incident.State != STATE_NEW {
+		return Incident{}, app_error.ValidationError(`Принять можно только инцидент со статусом "Новый"`)
+	}
+
+	now := time.Now()
+	incident.State = STATE_ACKNOWLEDGED
+	incident.AcknowledgedBy = &userId
+	incident.AcknowledgedAt = &now
+
+	if data.Comment != "" {
+		incident.Comment = data.Comment
+	}
+
+	return s.repo.Update(incident)
+}
+
+func (s *usecase) Resolve(id int, data UpdateStateRequest, userId int) (Incident, error) {
+	incident, err := s.repo.GetDbById(id)
+
+	if err != nil {
+		return Incident{}, err
+	}
+
+	if incident.State == STATE_RESOLVED {
+		return Incident{}, app_error.ValidationError(`Инцидент уже закрыт`)
+	}
+
+	now := time.Now()
+	incident.State = STATE_RESOLVED
+	incident.ResolvedBy = &userId
+	incident.ResolvedAt = &now
+
+	if data.Comment != "" {
+		incident.Comment = data.Comment
+	}
+
+	return s.repo.Update(incident)
+}
+
+// Check ищет у пломб активных перевозок новые установившиеся биты вскрытия и ошибок датчиков
+// и сохраняет каждый такой переход как инцидент
+func (s *usecase) Check() error {
+	list, err := s.repo.ListActiveShipping()
+	if err != nil {
+		return err
+	}
+
+	for _, sh := range list {
+		if err := s.checkShipping(sh); err != nil {
+			s.logger.Error(fmt.Sprintf("Ошибка проверки инцидентов перевозки %d: %v", sh.Id, err))
+		}
+	}
+
+	return nil
+}
+
+func (s *usecase) checkShipping(sh activeShipping) error {
+	from := sh.TimeStart
+	if sh.CheckedAt != nil && sh.CheckedAt.After(from) {
+		from = *sh.CheckedAt
+	}
+
+	packets, err := s.repo.SealPackets(sh.Id, sh.Modem, from, CHECK_BATCH_SIZE)
+	if err != nil || len(packets) == 0 {
+		return err
+	}
+
+	prev := map[int]sealPacket{}
+
+	for _, packet := range packets {
+		last, ok := prev[packet.Seal]
+		if !ok {
+			last, err = s.repo.LastSealPacket(packet.Seal, sh.Modem, packet.DevTime)
+
+			if errors.Is(err, app_error.ErrNotFound) {
+				// первый пакет пломбы – точка отсчёта, сравнивать его не с чем
+				prev[packet.Seal] = packet
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+
+		for _, incident := range transitions(sh, last, packet) {
+			if err := s.repo.Create(incident); err != nil {
+				return err
+			}
+		}
+
+		prev[packet.Seal] = packet
+	}
+
+	return s.repo.SetCheckedAt(sh.Id, packets[len(packets)-1].DevTime)
+}
+
+// statusTypes биты статуса пломбы, установка которых - инцидент, и тип инцидента
+var statusTypes = []struct {
+	bit          int64
+	incidentType int
+}{
+	{seal.STATUS_CABLE_CUT, TYPE_CABLE_CUT},
+	{seal.STATUS_CASE_OPENED, TYPE_CASE_OPENED},
+}
+
+// sensorErrors биты ошибок пломбы, установка которых - инцидент TYPE_SENSOR_ERROR
+const sensorErrors = seal.ERROR_CABLE_SENSOR | seal.ERROR_CASE_SENSOR
+
+// transitions возвращает инциденты по известным битам вскрытия и ошибок датчиков, установившимся между двумя пакетами пломбы.
+// Прочие биты (заряд, связь и т.п.) инцидентов не создают
+func transitions(sh activeShipping, prev, packet sealPacket) []Db {
+	var res []Db
+
+	newIncident := func(incidentType int, flag int64) Db {
+		return Db{
+			Shipping:   sh.Id,
+			Seal:       packet.Seal,
+			SealSerial: packet.SealSerial,
+			Modem:      sh.Modem,
+			DevTime:    packet.DevTime,
+			Type:       incidentType,
+			Flag:       flag,
+			Status:     packet.Status,
+			Errors:     packet.Errors,
+			Latitude:   packet.Latitude,
+			Longitude:  packet.Longitude,
+		}
+	}
+
+	raisedStatus := packet.Status &^ prev.Status
+	for _, st := range statusTypes {
+		if raisedStatus&st.bit != 0 {
+			res = append(res, newIncident(st.incidentType, st.bit))
+		}
+	}
+
+	raisedErrors := packet.Errors &^ prev.Errors & sensorErrors
+	for bit := int16(1); raisedErrors != 0; bit <<= 1 {
+		if raisedErrors&bit != 0 {
+			raisedErrors &^= bit
+			res = append(res, newIncident(TYPE_SENSOR_ERROR, int64(bit)))
+		}
+	}
+
+	return res
+}
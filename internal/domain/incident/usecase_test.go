@@ -0,0 +1,119 @@
+package incident
+
+import (
+	"seal/internal/domain/seal"
+	"seal/pkg/app_error"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRepo struct {
+	Repo
+	packets   []sealPacket
+	last      map[int]sealPacket
+	created   []Db
+	checkedAt *time.Time
+}
+
+func (r *fakeRepo) SealPackets(shippingId, modemId int, from time.Time, limit int) ([]sealPacket, error) {
+	return r.packets, nil
+}
+
+func (r *fakeRepo) LastSealPacket(sealId, modemId int, before time.Time) (sealPacket, error) {
+	if packet, ok := r.last[sealId]; ok {
+		return packet, nil
+	}
+
+	return sealPacket{}, app_error.ErrNotFound
+}
+
+func (r *fakeRepo) Create(data Db) error {
+	r.created = append(r.created, data)
+	return nil
+}
+
+func (r *fakeRepo) SetCheckedAt(shippingId int, checkedAt time.Time) error {
+	r.checkedAt = &checkedAt
+	return nil
+}
+
+func TestTransitions(t *testing.T) {
+	sh := activeShipping{Id: 1, Modem: 2}
+	packet := func(status int64, errors int16) sealPacket {
+		return sealPacket{Seal: 3, Status: status, Errors: errors}
+	}
+
+	tests := []struct {
+		name  string
+		prev  sealPacket
+		next  sealPacket
+		types []int
+		flags []int64
+	}{
+		{"без изменений", packet(seal.STATUS_CABLE_CUT, 1), packet(seal.STATUS_CABLE_CUT, 1), nil, nil},
+		{"перерезан трос", packet(0, 0), packet(seal.STATUS_CABLE_CUT, 0), []int{TYPE_CABLE_CUT}, []int64{seal.STATUS_CABLE_CUT}},
+		{"вскрыт корпус и прочий бит", packet(0, 0), packet(seal.STATUS_CASE_OPENED|1<<5, 0),
+			[]int{TYPE_CASE_OPENED}, []int64{seal.STATUS_CASE_OPENED}},
+		{"трос и корпус сразу", packet(0, 0), packet(seal.STATUS_CASE_OPENED|seal.STATUS_CABLE_CUT, 0),
+			[]int{TYPE_CABLE_CUT, TYPE_CASE_OPENED}, []int64{seal.STATUS_CABLE_CUT, seal.STATUS_CASE_OPENED}},
+		{"только прочие биты статуса", packet(0, 0), packet(1<<2|1<<5|1<<31, 0), nil, nil},
+		{"бит снят", packet(seal.STATUS_CABLE_CUT, 0), packet(0, 0), nil, nil},
+		{"ошибки датчиков", packet(0, seal.ERROR_CABLE_SENSOR), packet(0, seal.ERROR_CABLE_SENSOR|seal.ERROR_CASE_SENSOR),
+			[]int{TYPE_SENSOR_ERROR}, []int64{seal.ERROR_CASE_SENSOR}},
+		{"неизвестные биты ошибок", packet(0, 0), packet(0, 1<<4|1<<14), nil, nil},
+		{"отрицательное значение ошибок", packet(0, 0), packet(0, -1), []int{TYPE_SENSOR_ERROR, TYPE_SENSOR_ERROR},
+			[]int64{seal.ERROR_CABLE_SENSOR, seal.ERROR_CASE_SENSOR}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var types []int
+			var flags []int64
+
+			for _, incident := range transitions(sh, tt.prev, tt.next) {
+				types = append(types, incident.Type)
+				flags = append(flags, incident.Flag)
+				assert.Equal(t, sh.Id, incident.Shipping)
+				assert.Equal(t, tt.next.Seal, incident.Seal)
+			}
+
+			assert.Equal(t, tt.types, types)
+			assert.Equal(t, tt.flags, flags)
+		})
+	}
+}
+
+func TestCheckShipping(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	packet := func(sealId int, minutes int, status int64) sealPacket {
+		return sealPacket{Seal: sealId, DevTime: t0.Add(time.Duration(minutes) * time.Minute), Status: status}
+	}
+
+	t.Run("первый пакет пломбы без предыдущего не даёт инцидентов", func(t *testing.T) {
+		repo := &fakeRepo{packets: []sealPacket{
+			packet(1, 1, seal.STATUS_CABLE_CUT),
+			packet(1, 2, seal.STATUS_CABLE_CUT|seal.STATUS_CASE_OPENED),
+		}}
+		s := &usecase{repo: repo}
+
+		assert.NoError(t, s.checkShipping(activeShipping{Id: 1, TimeStart: t0}))
+		assert.Len(t, repo.created, 1)
+		assert.Equal(t, TYPE_CASE_OPENED, repo.created[0].Type)
+		assert.Equal(t, t0.Add(2*time.Minute), *repo.checkedAt)
+	})
+
+	t.Run("сравнение с пакетом до начала проверки", func(t *testing.T) {
+		repo := &fakeRepo{
+			packets: []sealPacket{packet(1, 1, seal.STATUS_CABLE_CUT), packet(2, 1, 0)},
+			last:    map[int]sealPacket{1: packet(1, 0, 0)},
+		}
+		s := &usecase{repo: repo}
+
+		assert.NoError(t, s.checkShipping(activeShipping{Id: 1, TimeStart: t0}))
+		assert.Len(t, repo.created, 1)
+		assert.Equal(t, TYPE_CABLE_CUT, repo.created[0].Type)
+		assert.Equal(t, 1, repo.created[0].Seal)
+	})
+}
@@ -0,0 +1,175 @@
+package modem
+
+import (
+	"fmt"
+	"math"
+	"seal/internal/transport"
+	"seal/pkg/app_error"
+	"time"
+)
+
+const CONNECTIVITY_ONLINE = "online"
+const CONNECTIVITY_LATE = "late"
+const CONNECTIVITY_OFFLINE = "offline"
+const CONNECTIVITY_UNKNOWN = "unknown" // модем не сообщил период связи
+
+// MAX_AVAILABILITY_SESSIONS ограничение сеансов связи за окно расчёта доступности
+const MAX_AVAILABILITY_SESSIONS = 200000
+
+// Connectivity параметры расчёта состояния связи по last_dev_time и connect_period модема
+type Connectivity struct {
+	PeriodUnit    int     // секунд в единице connect_period
+	Grace         float64 // online, пока с последнего сеанса прошло не больше connect_period * Grace
+	OfflineFactor float64 // offline, если прошло больше connect_period * OfflineFactor, между ними - late
+}
+
+type ListQueryParams struct {
+	transport.QueryParams
+	Connectivity []string `form:"connectivity" validate:"dive,oneof=online late offline unknown"`
+}
+
+type AvailabilityQueryParams struct {
+	Id   int
+	From time.Time `form:"from" validate:"required"`
+	To   time.Time `form:"to"`
+}
+
+// Availability доля ожидаемых по connect_period сеансов связи, которые пришли за окно
+type Availability struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Expected int       `json:"expected"`
+	Received int       `json:"received"`
+	Percent  *float64  `json:"percent"` // nil, если сеансов не ожидалось
+	Gaps     []Gap     `json:"gaps"`
+}
+
+// Gap пропуск связи, Missed - сколько сеансов не пришло
+type Gap struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Missed int       `json:"missed"`
+}
+
+type session struct {
+	DevTime       time.Time `db:"dev_time"`
+	ConnectPeriod int       `db:"connect_period"`
+}
+
+func (c Connectivity) period(connectPeriod int) time.Duration {
+	return time.Duration(connectPeriod*c.PeriodUnit) * time.Second
+}
+
+// State состояние связи на момент now
+func (c Connectivity) State(lastDevTime *time.Time, connectPeriod int, now time.Time) string {
+	if lastDevTime == nil {
+		return CONNECTIVITY_OFFLINE
+	}
+
+	if connectPeriod <= 0 {
+		return CONNECTIVITY_UNKNOWN
+	}
+
+	since := now.Sub(*lastDevTime).Seconds()
+	period := c.period(connectPeriod).Seconds()
+
+	if since <= period*c.Grace {
+		return CONNECTIVITY_ONLINE
+	} else if since <= period*c.OfflineFactor {
+		return CONNECTIVITY_LATE
+	}
+
+	return CONNECTIVITY_OFFLINE
+}
+
+// stateSql то же, что State, выражением SQL для выборки и фильтра списка
+func (c Connectivity) stateSql(lastDevTime, connectPeriod string) string {
+	return fmt.Sprintf("(case when %[1]s is null then '%[3]s' "+
+		"when coalesce(%[2]s, 0) <= 0 then '%[4]s' "+
+		"when now() - %[1]s <= make_interval(secs => %[2]s * %[7]f) then '%[5]s' "+
+		"when now() - %[1]s <= make_interval(secs => %[2]s * %[8]f) then '%[6]s' "+
+		"else '%[3]s' end)",
+		lastDevTime, connectPeriod,
+		CONNECTIVITY_OFFLINE, CONNECTIVITY_UNKNOWN, CONNECTIVITY_ONLINE, CONNECTIVITY_LATE,
+		float64(c.PeriodUnit)*c.Grace, float64(c.PeriodUnit)*c.OfflineFactor)
+}
+
+func (s *usecase) Availability(params AvailabilityQueryParams) (Availability, error) {
+	if errs := s.validator.Struct(params); errs != nil {
+		return Availability{}, app_error.ValidationError(errs)
+	}
+
+	now := time.Now()
+	if params.To.IsZero() || params.To.After(now) {
+		params.To = now
+	}
+
+	if !params.To.After(params.From) {
+		return Availability{}, app_error.ValidationError(map[string]string{"from": "Должно быть раньше to"})
+	}
+
+	if _, err := s.repo.GetDbById(params.Id); err != nil {
+		return Availability{}, err
+	}
+
+	sessions, err := s.repo.Sessions(params.Id, params.From, params.To)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	return s.connectivity.availability(sessions, params.From, params.To), nil
+}
+
+// availability считает ожидаемые сеансы по периоду связи, действовавшему перед каждым интервалом.
+// Сеанс пришёл раньше периода - ожидался один сеанс, через n периодов - n, из них n-1 пропущены.
+// Интервалы с неизвестным периодом не учитываются
+func (c Connectivity) availability(sessions []session, from, to time.Time) Availability {
+	res := Availability{From: from, To: to, Received: len(sessions), Gaps: []Gap{}}
+
+	prev := from
+	for i, current := range sessions {
+		connectPeriod := current.ConnectPeriod
+		if i > 0 {
+			connectPeriod = sessions[i-1].ConnectPeriod
+		}
+
+		period := c.period(connectPeriod).Seconds()
+		if period <= 0 {
+			res.Expected++
+			prev = current.DevTime
+			continue
+		}
+
+		gap := current.DevTime.Sub(prev).Seconds()
+		expected := max(1, int(math.Round(gap/period)))
+		res.Expected += expected
+
+		if expected > 1 && gap > period*c.Grace {
+			res.Gaps = append(res.Gaps, Gap{prev, current.DevTime, expected - 1})
+		}
+
+		prev = current.DevTime
+	}
+
+	// после последнего сеанса пропущенными считаются только просроченные сеансы
+	connectPeriod := 0
+	if len(sessions) > 0 {
+		connectPeriod = sessions[len(sessions)-1].ConnectPeriod
+	}
+
+	if period := c.period(connectPeriod).Seconds(); period > 0 {
+		gap := to.Sub(prev).Seconds()
+		if gap > period*c.Grace {
+			missed := int(gap / period)
+			res.Expected += missed
+			res.Gaps = append(res.Gaps, Gap{prev, to, missed})
+		}
+	}
+
+	if res.Expected > 0 {
+		percent := math.Round(float64(res.Received)/float64(res.Expected)*10000) / 100
+		res.Percent = &percent
+	}
+
+	return res
+}
@@ -0,0 +1,88 @@
+package modem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConnectivity = Connectivity{PeriodUnit: 60, Grace: 1.5, OfflineFactor: 3}
+
+func TestState(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(minutes int) *time.Time {
+		v := now.Add(-time.Duration(minutes) * time.Minute)
+		return &v
+	}
+
+	tests := []struct {
+		name          string
+		lastDevTime   *time.Time
+		connectPeriod int
+		want          string
+	}{
+		{"не выходил на связь", nil, 10, CONNECTIVITY_OFFLINE},
+		{"период неизвестен", ago(1), 0, CONNECTIVITY_UNKNOWN},
+		{"отрицательный период", ago(1), -5, CONNECTIVITY_UNKNOWN},
+		{"в пределах периода", ago(5), 10, CONNECTIVITY_ONLINE},
+		{"граница grace", ago(15), 10, CONNECTIVITY_ONLINE},
+		{"опаздывает", ago(16), 10, CONNECTIVITY_LATE},
+		{"граница offline", ago(30), 10, CONNECTIVITY_LATE},
+		{"не на связи", ago(31), 10, CONNECTIVITY_OFFLINE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, testConnectivity.State(tt.lastDevTime, tt.connectPeriod, now))
+		})
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return from.Add(time.Duration(minutes) * time.Minute)
+	}
+	sessions := func(connectPeriod int, minutes ...int) []session {
+		res := make([]session, 0, len(minutes))
+		for _, m := range minutes {
+			res = append(res, session{DevTime: at(m), ConnectPeriod: connectPeriod})
+		}
+
+		return res
+	}
+	percent := func(v float64) *float64 {
+		return &v
+	}
+
+	tests := []struct {
+		name     string
+		sessions []session
+		to       int
+		expected int
+		percent  *float64
+		gaps     []Gap
+	}{
+		{"нет сеансов и периода", nil, 60, 0, nil, []Gap{}},
+		{"все сеансы по расписанию", sessions(10, 10, 20, 30), 30, 3, percent(100), []Gap{}},
+		{"ранний сеанс считается одним", sessions(10, 10, 12, 20), 20, 3, percent(100), []Gap{}},
+		{"пропуск между сеансами", sessions(10, 10, 40), 40, 4, percent(50), []Gap{{at(10), at(40), 2}}},
+		{"просрочка после последнего сеанса", sessions(10, 10), 50, 5, percent(20), []Gap{{at(10), at(50), 4}}},
+		{"после последнего сеанса в пределах grace", sessions(10, 10), 25, 1, percent(100), []Gap{}},
+		{"период неизвестен", sessions(0, 10, 300), 600, 2, percent(100), []Gap{}},
+		{"период по предыдущему сеансу", []session{{at(10), 10}, {at(70), 60}}, 70, 7, percent(28.57),
+			[]Gap{{at(10), at(70), 5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := testConnectivity.availability(tt.sessions, from, at(tt.to))
+
+			assert.Equal(t, len(tt.sessions), res.Received)
+			assert.Equal(t, tt.expected, res.Expected)
+			assert.Equal(t, tt.percent, res.Percent)
+			assert.Equal(t, tt.gaps, res.Gaps)
+		})
+	}
+}
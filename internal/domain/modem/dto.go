@@ -2,53 +2,56 @@ package modem
 
 import (
 	"github.com/jackc/pgx/v5/pgtype"
+	"seal/internal/domain/battery"
+	"seal/internal/domain/status_flag"
 	"time"
 )
 
 type Data struct {
-	DevTime                  time.Time      `json:"dev_time"`
-	RegTime                  time.Time      `json:"reg_time"`
-	Modem                    int            `json:"modem"`
-	Model                    uint16         `json:"model"`
-	Protocol                 uint8          `json:"protocol"`
-	StatusRaw                uint16         `json:"status_raw"`
-	Status                   uint16         `json:"status"`
-	Iccid                    string         `json:"iccid"`
-	Timezone                 int8           `json:"timezone"`
-	Temperature              any            `json:"temperature"`
-	BatteryVoltage           any            `json:"battery_voltage"`
-	BatteryVoltageMin        any            `json:"battery_voltage_min"`
-	Rssi                     int16          `json:"rssi"`
-	Rsrp                     int16          `json:"rsrp"`
-	Rsrq                     int8           `json:"rsrq"`
-	Snr                      int8           `json:"snr"`
-	Band                     uint8          `json:"band"`
-	Network                  uint8          `json:"network"`
-	SatellitesCount          uint8          `json:"satellites_count"`
-	ModemErrorsCode          uint16         `json:"modem_errors_code"`
-	SealConnectPeriod        uint16         `json:"seal_connect_period"`
-	BatteryLevel             int16          `json:"battery_level"`
-	ConnectPeriod            uint16         `json:"connect_period"`
-	RetriesCount             uint8          `json:"retries_count"`
-	SessionsCount            uint32         `json:"sessions_count"`
-	BatteryLevelMin          uint8          `json:"battery_level_min"`
-	MaxRegTime               uint16         `json:"max_reg_time"`
-	MaxSessionTime           uint16         `json:"max_session_time"`
-	CoordinatesPeriod        uint16         `json:"coordinates_period"`
-	PositioningTime          time.Time      `json:"positioning_time"`
-	Latitude                 any            `json:"latitude"`
-	Longitude                any            `json:"longitude"`
-	Altitude                 int32          `json:"altitude"`
-	Speed                    uint16         `json:"speed"`
-	StatusGpsModule          uint8          `json:"status_gps_module"`
-	Hdop                     uint8          `json:"hdop"`
-	SignalGps                int32          `json:"signal_gps"`
-	SignalGlonass            int32          `json:"signal_glonass"`
-	ErrorsFlags              uint16         `json:"errors_flags"`
-	SatellitesSearchPeriod   uint16         `json:"satellites_search_period"`
-	LowPowerTimeout          uint16         `json:"low_power_timeout"`
-	CoordinatesLbs           *CoordinateLbs `json:"coordinate_lbs"`
-	SensitivityAccelerometer int16          `json:"sensitivity_accelerometer"`
+	DevTime                  time.Time            `json:"dev_time"`
+	RegTime                  time.Time            `json:"reg_time"`
+	Modem                    int                  `json:"modem"`
+	Model                    uint16               `json:"model"`
+	Protocol                 uint8                `json:"protocol"`
+	StatusRaw                uint16               `json:"status_raw"`
+	Status                   uint16               `json:"status"`
+	Iccid                    string               `json:"iccid"`
+	Timezone                 int8                 `json:"timezone"`
+	Temperature              any                  `json:"temperature"`
+	BatteryVoltage           any                  `json:"battery_voltage"`
+	BatteryVoltageMin        any                  `json:"battery_voltage_min"`
+	Rssi                     int16                `json:"rssi"`
+	Rsrp                     int16                `json:"rsrp"`
+	Rsrq                     int8                 `json:"rsrq"`
+	Snr                      int8                 `json:"snr"`
+	Band                     uint8                `json:"band"`
+	Network                  uint8                `json:"network"`
+	SatellitesCount          uint8                `json:"satellites_count"`
+	ModemErrorsCode          uint16               `json:"modem_errors_code"`
+	SealConnectPeriod        uint16               `json:"seal_connect_period"`
+	BatteryLevel             int16                `json:"battery_level"`
+	ConnectPeriod            uint16               `json:"connect_period"`
+	RetriesCount             uint8                `json:"retries_count"`
+	SessionsCount            uint32               `json:"sessions_count"`
+	BatteryLevelMin          uint8                `json:"battery_level_min"`
+	MaxRegTime               uint16               `json:"max_reg_time"`
+	MaxSessionTime           uint16               `json:"max_session_time"`
+	CoordinatesPeriod        uint16               `json:"coordinates_period"`
+	PositioningTime          time.Time            `json:"positioning_time"`
+	Latitude                 any                  `json:"latitude"`
+	Longitude                any                  `json:"longitude"`
+	Altitude                 int32                `json:"altitude"`
+	Speed                    uint16               `json:"speed"`
+	StatusGpsModule          uint8                `json:"status_gps_module"`
+	Hdop                     uint8                `json:"hdop"`
+	SignalGps                int32                `json:"signal_gps"`
+	SignalGlonass            int32                `json:"signal_glonass"`
+	ErrorsFlags              uint16               `json:"errors_flags"`
+	SatellitesSearchPeriod   uint16               `json:"satellites_search_period"`
+	LowPowerTimeout          uint16               `json:"low_power_timeout"`
+	CoordinatesLbs           *CoordinateLbs       `json:"coordinate_lbs"`
+	SensitivityAccelerometer int16                `json:"sensitivity_accelerometer"`
+	Flags                    *status_flag.Decoded `json:"flags"`
 }
 
 type Extra struct {
@@ -96,6 +99,17 @@ type Modem struct {
 	Comment        string             `json:"comment"`
 	Msisdn         *string            `json:"msisdn"`
 	LastCoordinate *Coordinate        `json:"last_coordinate" db:"last_coordinate"`
+	Connectivity   string             `json:"connectivity" db:"-"`
+	Battery        *battery.Forecast  `json:"battery" db:"-"`
+}
+
+// hardwareRevision аппаратная ревизия, по ней каталог команд ограничивает модели
+func (m Modem) hardwareRevision() string {
+	if m.Extra == nil {
+		return ""
+	}
+
+	return m.Extra.HardwareRevision
 }
 
 type SendCommandRequest struct {
@@ -117,6 +131,8 @@ type TrackQueryParams struct {
 	To        time.Time `form:"to"`
 	Limit     int       `form:"limit"`
 	OrderDesc bool      `form:"order_desc"`
+	Tolerance int       `form:"tolerance" validate:"max=100000,min=0"`
+	Zoom      *int      `form:"zoom" validate:"omitempty,max=22,min=0"`
 }
 
 type Coordinate struct {
@@ -131,6 +147,7 @@ type Coordinate struct {
 	SignalGps          int32     `db:"signal_gps" json:"signal_gps"`
 	SignalGlonass      int32     `db:"signal_glonass" json:"signal_glonass"`
 	MinDistanceToRoute *int      `db:"min_distance_to_route" json:"min_distance_to_route"`
+	InSecretArea       bool      `db:"in_secret_area" json:"in_secret_area"`
 }
 
 type CoordinateLbs struct {
@@ -183,6 +200,7 @@ type ModemForList struct {
 	SoftwareVersion  *string            `json:"software_version" db:"software_version"`
 	HardwareRevision *string            `json:"hardware_revision" db:"hardware_revision"`
 	LastCoordinate   *coordinateForList `json:"last_coordinate" db:"last_coordinate"`
+	Connectivity     string             `json:"connectivity"`
 }
 
 type ModemForListShippingReady struct {
@@ -195,24 +213,27 @@ type ModemForListShippingReady struct {
 			Status       int32 `json:"status"`
 			BatteryLevel int16 `json:"battery_level"`
 		} `json:"last"`
+		Battery *battery.Forecast `json:"battery"`
 	} `json:"seals"`
+	Battery *battery.Forecast `json:"battery" db:"-"`
 }
 
 type ArchiveModemData struct {
-	DevTime         time.Time      `json:"dev_time"`
-	RegTime         time.Time      `json:"reg_time"`
-	Status          int32          `json:"status"`
-	ErrorsFlags     int32          `json:"errors_flags"`
-	PositioningTime time.Time      `json:"positioning_time"`
-	Latitude        any            `json:"latitude"`
-	Longitude       any            `json:"longitude"`
-	Altitude        int32          `json:"altitude"`
-	SatellitesCount int16          `json:"satellites_count"`
-	Speed           int32          `json:"speed"`
-	StatusGpsModule int16          `json:"status_gps_module"`
-	Rssi            int16          `json:"rssi"`
-	BatteryLevel    int16          `json:"battery_level"`
-	CoordinatesLbs  *CoordinateLbs `json:"coordinate_lbs"`
-	SignalGps       int32          `json:"signal_gps"`
-	SignalGlonass   int32          `json:"signal_glonass"`
+	DevTime         time.Time           `json:"dev_time"`
+	RegTime         time.Time           `json:"reg_time"`
+	Status          int32               `json:"status"`
+	ErrorsFlags     int32               `json:"errors_flags"`
+	PositioningTime time.Time           `json:"positioning_time"`
+	Latitude        any                 `json:"latitude"`
+	Longitude       any                 `json:"longitude"`
+	Altitude        int32               `json:"altitude"`
+	SatellitesCount int16               `json:"satellites_count"`
+	Speed           int32               `json:"speed"`
+	StatusGpsModule int16               `json:"status_gps_module"`
+	Rssi            int16               `json:"rssi"`
+	BatteryLevel    int16               `json:"battery_level"`
+	CoordinatesLbs  *CoordinateLbs      `json:"coordinate_lbs"`
+	SignalGps       int32               `json:"signal_gps"`
+	SignalGlonass   int32               `json:"signal_glonass"`
+	Flags           status_flag.Decoded `json:"flags"`
 }
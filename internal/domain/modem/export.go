@@ -0,0 +1,59 @@
+package modem
+
+import (
+	"math"
+	"seal/pkg/app_error"
+	"seal/pkg/track_export"
+)
+
+// ExportTrack трек модема для выгрузки в GIS-форматы.
+// Координаты в секретных зонах и без навигационного решения не выгружаются и разрывают трек на участки
+func (s *usecase) ExportTrack(params TrackQueryParams, name string) (track_export.Track, error) {
+	if errs := s.validator.Struct(params); errs != nil {
+		return track_export.Track{}, app_error.ValidationError(errs)
+	}
+
+	params.OrderDesc = false
+	if params.Limit == 0 {
+		params.Limit = MAX_RETURNING_ROWS
+	}
+
+	coords, err := s.repo.Track(params)
+	if err != nil {
+		return track_export.Track{}, err
+	}
+
+	if params.Simplified() {
+		coords = simplify(coords, params)
+	}
+
+	return track_export.Track{Name: name, Segments: segments(coords)}, nil
+}
+
+func segments(coords []Coordinate) [][]track_export.Point {
+	var result [][]track_export.Point
+	var current []track_export.Point
+
+	for _, c := range coords {
+		if c.InSecretArea || math.IsNaN(float64(c.Latitude)) || math.IsNaN(float64(c.Longitude)) {
+			if len(current) > 0 {
+				result = append(result, current)
+				current = nil
+			}
+			continue
+		}
+
+		current = append(current, track_export.Point{
+			Time:      c.DevTime,
+			Latitude:  float64(c.Latitude),
+			Longitude: float64(c.Longitude),
+			Altitude:  c.Altitude,
+		})
+	}
+
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+
+	return result
+}
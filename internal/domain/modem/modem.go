@@ -6,6 +6,8 @@ import (
 	modemLogRaw "seal/internal/domain/modem_log_raw"
 	"seal/internal/repository/pg/query"
 	"seal/internal/transport"
+	"seal/pkg/track_export"
+	"time"
 )
 
 type Db struct {
@@ -24,8 +26,9 @@ type Repo interface {
 	GetById(id int) (Modem, error)
 	GetDbById(id int) (Db, error)
 	GetByImei(imei uint64) (Modem, error)
-	List(params transport.QueryParams) (query.List[ModemForList], error)
+	List(params ListQueryParams, connectivity Connectivity) (query.List[ModemForList], error)
 	ListShippingReady(params transport.QueryParams) (query.List[ModemForListShippingReady], error)
+	Sessions(id int, from, to time.Time) ([]session, error)
 	Track(params TrackQueryParams) ([]Coordinate, error)
 	TrackLbs(params TrackQueryParams) ([]CoordinateLbs, error)
 	Update(data Db) (Modem, error)
@@ -35,13 +38,16 @@ type Usecase interface {
 	GetById(id int) (Modem, error)
 	GetDbById(id int) (Db, error)
 	GetByImei(imei uint64) (Modem, error)
-	List(params transport.QueryParams) (query.List[ModemForList], error)
+	WithBattery(modem Modem) Modem
+	List(params ListQueryParams) (query.List[ModemForList], error)
 	ListShippingReady(params transport.QueryParams) (query.List[ModemForListShippingReady], error)
-	SendCommand(sealId int, data SendCommandRequest, author string) (bool, error)
-	CommandsList(sealId int) (query.List[command.Command], error)
+	Availability(params AvailabilityQueryParams) (Availability, error)
+	SendCommand(sealId int, data SendCommandRequest, author string, role int) (bool, error)
+	CommandsList(sealId int, params command.HistoryQueryParams) (query.List[command.Command], error)
 	Archive(params ArchiveQueryParams) ([]ArchiveModemData, error)
 	LogRawTelemetry(params ArchiveQueryParams) ([]ArchiveModemData, error)
 	Track(params TrackQueryParams) (TrackResponse, error)
+	ExportTrack(params TrackQueryParams, name string) (track_export.Track, error)
 	TrackLbs(params TrackQueryParams) ([]CoordinateLbs, error)
 	Log(params LogQueryParams) ([]modemLogRaw.ModemLogRaw, error)
 	Update(id int, data UpdateRequest) (Modem, error)
@@ -3,12 +3,15 @@ package modem
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v5"
 	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping_status"
 	"seal/internal/repository/pg"
 	"seal/internal/repository/pg/query"
 	"seal/internal/transport"
 	"seal/pkg/app_error"
+	"time"
 )
 
 const MAX_RETURNING_ROWS = 50000
@@ -46,11 +49,6 @@ func (r *repo) Update(modem Db) (Modem, error) {
 
 func (r *repo) GetById(id int) (Modem, error) {
 
-	qLastCharge := "(select dev_time from modems_data where dev_time > " +
-		"(select dev_time from modems_data md where dev_time < now() and dev_time < " +
-		"(select dev_time from modems_data where dev_time < now() and modem = m.id and battery_level = 100 order by dev_time desc limit 1) " +
-		"and modem = m.id and battery_level < 100 order by dev_time desc limit 1) and modem = m.id order by dev_time limit 1)"
-
 	q := query.New[Modem](r.ctx, r.db).
 		Select("m.id", "").
 		AddSelect("m.imei", "").
@@ -70,7 +68,7 @@ func (r *repo) GetById(id int) (Modem, error) {
 			"from seals s "+
 			"left join lateral (select * from seals_data where seal = s.id and modem = m.id order by dev_time desc limit 1) sd ON true "+
 			"where s.serial = any(m.serials_of_seals)) t)", "seals").
-		AddSelect(qLastCharge, "last_charge_time").
+		AddSelect("(select max(mc.time_end) from modem_charges mc where mc.modem = m.id)", "last_charge_time").
 		From("modems", "m").
 		LeftJoin("l", "modems_data", "l.dev_time = m.last_dev_time and l.modem = m.id").
 		LeftJoin("lbs", "coordinates_lbs", "lbs.dev_time = l.dev_time and lbs.modem = l.modem").
@@ -128,7 +126,11 @@ func (r *repo) GetByImei(imei uint64) (Modem, error) {
 	return r.GetById(data.Id)
 }
 
-func (r *repo) List(params transport.QueryParams) (query.List[ModemForList], error) {
+func (r *repo) List(params ListQueryParams, connectivity Connectivity) (query.List[ModemForList], error) {
+	// условие фильтра попадает и в подсчёт количества без join, поэтому период берётся подзапросом
+	qConnectivity := connectivity.stateSql("m.last_dev_time",
+		"(select md.connect_period from modems_data md where md.dev_time = m.last_dev_time and md.modem = m.id)")
+
 	q := query.New[ModemForList](r.ctx, r.db).
 		Select("m.id", "").
 		AddSelect("m.imei", "").
@@ -144,10 +146,13 @@ func (r *repo) List(params transport.QueryParams) (query.List[ModemForList], err
 			"c.dev_time, c.latitude, c.longitude, c.altitude, c.satellites_count, speed, status_gps_module, min_distance_to_route "+
 			"from coordinates c where c.dev_time < Now() and c.modem = m.id and latitude != 'NaN' and longitude != 'NaN' "+
 			"order by c.dev_time desc limit 1) t)", "last_coordinate").
+		AddSelect(qConnectivity, "connectivity").
 		From("modems", "m").
 		LeftJoin("l", "modems_data", "l.dev_time = m.last_dev_time and l.modem = m.id").
 		FilterWhere(params.FindType, "serial", params.Find).
 		OrFilterWhere(params.FindType, "imei", params.Find).
+		Grouped().
+		AndFilterWhere(query.IN, qConnectivity, params.Connectivity).
 		OrderBy("serial").
 		Limit(min(params.Limit, MAX_RETURNING_ROWS)).
 		Offset(params.Offset)
@@ -173,7 +178,8 @@ func (r *repo) ListShippingReady(params transport.QueryParams) (query.List[Modem
 		InnerJoin("sl", "seals_data", "sl.dev_time = s.last_dev_time and sl.seal = s.id").
 		FilterWhere(params.FindType, "m.serial", params.Find).
 		OrFilterWhere(params.FindType, "m.imei", params.Find).
-		AndWhereNotExists("select * from shipping where modem=m.id and status<>2").
+		AndWhereNotExists(fmt.Sprintf("select * from shipping where modem=m.id and status not in (%d,%d)",
+			shipping_status.END, shipping_status.CANCELLED)).
 		OrderBy("m.serial").
 		GroupBy("m.id").
 		Limit(min(params.Limit, MAX_RETURNING_ROWS)).
@@ -207,15 +213,18 @@ func (r *repo) Track(params TrackQueryParams) ([]Coordinate, error) {
 		AddSelect("c.signal_gps", "").
 		AddSelect("c.signal_glonass", "").
 		AddSelect("c.min_distance_to_route", "").
+		AddSelect("count(sa.*) > 0", "in_secret_area").
 		From("coordinates", "c").
+		LeftJoin("sa", "secret_areas", "sa.area @> point(c.latitude, c.longitude)").
 		Where(query.GREAT, "c.dev_time", params.From).
 		AndFilterWhere(query.LITTLE_OR_EQ, "c.dev_time", params.To).
 		AndWhere(query.EQUEL, "c.modem", params.Id).
 		OrderBy(order).
-		Limit(min(params.Limit, MAX_RETURNING_ROWS))
+		GroupBy("c.dev_time, c.modem").
+		Limit(params.maxRows())
 
 	data, err := q.All()
-	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
 
 	return data, nil
 }
@@ -240,3 +249,21 @@ func (r *repo) TrackLbs(params TrackQueryParams) ([]CoordinateLbs, error) {
 
 	return data, nil
 }
+
+// Sessions сеансы связи модема за окно по возрастанию времени
+func (r *repo) Sessions(id int, from, to time.Time) ([]session, error) {
+	q := query.New[session](r.ctx, r.db).
+		Select("md.dev_time", "").
+		AddSelect("coalesce(md.connect_period, 0)", "connect_period").
+		From("modems_data", "md").
+		Where(query.EQUEL, "md.modem", id).
+		AndWhere(query.GREAT_OR_EQ, "md.dev_time", from).
+		AndWhere(query.LITTLE_OR_EQ, "md.dev_time", to).
+		OrderBy("md.dev_time").
+		Limit(MAX_AVAILABILITY_SESSIONS)
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
@@ -0,0 +1,120 @@
+package modem
+
+import (
+	"math"
+	"seal/pkg/geo"
+	"slices"
+)
+
+// MAX_SIMPLIFY_ROWS максимальное количество координат, из которых строится упрощённый трек
+const MAX_SIMPLIFY_ROWS = 500000
+
+// SIMPLIFY_STOP_SPEED скорость, ниже которой точка считается точкой стоянки, км/ч
+const SIMPLIFY_STOP_SPEED = 5
+
+// SIMPLIFY_ROUTE_JUMP изменение расстояния до маршрута между соседними точками, при котором обе точки сохраняются, м
+const SIMPLIFY_ROUTE_JUMP = 100
+
+// ZOOM_METERS_PER_PIXEL размер пикселя на экваторе при масштабе карты 0 (Web Mercator, тайл 256 px), м
+const ZOOM_METERS_PER_PIXEL = 156543.03
+
+// Simplified запрошено ли упрощение трека
+func (p TrackQueryParams) Simplified() bool {
+	return p.Tolerance > 0 || p.Zoom != nil
+}
+
+func (p TrackQueryParams) maxRows() int {
+	if p.Simplified() {
+		return MAX_SIMPLIFY_ROWS
+	}
+
+	return min(p.Limit, MAX_RETURNING_ROWS)
+}
+
+// tolerance допуск упрощения, м. Для масштаба карты - размер пикселя на широте latitude
+func (p TrackQueryParams) tolerance(latitude float64) float64 {
+	if p.Tolerance > 0 {
+		return float64(p.Tolerance)
+	}
+
+	return ZOOM_METERS_PER_PIXEL * math.Cos(latitude*math.Pi/180) / math.Pow(2, float64(*p.Zoom))
+}
+
+// simplify упрощает трек, упорядоченный по времени. Всегда сохраняются первая и последняя точки, границы стоянок,
+// скачки расстояния до маршрута, точки без навигационного решения и границы секретных зон
+func simplify(coords []Coordinate, params TrackQueryParams) []Coordinate {
+	if len(coords) < 3 {
+		return coords
+	}
+
+	points := make([]geo.Point, len(coords))
+	latitude := math.NaN()
+
+	for i, c := range coords {
+		points[i] = geo.Point{Latitude: float64(c.Latitude), Longitude: float64(c.Longitude)}
+		if math.IsNaN(latitude) && !noFix(c) {
+			latitude = points[i].Latitude
+		}
+	}
+
+	if math.IsNaN(latitude) {
+		return coords
+	}
+
+	keep := func(i int) bool {
+		c := coords[i]
+		if noFix(c) || noFix(coords[i-1]) || noFix(coords[i+1]) {
+			return true
+		}
+
+		for _, neighbour := range []Coordinate{coords[i-1], coords[i+1]} {
+			if c.InSecretArea != neighbour.InSecretArea ||
+				(c.Speed < SIMPLIFY_STOP_SPEED) != (neighbour.Speed < SIMPLIFY_STOP_SPEED) ||
+				routeJump(c.MinDistanceToRoute, neighbour.MinDistanceToRoute) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	indexes := geo.Simplify(points, params.tolerance(latitude), keep)
+
+	result := make([]Coordinate, 0, len(indexes))
+	for _, i := range indexes {
+		result = append(result, coords[i])
+	}
+
+	return result
+}
+
+func noFix(c Coordinate) bool {
+	return math.IsNaN(float64(c.Latitude)) || math.IsNaN(float64(c.Longitude))
+}
+
+func routeJump(a, b *int) bool {
+	if a == nil || b == nil {
+		return a != b
+	}
+
+	return max(*a-*b, *b-*a) >= SIMPLIFY_ROUTE_JUMP
+}
+
+// simplifyTrack загружает трек по возрастанию времени, упрощает и возвращает в запрошенном порядке
+func (s *usecase) simplifyTrack(params TrackQueryParams) ([]Coordinate, error) {
+	orderDesc := params.OrderDesc
+	params.OrderDesc = false
+
+	coords, err := s.repo.Track(params)
+	if err != nil {
+		return coords, err
+	}
+
+	coords = simplify(coords, params)
+
+	if orderDesc {
+		slices.Reverse(coords)
+	}
+
+	return coords, nil
+}
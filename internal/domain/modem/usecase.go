@@ -3,9 +3,11 @@ package modem
 import (
 	"fmt"
 	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/battery"
 	"seal/internal/domain/command"
 	modemData "seal/internal/domain/modem_data"
 	modemLogRaw "seal/internal/domain/modem_log_raw"
+	"seal/internal/domain/status_flag"
 	"seal/internal/repository/pg/query"
 	"seal/internal/transport"
 	"seal/pkg/app_error"
@@ -16,28 +18,76 @@ import (
 
 type cmds interface {
 	Send(serial string, name string, params any, author string) (bool, error)
-	List(serial string) (query.List[command.Command], error)
 }
 
 type CoreUseCase struct {
+	Battery     battery.Usecase
+	Command     command.Usecase
 	Commands    cmds
 	ModemData   modemData.Usecase
 	ModemLogRaw modemLogRaw.Usecase
+	StatusFlag  status_flag.Usecase
 }
 
 type usecase struct {
-	repo      Repo
-	logger    app_interface.Logger
-	validator app_interface.Validator
-	usecase   CoreUseCase
+	repo         Repo
+	logger       app_interface.Logger
+	validator    app_interface.Validator
+	connectivity Connectivity
+	usecase      CoreUseCase
 }
 
-func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, coreUseCase CoreUseCase) Usecase {
-	return &usecase{repo, logger, validator, coreUseCase}
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, connectivity Connectivity, coreUseCase CoreUseCase) Usecase {
+	return &usecase{repo, logger, validator, connectivity, coreUseCase}
 }
 
 func (s *usecase) GetById(id int) (Modem, error) {
-	return s.repo.GetById(id)
+	return s.detail(s.repo.GetById(id))
+}
+
+// detail дополняет модем расчётными полями: состояние связи и расшифровка флагов
+func (s *usecase) detail(modem Modem, err error) (Modem, error) {
+	if err != nil {
+		return modem, err
+	}
+
+	var lastDevTime *time.Time
+	if modem.LastDevTime.Valid {
+		lastDevTime = &modem.LastDevTime.Time
+	}
+
+	connectPeriod := 0
+	if modem.Last != nil {
+		connectPeriod = int(modem.Last.ConnectPeriod)
+	}
+
+	modem.Connectivity = s.connectivity.State(lastDevTime, connectPeriod, time.Now())
+
+	if modem.Last != nil {
+		flags := s.usecase.StatusFlag.Decode(int(modem.Last.Model), int(modem.Last.Protocol), status_flag.Values{
+			Status:          int64(modem.Last.Status),
+			StatusRaw:       int64(modem.Last.StatusRaw),
+			ErrorsFlags:     int64(modem.Last.ErrorsFlags),
+			ModemErrorsCode: int64(modem.Last.ModemErrorsCode),
+		})
+		modem.Last.Flags = &flags
+	}
+
+	return modem, nil
+}
+
+// WithBattery дополняет модем прогнозом батареи. Прогноз нужен только карточке модема,
+// внутренние вызовы GetById/GetByImei его не считают
+func (s *usecase) WithBattery(modem Modem) Modem {
+	// без прогноза модем всё равно нужен, ошибка только логируется
+	if forecasts, err := s.usecase.Battery.Modems([]int{modem.Id}); err != nil {
+		s.logger.Error(fmt.Sprintf("Battery forecast modem %d: %v", modem.Id, err))
+	} else {
+		forecast := forecasts[modem.Id]
+		modem.Battery = &forecast
+	}
+
+	return modem
 }
 
 func (s *usecase) GetDbById(id int) (Db, error) {
@@ -59,15 +109,15 @@ func (s *usecase) Update(id int, data UpdateRequest) (Modem, error) {
 }
 
 func (s *usecase) GetByImei(imei uint64) (Modem, error) {
-	return s.repo.GetByImei(imei)
+	return s.detail(s.repo.GetByImei(imei))
 }
 
-func (s *usecase) List(queryParams transport.QueryParams) (query.List[ModemForList], error) {
+func (s *usecase) List(queryParams ListQueryParams) (query.List[ModemForList], error) {
 	if errs := s.validator.Struct(queryParams); errs != nil {
 		return query.List[ModemForList]{}, app_error.ValidationError(errs)
 	}
 
-	return s.repo.List(queryParams)
+	return s.repo.List(queryParams, s.connectivity)
 }
 
 func (s *usecase) ListShippingReady(queryParams transport.QueryParams) (query.List[ModemForListShippingReady], error) {
@@ -75,10 +125,43 @@ func (s *usecase) ListShippingReady(queryParams transport.QueryParams) (query.Li
 		return query.List[ModemForListShippingReady]{}, app_error.ValidationError(errs)
 	}
 
-	return s.repo.ListShippingReady(queryParams)
+	list, err := s.repo.ListShippingReady(queryParams)
+	if err != nil {
+		return list, err
+	}
+
+	var modemIds, sealIds []int
+	for _, modem := range list.Data {
+		modemIds = append(modemIds, modem.Id)
+		for _, seal := range modem.Seals {
+			sealIds = append(sealIds, seal.Id)
+		}
+	}
+
+	modems, err := s.usecase.Battery.Modems(modemIds)
+	if err != nil {
+		return list, err
+	}
+
+	seals, err := s.usecase.Battery.Seals(sealIds)
+	if err != nil {
+		return list, err
+	}
+
+	for i := range list.Data {
+		forecast := modems[list.Data[i].Id]
+		list.Data[i].Battery = &forecast
+
+		for j := range list.Data[i].Seals {
+			forecast := seals[list.Data[i].Seals[j].Id]
+			list.Data[i].Seals[j].Battery = &forecast
+		}
+	}
+
+	return list, nil
 }
 
-func (s *usecase) SendCommand(id int, data SendCommandRequest, author string) (bool, error) {
+func (s *usecase) SendCommand(id int, data SendCommandRequest, author string, role int) (bool, error) {
 
 	modem, err := s.GetById(id)
 
@@ -86,14 +169,23 @@ func (s *usecase) SendCommand(id int, data SendCommandRequest, author string) (b
 		return false, err
 	}
 
+	template, err := s.usecase.Command.Check(data.Name, data.Params, role)
+	if err != nil {
+		return false, err
+	}
+
+	if template != nil && !template.Supports(modem.hardwareRevision()) {
+		return false, app_error.ValidationError(map[string]string{"name": "Команда не поддерживается моделью модема"})
+	}
+
 	imei := fmt.Sprintf("%v", modem.Imei)
 
 	return s.usecase.Commands.Send(imei, data.Name, data.Params, author)
 }
 
-func (s *usecase) CommandsList(id int) (query.List[command.Command], error) {
+func (s *usecase) CommandsList(id int, params command.HistoryQueryParams) (query.List[command.Command], error) {
 
-	modem, err := s.GetById(id)
+	modem, err := s.GetDbById(id)
 
 	if err != nil {
 		return query.List[command.Command]{}, err
@@ -101,7 +193,7 @@ func (s *usecase) CommandsList(id int) (query.List[command.Command], error) {
 
 	imei := fmt.Sprintf("%v", modem.Imei)
 
-	return s.usecase.Commands.List(imei)
+	return s.usecase.Command.History(imei, params)
 }
 
 func (s *usecase) Archive(params ArchiveQueryParams) ([]ArchiveModemData, error) {
@@ -140,6 +232,12 @@ func (s *usecase) Archive(params ArchiveQueryParams) ([]ArchiveModemData, error)
 			BatteryLevel:    data.BatteryLevel,
 			SignalGps:       data.SignalGps,
 			SignalGlonass:   data.SignalGlonass,
+			Flags: s.usecase.StatusFlag.Decode(int(data.Model), int(data.Protocol), status_flag.Values{
+				Status:          int64(data.Status),
+				StatusRaw:       int64(data.StatusRaw),
+				ErrorsFlags:     int64(data.ErrorsFlags),
+				ModemErrorsCode: int64(data.ModemErrorsCode),
+			}),
 		}
 
 		if data.CoordinatesLbs != nil {
@@ -198,6 +296,10 @@ func (s *usecase) LogRawTelemetry(params ArchiveQueryParams) ([]ArchiveModemData
 			BatteryLevel:    data.BatteryLevel,
 			SignalGps:       data.SignalGps,
 			SignalGlonass:   data.SignalGlonass,
+			Flags: s.usecase.StatusFlag.Decode(data.Model, data.Protocol, status_flag.Values{
+				Status:      int64(data.Status),
+				ErrorsFlags: int64(data.ErrorsFlags),
+			}),
 		}
 
 		archive = append(archive, archiveModemData)
@@ -211,6 +313,11 @@ func (s *usecase) Track(params TrackQueryParams) (TrackResponse, error) {
 		return TrackResponse{}, app_error.ValidationError(errs)
 	}
 
+	if params.Simplified() {
+		coords, err := s.simplifyTrack(params)
+		return TrackResponse{Coordinates: coords}, err
+	}
+
 	coords, err := s.repo.Track(params)
 
 	return TrackResponse{Coordinates: coords}, err
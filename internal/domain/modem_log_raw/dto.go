@@ -24,6 +24,8 @@ type ListParams struct {
 type Telemetry struct {
 	CurrentTime     int64     `db:"current_time"`
 	RegTime         time.Time `db:"reg_time"`
+	Model           int       `db:"model"`
+	Protocol        int       `db:"protocol"`
 	Status          int32     `db:"status"`
 	ErrorsFlags     int32     `db:"errors_flags"`
 	PositioningTime int64     `db:"positioning_time"`
@@ -50,6 +50,8 @@ func (r *repo) ListTelemetry(params ListParams) ([]Telemetry, error) {
 	q := query.New[Telemetry](r.ctx, r.db).
 		Select("d.reg_time", "").
 		AddSelect("coalesce((d.payload#>>'{current_time}')::int8, 0)", "current_time").
+		AddSelect("coalesce((d.payload#>>'{model}')::int8, 0)", "model").
+		AddSelect("coalesce((d.payload#>>'{protocol}')::int8, 0)", "protocol").
 		AddSelect("coalesce((d.payload#>>'{status}')::int8, 0)", "status").
 		AddSelect("coalesce((d.payload#>>'{errors_flags}')::int8, 0)", "errors_flags").
 		AddSelect("coalesce((d.payload#>>'{positioning_time}')::int8, 0)", "positioning_time").
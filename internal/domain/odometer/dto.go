@@ -0,0 +1,54 @@
+package odometer
+
+import (
+	"time"
+)
+
+type Db struct {
+	Shipping      int
+	CheckedAt     time.Time
+	LastDevTime   *time.Time
+	LastLatitude  *float32
+	LastLongitude *float32
+	Rejected      int
+	RejectedTotal int
+	Points        int
+	Distance      float64 // метры
+	MovingTime    int
+}
+
+// MOVING_SPEED скорость между соседними координатами, начиная с которой транспорт считается движущимся, км/ч
+const MOVING_SPEED = 5
+
+// MAX_REJECTED_IN_ROW количество координат подряд, отброшенных по скорости, после которого
+// новое положение считается достоверным (транспорт перевезён или модем долго был без связи)
+const MAX_REJECTED_IN_ROW = 3
+
+// MAX_COORDINATES максимальное количество координат, по которым считается пробег модема за один запрос
+const MAX_COORDINATES = 500000
+
+// CHECK_BATCH_SIZE количество координат перевозки, обрабатываемых за одну проверку
+const CHECK_BATCH_SIZE = 5000
+
+// CHECK_SHIPPING_LIMIT количество перевозок, обрабатываемых за одну проверку
+const CHECK_SHIPPING_LIMIT = 100
+
+type QueryParams struct {
+	Id   int       `validate:"required"`
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+}
+
+type Repo interface {
+	Coordinates(modemId int, from, to time.Time, limit int) ([]coordinate, error)
+	ListForCheck(limit int) ([]shippingForCheck, error)
+	Saved(shippingId int) (saved, error)
+	Days(shippingId int) ([]Day, error)
+	Save(data Db, days []Day) error
+}
+
+type Usecase interface {
+	ByModem(params QueryParams) (Odometer, error)
+	ByShipping(id int) (ShippingOdometer, error)
+	Check() error
+}
@@ -0,0 +1,98 @@
+package odometer
+
+import (
+	"math"
+	"seal/pkg/geo"
+	"time"
+)
+
+// state накопленный пробег и последняя принятая координата
+type state struct {
+	last     *coordinate
+	rejected int
+	distance float64
+	moving   int
+}
+
+// add учитывает очередную координату. Координаты с плохим навигационным решением отбрасываются,
+// как и скачки, требующие скорости больше filter.MaxSpeed от последней принятой координаты.
+// Возвращает пройденные от предыдущей точки расстояние (м) и время в движении (с)
+func (s *state) add(c coordinate, filter Filter) (float64, int, bool) {
+	if math.IsNaN(float64(c.Latitude)) || math.IsNaN(float64(c.Longitude)) ||
+		filter.MaxHdop > 0 && int(c.Hdop) > filter.MaxHdop ||
+		filter.MinSatellites > 0 && int(c.SatellitesCount) < filter.MinSatellites {
+		return 0, 0, false
+	}
+
+	if s.last == nil {
+		s.last = &c
+		return 0, 0, true
+	}
+
+	seconds := c.DevTime.Sub(s.last.DevTime).Seconds()
+	if seconds <= 0 {
+		return 0, 0, false
+	}
+
+	distance := geo.Distance(float64(s.last.Latitude), float64(s.last.Longitude), float64(c.Latitude), float64(c.Longitude))
+	speed := distance / seconds * 3.6
+
+	if filter.MaxSpeed > 0 && speed > float64(filter.MaxSpeed) {
+		if s.rejected++; s.rejected < MAX_REJECTED_IN_ROW {
+			return 0, 0, false
+		}
+
+		// новое положение подтверждено несколькими координатами, расстояние до него не учитывается
+		s.last, s.rejected = &c, 0
+		return 0, 0, true
+	}
+
+	moving := 0
+	if speed >= MOVING_SPEED {
+		moving = int(seconds)
+	}
+
+	s.last, s.rejected = &c, 0
+	s.distance += distance
+	s.moving += moving
+
+	return distance, moving, true
+}
+
+// measure пробег по упорядоченным по времени координатам с разбивкой по суткам (время сервера)
+func measure(coords []coordinate, filter Filter) Odometer {
+	var st state
+	result := Odometer{Days: []Day{}}
+
+	for _, c := range coords {
+		distance, moving, ok := st.add(c, filter)
+		if !ok {
+			result.Rejected++
+			continue
+		}
+
+		result.Points++
+
+		date := c.DevTime.In(time.Local).Format(time.DateOnly)
+		if len(result.Days) == 0 || result.Days[len(result.Days)-1].Date != date {
+			result.Days = append(result.Days, Day{Date: date})
+		}
+
+		day := &result.Days[len(result.Days)-1]
+		day.Distance += distance
+		day.MovingTime += moving
+	}
+
+	for i := range result.Days {
+		result.Days[i].Distance = kilometers(result.Days[i].Distance)
+	}
+
+	result.Distance = kilometers(st.distance)
+	result.MovingTime = st.moving
+
+	return result
+}
+
+func kilometers(meters float64) float64 {
+	return math.Round(meters/100) / 10
+}
@@ -0,0 +1,232 @@
+package odometer
+
+import (
+	"math"
+	"seal/pkg/geo"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var t0 = time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+
+var filter = Filter{MaxHdop: 5, MinSatellites: 4, MaxSpeed: 150}
+
+// coord координата через minute минут от t0, смещённая на north метров к северу от (55, 37)
+func coord(minute int, north float64) coordinate {
+	return coordinate{
+		DevTime:         t0.Add(time.Duration(minute) * time.Minute),
+		Latitude:        float32(55 + north*180/math.Pi/geo.EARTH_RADIUS),
+		Longitude:       37,
+		Hdop:            1,
+		SatellitesCount: 8,
+	}
+}
+
+func TestAdd(t *testing.T) {
+	badHdop, fewSatellites, nan := coord(2, 2000), coord(2, 2000), coord(2, 2000)
+	badHdop.Hdop = 6
+	fewSatellites.SatellitesCount = 3
+	nan.Latitude = float32(math.NaN())
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		coords   []coordinate
+		accepted []bool
+		distance float64 // м
+		moving   int
+		rejected int
+	}{
+		{"первая координата", filter, []coordinate{coord(0, 0)}, []bool{true}, 0, 0, 0},
+		{"движение 60 км/ч", filter, []coordinate{coord(0, 0), coord(1, 1000), coord(2, 2000)}, []bool{true, true, true}, 2000, 120, 0},
+		{"стоянка не считается движением", filter, []coordinate{coord(0, 0), coord(10, 100)}, []bool{true, true}, 100, 0, 0},
+		{"hdop больше допустимого", filter, []coordinate{coord(0, 0), coord(1, 1000), badHdop}, []bool{true, true, false}, 1000, 60, 0},
+		{"мало спутников", filter, []coordinate{coord(0, 0), coord(1, 1000), fewSatellites}, []bool{true, true, false}, 1000, 60, 0},
+		{"нет координат", filter, []coordinate{coord(0, 0), coord(1, 1000), nan}, []bool{true, true, false}, 1000, 60, 0},
+		{"фильтр выключен", Filter{}, []coordinate{coord(0, 0), badHdop, coord(3, 200000)}, []bool{true, true, true}, 200000, 180, 0},
+		{"время не растёт", filter, []coordinate{coord(0, 0), coord(1, 1000), coord(1, 1500)}, []bool{true, true, false}, 1000, 60, 0},
+		{"скачок отбрасывается", filter, []coordinate{coord(0, 0), coord(1, 1000), coord(2, 50000), coord(3, 2000)}, []bool{true, true, false, true}, 2000, 180, 0},
+		{"скачки подряд копятся", filter, []coordinate{coord(0, 0), coord(1, 50000), coord(2, 51000)}, []bool{true, false, false}, 0, 0, 2},
+		{
+			"новое положение подтверждено",
+			filter,
+			[]coordinate{coord(0, 0), coord(1, 1000), coord(2, 100000), coord(3, 101000), coord(4, 102000), coord(5, 103000)},
+			[]bool{true, true, false, false, true, true},
+			2000, 120, 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var st state
+
+			accepted := make([]bool, len(tt.coords))
+			for i, c := range tt.coords {
+				_, _, accepted[i] = st.add(c, tt.filter)
+			}
+
+			assert.Equal(t, tt.accepted, accepted)
+			assert.InDelta(t, tt.distance, st.distance, 2)
+			assert.Equal(t, tt.moving, st.moving)
+			assert.Equal(t, tt.rejected, st.rejected)
+		})
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name     string
+		coords   []coordinate
+		distance float64 // км
+		moving   int
+		points   int
+		rejected int
+	}{
+		{"нет координат", nil, 0, 0, 0, 0},
+		{"движение", []coordinate{coord(0, 0), coord(1, 1000), coord(2, 2000), coord(3, 3000)}, 3, 180, 4, 0},
+		{"скачок отброшен", []coordinate{coord(0, 0), coord(1, 1000), coord(2, 50000), coord(3, 2000)}, 2, 180, 3, 1},
+		{"перемещение без пробега", []coordinate{coord(0, 0), coord(1, 100000), coord(2, 101000), coord(3, 102000), coord(4, 103000)}, 1, 60, 3, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := measure(tt.coords, filter)
+
+			assert.Equal(t, tt.distance, res.Distance)
+			assert.Equal(t, tt.moving, res.MovingTime)
+			assert.Equal(t, tt.points, res.Points)
+			assert.Equal(t, tt.rejected, res.Rejected)
+		})
+	}
+
+	t.Run("разбивка по суткам", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 23, 58, 0, 0, time.Local).Sub(t0)
+		coords := []coordinate{coord(0, 0), coord(1, 1000), coord(2, 2000), coord(3, 3000)}
+		for i := range coords {
+			coords[i].DevTime = coords[i].DevTime.Add(start)
+		}
+
+		res := measure(coords, filter)
+
+		assert.Equal(t, []Day{
+			{Date: "2024-01-01", Distance: 1, MovingTime: 60},
+			{Date: "2024-01-02", Distance: 2, MovingTime: 120},
+		}, res.Days)
+		assert.Equal(t, float64(3), res.Distance)
+	})
+}
+
+type fakeRepo struct {
+	Repo
+	coords []coordinate
+	from   time.Time
+	saved  []Db
+	days   [][]Day
+}
+
+func (r *fakeRepo) Coordinates(modemId int, from, to time.Time, limit int) ([]coordinate, error) {
+	r.from = from
+
+	var res []coordinate
+	for _, c := range r.coords {
+		if c.DevTime.After(from) && (to.IsZero() || !c.DevTime.After(to)) && len(res) < limit {
+			res = append(res, c)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *fakeRepo) Save(data Db, days []Day) error {
+	r.saved = append(r.saved, data)
+	r.days = append(r.days, days)
+	return nil
+}
+
+// resumed перевозка для следующей проверки по сохранённому состоянию
+func resumed(sh shippingForCheck, data Db) shippingForCheck {
+	sh.CheckedAt = &data.CheckedAt
+	sh.LastDevTime, sh.LastLatitude, sh.LastLongitude = data.LastDevTime, data.LastLatitude, data.LastLongitude
+	sh.Rejected, sh.RejectedTotal = data.Rejected, data.RejectedTotal
+	sh.Points, sh.Distance, sh.MovingTime = data.Points, data.Distance, data.MovingTime
+
+	return sh
+}
+
+func TestCheckShipping(t *testing.T) {
+	t.Run("продолжение с сохранённой координаты", func(t *testing.T) {
+		repo := &fakeRepo{coords: []coordinate{coord(0, 0), coord(1, 1000), coord(2, 2000)}}
+		s := &usecase{repo: repo, filter: filter}
+		sh := shippingForCheck{Id: 1, Modem: 2, TimeStart: t0.Add(-time.Minute)}
+
+		require.NoError(t, s.checkShipping(sh))
+		require.Len(t, repo.saved, 1)
+		first := repo.saved[0]
+		assert.Equal(t, coord(2, 0).DevTime, first.CheckedAt)
+		assert.Equal(t, coord(2, 0).DevTime, *first.LastDevTime)
+		assert.Equal(t, 3, first.Points)
+		assert.InDelta(t, 2000, first.Distance, 2)
+
+		// вторая пачка: отрезок от сохранённой координаты до первой новой тоже учитывается
+		repo.coords = append(repo.coords, coord(3, 3000), coord(4, 4000))
+		require.NoError(t, s.checkShipping(resumed(sh, first)))
+		require.Len(t, repo.saved, 2)
+		assert.Equal(t, first.CheckedAt, repo.from)
+
+		second := repo.saved[1]
+		assert.Equal(t, coord(4, 0).DevTime, second.CheckedAt)
+		assert.Equal(t, 5, second.Points)
+		assert.InDelta(t, 4000, second.Distance, 2)
+		assert.Equal(t, 240, second.MovingTime)
+		require.Len(t, repo.days[1], 1)
+		assert.InDelta(t, 2000, repo.days[1][0].Distance, 2)
+		assert.Equal(t, 120, repo.days[1][0].MovingTime)
+	})
+
+	t.Run("скачки подряд на стыке пачек", func(t *testing.T) {
+		repo := &fakeRepo{coords: []coordinate{coord(0, 0), coord(1, 1000), coord(2, 100000), coord(3, 101000)}}
+		s := &usecase{repo: repo, filter: filter}
+		sh := shippingForCheck{Id: 1, Modem: 2, TimeStart: t0.Add(-time.Minute)}
+
+		require.NoError(t, s.checkShipping(sh))
+		first := repo.saved[0]
+		assert.Equal(t, 2, first.Rejected)
+		assert.Equal(t, 2, first.RejectedTotal)
+		assert.Equal(t, coord(1, 0).DevTime, *first.LastDevTime)
+		assert.Equal(t, coord(3, 0).DevTime, first.CheckedAt)
+
+		// третий скачок в новой пачке подтверждает новое положение
+		repo.coords = append(repo.coords, coord(4, 102000), coord(5, 103000))
+		require.NoError(t, s.checkShipping(resumed(sh, first)))
+
+		second := repo.saved[1]
+		assert.Equal(t, 0, second.Rejected)
+		assert.Equal(t, 2, second.RejectedTotal)
+		assert.Equal(t, 4, second.Points)
+		assert.InDelta(t, 2000, second.Distance, 2)
+		assert.Equal(t, coord(5, 0).DevTime, *second.LastDevTime)
+	})
+
+	t.Run("нет новых координат", func(t *testing.T) {
+		repo := &fakeRepo{}
+		s := &usecase{repo: repo, filter: filter}
+
+		require.NoError(t, s.checkShipping(shippingForCheck{Id: 1, Modem: 2, TimeStart: t0}))
+		assert.Empty(t, repo.saved)
+	})
+
+	t.Run("завершённая перевозка", func(t *testing.T) {
+		end := t0.Add(90 * time.Minute)
+		repo := &fakeRepo{coords: []coordinate{coord(0, 0), coord(1, 1000), coord(120, 2000)}}
+		s := &usecase{repo: repo, filter: filter}
+
+		require.NoError(t, s.checkShipping(shippingForCheck{Id: 1, Modem: 2, TimeStart: t0.Add(-time.Minute), TimeEnd: &end}))
+		require.Len(t, repo.saved, 1)
+		assert.Equal(t, end, repo.saved[0].CheckedAt)
+		assert.Equal(t, 2, repo.saved[0].Points)
+		assert.InDelta(t, 1000, repo.saved[0].Distance, 2)
+	})
+}
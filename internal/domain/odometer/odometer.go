@@ -0,0 +1,64 @@
+package odometer
+
+import "time"
+
+type Odometer struct {
+	Distance   float64 `json:"distance"`
+	MovingTime int     `json:"moving_time"`
+	Points     int     `json:"points"`
+	Rejected   int     `json:"rejected"`
+	Days       []Day   `json:"days"`
+}
+
+type Day struct {
+	Date       string  `json:"date"`
+	Distance   float64 `json:"distance"`
+	MovingTime int     `json:"moving_time" db:"moving_time"`
+}
+
+type ShippingOdometer struct {
+	Odometer
+	PlannedDistance int        `json:"planned_distance"`
+	Difference      float64    `json:"difference"`
+	CheckedAt       *time.Time `json:"checked_at"` // время последней учтённой координаты, nil - расчёт ещё не начат
+}
+
+// Filter пороги отбраковки координат, 0 - не проверять
+type Filter struct {
+	MaxHdop       int
+	MinSatellites int
+	MaxSpeed      int
+}
+
+type coordinate struct {
+	DevTime         time.Time `json:"dev_time" db:"dev_time"`
+	Latitude        float32   `json:"latitude"`
+	Longitude       float32   `json:"longitude"`
+	Hdop            uint8     `json:"hdop"`
+	SatellitesCount int16     `json:"satellites_count" db:"satellites_count"`
+}
+
+type shippingForCheck struct {
+	Id            int        `json:"id"`
+	Modem         int        `json:"modem"`
+	TimeStart     time.Time  `json:"time_start" db:"time_start"`
+	TimeEnd       *time.Time `json:"time_end" db:"time_end"`
+	CheckedAt     *time.Time `json:"checked_at" db:"checked_at"`
+	LastDevTime   *time.Time `json:"last_dev_time" db:"last_dev_time"`
+	LastLatitude  *float32   `json:"last_latitude" db:"last_latitude"`
+	LastLongitude *float32   `json:"last_longitude" db:"last_longitude"`
+	Rejected      int        `json:"rejected"`
+	RejectedTotal int        `json:"rejected_total" db:"rejected_total"`
+	Points        int        `json:"points"`
+	Distance      float64    `json:"distance"`
+	MovingTime    int        `json:"moving_time" db:"moving_time"`
+}
+
+// saved пробег перевозки, досчитанный фоновой проверкой, расстояние в метрах
+type saved struct {
+	CheckedAt     *time.Time `json:"checked_at" db:"checked_at"`
+	Points        int        `json:"points"`
+	RejectedTotal int        `json:"rejected_total" db:"rejected_total"`
+	Distance      float64    `json:"distance"`
+	MovingTime    int        `json:"moving_time" db:"moving_time"`
+}
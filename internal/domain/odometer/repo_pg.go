@@ -0,0 +1,181 @@
+package odometer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+func (r *repo) Coordinates(modemId int, from, to time.Time, limit int) ([]coordinate, error) {
+	q := query.New[coordinate](r.ctx, r.db).
+		Select("c.dev_time", "").
+		AddSelect("c.latitude", "").
+		AddSelect("c.longitude", "").
+		AddSelect("c.hdop", "").
+		AddSelect("c.satellites_count", "").
+		From("coordinates", "c").
+		Where(query.EQUEL, "c.modem", modemId).
+		AndWhere(query.GREAT, "c.dev_time", from).
+		AndFilterWhere(query.LITTLE_OR_EQ, "c.dev_time", to).
+		AndWhere(query.LITTLE_OR_EQ, "c.dev_time", time.Now()).
+		AndWhere(query.IS_NOT_NULL, "nullif(c.latitude, 'NaN')", nil).
+		AndWhere(query.IS_NOT_NULL, "nullif(c.longitude, 'NaN')", nil).
+		OrderBy("c.dev_time").
+		Limit(limit)
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// ListForCheck перевозки в пути и завершённые перевозки, координаты которых ещё не обработаны до конца поездки
+func (r *repo) ListForCheck(limit int) ([]shippingForCheck, error) {
+	q := query.New[shippingForCheck](r.ctx, r.db).
+		Select("s.id", "").
+		AddSelect("s.modem", "").
+		AddSelect("s.time_start", "").
+		AddSelect("s.time_end", "").
+		AddSelect("o.checked_at", "").
+		AddSelect("o.last_dev_time", "").
+		AddSelect("o.last_latitude", "").
+		AddSelect("o.last_longitude", "").
+		AddSelect("coalesce(o.rejected, 0)", "rejected").
+		AddSelect("coalesce(o.rejected_total, 0)", "rejected_total").
+		AddSelect("coalesce(o.points, 0)", "points").
+		AddSelect("coalesce(o.distance, 0)", "distance").
+		AddSelect("coalesce(o.moving_time, 0)", "moving_time").
+		From("shipping", "s").
+		LeftJoin("o", "shipping_odometer", "o.shipping = s.id").
+		Where(query.IS_NOT_NULL, "s.modem", nil).
+		AndWhere(query.IS_NOT_NULL, "s.time_start", nil).
+		AndWhere(query.IN, "s.status", []int{shipping.STATUS_ACTIVE, shipping.STATUS_SUSPENDED, shipping.STATUS_END}).
+		AndWhereNotExists(fmt.Sprintf("select 1 from shipping_odometer od where od.shipping = s.id "+
+			"and s.status = %d and od.checked_at >= s.time_end", shipping.STATUS_END)).
+		OrderBy("o.checked_at nulls first, s.id").
+		Limit(limit)
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Saved досчитанный пробег перевозки, пустой, если расчёт ещё не начат
+func (r *repo) Saved(shippingId int) (saved, error) {
+	q := query.New[saved](r.ctx, r.db).
+		Select("o.checked_at", "").
+		AddSelect("o.points", "").
+		AddSelect("o.rejected_total", "").
+		AddSelect("o.distance", "").
+		AddSelect("o.moving_time", "").
+		From("shipping_odometer", "o").
+		Where(query.EQUEL, "o.shipping", shippingId)
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return saved{}, nil
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Days суточная разбивка пробега перевозки, расстояние в метрах
+func (r *repo) Days(shippingId int) ([]Day, error) {
+	q := query.New[Day](r.ctx, r.db).
+		Select("to_char(d.date, 'YYYY-MM-DD')", "date").
+		AddSelect("d.distance", "").
+		AddSelect("d.moving_time", "").
+		From("shipping_odometer_days", "d").
+		Where(query.EQUEL, "d.shipping", shippingId).
+		OrderBy("d.date")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Save сохраняет досчитанный пробег и прибавляет пробег по суткам из обработанной пачки координат
+func (r *repo) Save(data Db, days []Day) error {
+	var err error
+	var tx pgx.Tx
+
+	if tx, err = r.db.Begin(r.ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(r.ctx)
+		}
+	}()
+
+	q := `INSERT INTO shipping_odometer 
+		(shipping, calculated_at, checked_at, last_dev_time, last_latitude, last_longitude, rejected, rejected_total, points,
+			distance, moving_time)
+		VALUES ($1, now(), $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (shipping) DO UPDATE SET
+			calculated_at = EXCLUDED.calculated_at,
+			checked_at = EXCLUDED.checked_at,
+			last_dev_time = EXCLUDED.last_dev_time,
+			last_latitude = EXCLUDED.last_latitude,
+			last_longitude = EXCLUDED.last_longitude,
+			rejected = EXCLUDED.rejected,
+			rejected_total = EXCLUDED.rejected_total,
+			points = EXCLUDED.points,
+			distance = EXCLUDED.distance,
+			moving_time = EXCLUDED.moving_time
+	`
+
+	qp := []any{data.Shipping, data.CheckedAt, data.LastDevTime, data.LastLatitude, data.LastLongitude,
+		data.Rejected, data.RejectedTotal, data.Points, data.Distance, data.MovingTime}
+
+	if _, err = tx.Exec(r.ctx, q, qp...); err != nil {
+		r.logger.Error(query.NewLogSql(q, qp...).SetError(err).GetMsg())
+		return err
+	}
+
+	qd := `INSERT INTO shipping_odometer_days (shipping, date, distance, moving_time)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (shipping, date) DO UPDATE SET
+			distance = shipping_odometer_days.distance + EXCLUDED.distance,
+			moving_time = shipping_odometer_days.moving_time + EXCLUDED.moving_time
+	`
+
+	for _, day := range days {
+		qdp := []any{data.Shipping, day.Date, day.Distance, day.MovingTime}
+
+		if _, err = tx.Exec(r.ctx, qd, qdp...); err != nil {
+			r.logger.Error(query.NewLogSql(qd, qdp...).SetError(err).GetMsg())
+			return err
+		}
+	}
+
+	if err = tx.Commit(r.ctx); err != nil {
+		return err
+	}
+
+	r.logger.Debug(query.NewLogSql(q, qp...).SetResult(len(days)).GetMsg())
+
+	return nil
+}
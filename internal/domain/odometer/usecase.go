@@ -0,0 +1,181 @@
+package odometer
+
+import (
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/modem"
+	"seal/internal/domain/shipping"
+	"seal/pkg/app_error"
+	"time"
+)
+
+type CoreUseCase struct {
+	Shipping shipping.Usecase
+	Modem    modem.Usecase
+}
+
+type usecase struct {
+	repo      Repo
+	logger    app_interface.Logger
+	validator app_interface.Validator
+	filter    Filter
+	usecase   CoreUseCase
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, filter Filter, coreUsecase CoreUseCase) Usecase {
+	return &usecase{repo, logger, validator, filter, coreUsecase}
+}
+
+func (s *usecase) ByModem(params QueryParams) (Odometer, error) {
+	if errs := s.validator.Struct(params); errs != nil {
+		return Odometer{}, app_error.ValidationError(errs)
+	}
+
+	if params.From.IsZero() {
+		return Odometer{}, app_error.ValidationError(map[string]string{"from": "Не указано начало периода"})
+	}
+
+	if _, err := s.usecase.Modem.GetDbById(params.Id); err != nil {
+		return Odometer{}, err
+	}
+
+	coords, err := s.repo.Coordinates(params.Id, params.From, params.To, MAX_COORDINATES)
+	if err != nil {
+		return Odometer{}, err
+	}
+
+	return measure(coords, s.filter), nil
+}
+
+// ByShipping пробег перевозки с разбивкой по суткам в сравнении с длиной маршрута.
+// Отдаётся пробег, досчитанный фоновой проверкой (Check), координаты заново не читаются
+func (s *usecase) ByShipping(id int) (ShippingOdometer, error) {
+	sh, err := s.usecase.Shipping.GetById(id)
+	if err != nil {
+		return ShippingOdometer{}, err
+	}
+
+	if sh.Modem == nil || sh.TimeStart == nil {
+		return ShippingOdometer{}, app_error.ValidationError(`Поездка не начата`)
+	}
+
+	st, err := s.repo.Saved(id)
+	if err != nil {
+		return ShippingOdometer{}, err
+	}
+
+	days, err := s.repo.Days(id)
+	if err != nil {
+		return ShippingOdometer{}, err
+	}
+
+	if days == nil {
+		days = []Day{}
+	}
+
+	for i := range days {
+		days[i].Distance = kilometers(days[i].Distance)
+	}
+
+	result := ShippingOdometer{
+		Odometer: Odometer{
+			Distance:   kilometers(st.Distance),
+			MovingTime: st.MovingTime,
+			Points:     st.Points,
+			Rejected:   st.RejectedTotal,
+			Days:       days,
+		},
+		PlannedDistance: sh.Route.Length,
+		CheckedAt:       st.CheckedAt,
+	}
+	result.Difference = kilometers(st.Distance - float64(result.PlannedDistance)*1000)
+
+	return result, nil
+}
+
+// Check досчитывает пробег перевозок по новым координатам
+func (s *usecase) Check() error {
+	list, err := s.repo.ListForCheck(CHECK_SHIPPING_LIMIT)
+	if err != nil {
+		return err
+	}
+
+	for _, sh := range list {
+		if err := s.checkShipping(sh); err != nil {
+			s.logger.Error(fmt.Sprintf("Ошибка расчёта пробега перевозки %d: %v", sh.Id, err))
+		}
+	}
+
+	return nil
+}
+
+func (s *usecase) checkShipping(sh shippingForCheck) error {
+	from := sh.TimeStart
+	if sh.CheckedAt != nil && sh.CheckedAt.After(from) {
+		from = *sh.CheckedAt
+	}
+
+	var to time.Time
+	if sh.TimeEnd != nil {
+		to = *sh.TimeEnd
+	}
+
+	coords, err := s.repo.Coordinates(sh.Modem, from, to, CHECK_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+
+	// координаты завершённой перевозки обработаны до конца поездки
+	finished := sh.TimeEnd != nil && len(coords) < CHECK_BATCH_SIZE
+	if len(coords) == 0 && !finished {
+		return nil
+	}
+
+	st := state{rejected: sh.Rejected, distance: sh.Distance, moving: sh.MovingTime}
+	if sh.LastDevTime != nil && sh.LastLatitude != nil && sh.LastLongitude != nil {
+		st.last = &coordinate{DevTime: *sh.LastDevTime, Latitude: *sh.LastLatitude, Longitude: *sh.LastLongitude}
+	}
+
+	data := Db{
+		Shipping:      sh.Id,
+		CheckedAt:     from,
+		RejectedTotal: sh.RejectedTotal,
+		Points:        sh.Points,
+	}
+
+	// пробег пачки по суткам, в метрах, прибавляется к сохранённому
+	var days []Day
+	for _, c := range coords {
+		distance, moving, ok := st.add(c, s.filter)
+		if !ok {
+			data.RejectedTotal++
+			continue
+		}
+
+		data.Points++
+
+		date := c.DevTime.In(time.Local).Format(time.DateOnly)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, Day{Date: date})
+		}
+
+		days[len(days)-1].Distance += distance
+		days[len(days)-1].MovingTime += moving
+	}
+
+	data.Rejected, data.Distance, data.MovingTime = st.rejected, st.distance, st.moving
+
+	if len(coords) > 0 {
+		data.CheckedAt = coords[len(coords)-1].DevTime
+	}
+
+	if finished {
+		data.CheckedAt = *sh.TimeEnd
+	}
+
+	if st.last != nil {
+		data.LastDevTime, data.LastLatitude, data.LastLongitude = &st.last.DevTime, &st.last.Latitude, &st.last.Longitude
+	}
+
+	return s.repo.Save(data, days)
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"seal/pkg/track_export"
+	"strings"
+)
+
+const MAP_WIDTH = 800
+const MAP_HEIGHT = 500
+
+// MAP_MAX_POINTS максимальное количество точек одной линии на карте, остальные прореживаются
+const MAP_MAX_POINTS = 2000
+
+const mapPadding = 20
+
+type bounds struct {
+	minLat, maxLat, minLon, maxLon float64
+}
+
+func (b *bounds) extend(p track_export.Point) {
+	b.minLat = math.Min(b.minLat, p.Latitude)
+	b.maxLat = math.Max(b.maxLat, p.Latitude)
+	b.minLon = math.Min(b.minLon, p.Longitude)
+	b.maxLon = math.Max(b.maxLon, p.Longitude)
+}
+
+// renderMap рисует на сервере схему трека и планового маршрута в SVG (равнопромежуточная проекция
+// с поправкой на широту), чтобы отчёт не зависел от внешних картографических сервисов
+func renderMap(track track_export.Track, width, height int) template.HTML {
+	b := bounds{math.MaxFloat64, -math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64}
+	empty := true
+
+	for _, p := range track.Route {
+		b.extend(p)
+		empty = false
+	}
+	for _, segment := range track.Segments {
+		for _, p := range segment {
+			b.extend(p)
+			empty = false
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	sb.WriteString(`<rect width="100%" height="100%" fill="#f7f7f2" stroke="#ccc"/>`)
+
+	if empty {
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle" fill="#888">Нет координат</text></svg>`, width/2, height/2)
+		return template.HTML(sb.String())
+	}
+
+	kx := math.Cos((b.minLat + b.maxLat) / 2 * math.Pi / 180)
+	spanX := math.Max((b.maxLon-b.minLon)*kx, 1e-6)
+	spanY := math.Max(b.maxLat-b.minLat, 1e-6)
+	scale := math.Min(float64(width-2*mapPadding)/spanX, float64(height-2*mapPadding)/spanY)
+	offsetX := (float64(width) - spanX*scale) / 2
+	offsetY := (float64(height) - spanY*scale) / 2
+
+	project := func(p track_export.Point) (float64, float64) {
+		return offsetX + (p.Longitude-b.minLon)*kx*scale, offsetY + (b.maxLat-p.Latitude)*scale
+	}
+
+	polyline := func(points []track_export.Point, style string) {
+		step := len(points)/MAP_MAX_POINTS + 1
+
+		sb.WriteString(`<polyline fill="none" ` + style + ` points="`)
+		for i := 0; i < len(points); i += step {
+			x, y := project(points[i])
+			fmt.Fprintf(&sb, "%.1f,%.1f ", x, y)
+		}
+		if (len(points)-1)%step != 0 {
+			x, y := project(points[len(points)-1])
+			fmt.Fprintf(&sb, "%.1f,%.1f", x, y)
+		}
+		sb.WriteString(`"/>`)
+	}
+
+	if len(track.Route) > 0 {
+		polyline(track.Route, `stroke="#999" stroke-width="4" stroke-dasharray="8 6"`)
+	}
+
+	for _, segment := range track.Segments {
+		polyline(segment, `stroke="#1f5fbf" stroke-width="2"`)
+	}
+
+	if len(track.Segments) > 0 {
+		first := track.Segments[0][0]
+		lastSegment := track.Segments[len(track.Segments)-1]
+		last := lastSegment[len(lastSegment)-1]
+
+		x, y := project(first)
+		fmt.Fprintf(&sb, `<circle cx="%.1f" cy="%.1f" r="6" fill="#2a9d3a"><title>Начало</title></circle>`, x, y)
+		x, y = project(last)
+		fmt.Fprintf(&sb, `<circle cx="%.1f" cy="%.1f" r="6" fill="#c0392b"><title>Окончание</title></circle>`, x, y)
+	}
+
+	sb.WriteString(`</svg>`)
+
+	return template.HTML(sb.String())
+}
@@ -0,0 +1,24 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"seal/internal/domain/deviation"
+	"seal/internal/domain/shipping"
+	"time"
+)
+
+// ShippingReport данные отчёта о поездке
+type ShippingReport struct {
+	Shipping   shipping.Shipping
+	Status     string
+	History    []shipping.History
+	Deviations []deviation.Deviation
+	Map        template.HTML
+	CreatedAt  time.Time
+}
+
+type Usecase interface {
+	Shipping(id int) (ShippingReport, error)
+	Write(w io.Writer, report ShippingReport) error
+}
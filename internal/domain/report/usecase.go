@@ -0,0 +1,107 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/deviation"
+	"seal/internal/domain/seal"
+	"seal/internal/domain/shipping"
+	"seal/pkg/app_error"
+	"seal/pkg/track_export"
+	"time"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+type CoreUseCase struct {
+	Shipping  shipping.Usecase
+	Deviation deviation.Usecase
+}
+
+type usecase struct {
+	logger    app_interface.Logger
+	validator app_interface.Validator
+	usecase   CoreUseCase
+	template  *template.Template
+}
+
+func NewUsecase(logger app_interface.Logger, validator app_interface.Validator, coreUsecase CoreUseCase) Usecase {
+	tpl := template.Must(template.New("shipping.html").Funcs(funcs).ParseFS(templates, "templates/*.html"))
+
+	return &usecase{logger, validator, coreUsecase, tpl}
+}
+
+// Shipping собирает отчёт о завершённой поездке из данных перевозки, истории статусов,
+// отклонений от маршрута и трека
+func (s *usecase) Shipping(id int) (ShippingReport, error) {
+	item, err := s.usecase.Shipping.GetById(id)
+	if err != nil {
+		return ShippingReport{}, err
+	}
+
+	if item.Status != shipping.STATUS_END {
+		return ShippingReport{}, app_error.ValidationError(`Отчёт формируется только для завершённой перевозки`)
+	}
+
+	report := ShippingReport{
+		Shipping:  item,
+		Status:    shipping.StatusTitle(item.Status),
+		CreatedAt: time.Now(),
+	}
+
+	if report.History, err = s.usecase.Shipping.History(id); err != nil {
+		return ShippingReport{}, err
+	}
+
+	if report.Deviations, err = s.usecase.Deviation.ListByShipping(id); err != nil {
+		return ShippingReport{}, err
+	}
+
+	var track track_export.Track
+	if item.Modem != nil {
+		if track, err = s.usecase.Shipping.ExportTrack(shipping.TrackQueryParams{Id: id}); err != nil {
+			return ShippingReport{}, err
+		}
+	}
+
+	report.Map = renderMap(track, MAP_WIDTH, MAP_HEIGHT)
+
+	return report, nil
+}
+
+func (s *usecase) Write(w io.Writer, report ShippingReport) error {
+	return s.template.Execute(w, report)
+}
+
+var funcs = template.FuncMap{
+	"datetime": func(t any) string {
+		switch v := t.(type) {
+		case time.Time:
+			return v.Local().Format("02.01.2006 15:04:05")
+		case *time.Time:
+			if v == nil {
+				return "—"
+			}
+			return v.Local().Format("02.01.2006 15:04:05")
+		}
+		return ""
+	},
+	"duration": func(seconds int) string {
+		d := time.Duration(seconds) * time.Second
+		return fmt.Sprintf("%d ч %02d мин", int(d.Hours()), int(d.Minutes())%60)
+	},
+	"status": shipping.StatusTitle,
+	"sealState": func(status int32) string {
+		switch {
+		case int64(status)&seal.STATUS_CABLE_CUT != 0:
+			return "Трос перерезан"
+		case int64(status)&seal.STATUS_CASE_OPENED != 0:
+			return "Корпус вскрыт"
+		}
+		return "Норма"
+	},
+}
@@ -10,6 +10,7 @@ type Route struct {
 	CreatedAt  *time.Time   `json:"created_at" db:"created_at"`
 	Coords     [][2]float32 `json:"coords" db:"coords"`
 	TravelTime int          `json:"travel_time" db:"travel_time"`
+	Corridor   int          `json:"corridor"`
 }
 
 func (r Route) getId() int {
@@ -26,6 +27,7 @@ type CreateRequest struct {
 	Length     int          `json:"length" validate:"max=32767,min=0"`
 	Coords     [][2]float32 `json:"coords" db:"coords"`
 	TravelTime int          `json:"travel_time"`
+	Corridor   int          `json:"corridor" validate:"max=32767,min=0"`
 }
 
 type UpdateRequest struct {
@@ -33,4 +35,5 @@ type UpdateRequest struct {
 	Points     []string `json:"points,omitempty"`
 	Length     *int     `json:"length,omitempty"`
 	TravelTime *int     `json:"travel_time,omitempty"`
+	Corridor   *int     `json:"corridor,omitempty"`
 }
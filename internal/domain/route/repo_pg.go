@@ -2,14 +2,17 @@ package route
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	app_interface "seal/internal/app/interface"
 	"seal/internal/repository/pg"
 	"seal/internal/repository/pg/query"
 	"seal/internal/transport"
 	"seal/pkg/app_error"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type repo struct {
@@ -36,12 +39,12 @@ func (r *repo) Create(route Route) (Route, error) {
 	}()
 
 	q := `INSERT INTO routes 
-		(title, points, length, travel_time)
-		VALUES ($1, $2, $3, $4)
+		(title, points, length, travel_time, corridor)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id
 	`
 
-	qp := []any{route.Title, route.Points, route.Length, route.TravelTime}
+	qp := []any{route.Title, route.Points, route.Length, route.TravelTime, route.Corridor}
 
 	logSql := query.NewLogSql(q, qp...)
 
@@ -66,12 +69,13 @@ func (r *repo) Update(route Db) (Route, error) {
 		set title = $2,
 		    points = $3,
 		    length = $4,
-			travel_time = $5
+			travel_time = $5,
+			corridor = $6
 		where id = $1
 		returning id
 	`
 
-	qp := []any{route.Id, route.Title, route.Points, route.Length, route.TravelTime}
+	qp := []any{route.Id, route.Title, route.Points, route.Length, route.TravelTime, route.Corridor}
 
 	logSql := query.NewLogSql(q, qp...)
 
@@ -108,6 +112,94 @@ func (r *repo) insertCoords(route Route, tx pgx.Tx) error {
 	return nil
 }
 
+func (r *repo) GetPoints(id int) (Points, error) {
+	q := `select r.id,
+			coalesce(r.custom_departure, 0),
+			coalesce(r.custom_destination, 0),
+			coalesce((select jsonb_agg(jsonb_build_object('latitude', latitude, 'longitude', longitude) order by number)
+				from route_points where route = r.id), '[]'::jsonb)
+		from routes r
+		where r.id = $1
+	`
+
+	var points Points
+	var coords []byte
+
+	err := r.db.QueryRow(r.ctx, q, id).Scan(&points.Route, &points.CustomDeparture, &points.CustomDestination, &coords)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return points, app_error.ErrNotFound
+	}
+
+	if err == nil {
+		err = json.Unmarshal(coords, &points.Coords)
+	}
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, id).SetResult(points).SetError(err).GetMsg())
+
+	return points, err
+}
+
+// UpdatePoints сохраняет таможни маршрута (0 - не задана), точки заменяются только если переданы
+func (r *repo) UpdatePoints(points Points) error {
+	var err error
+	var tx pgx.Tx
+
+	if tx, err = r.db.Begin(r.ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(r.ctx)
+		}
+	}()
+
+	q := `UPDATE routes
+		set custom_departure = nullif($2::int4, 0),
+			custom_destination = nullif($3::int4, 0)
+		where id = $1
+	`
+
+	qp := []any{points.Route, points.CustomDeparture, points.CustomDestination}
+
+	var commandTag pgconn.CommandTag
+	commandTag, err = tx.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(commandTag.RowsAffected()).SetError(err).GetMsg())
+
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		err = app_error.ErrNotFound
+		return err
+	}
+
+	if len(points.Coords) > 0 {
+		q = `DELETE FROM route_points where route = $1`
+
+		_, err = tx.Exec(r.ctx, q, points.Route)
+		r.logger.DebugOrError(err, query.NewLogSql(q, points.Route).SetError(err).GetMsg())
+
+		if err != nil {
+			return err
+		}
+
+		route := Route{Id: points.Route}
+		for _, coord := range points.Coords {
+			route.Coords = append(route.Coords, [2]float32{coord.Latitude, coord.Longitude})
+		}
+
+		if err = r.insertCoords(route, tx); err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit(r.ctx)
+
+	return err
+}
+
 func (r *repo) GetById(id int) (Route, error) {
 	q := query.New[Route](r.ctx, r.db).
 		Select("r.id", "").
@@ -116,6 +208,7 @@ func (r *repo) GetById(id int) (Route, error) {
 		AddSelect("r.length", "").
 		AddSelect("r.created_at", "").
 		AddSelect("r.travel_time", "").
+		AddSelect("r.corridor", "").
 		AddSelect("(select jsonb_agg(t.d) from (select jsonb_build_array(latitude, longitude) as d from route_points where route = r.id order by number) t)", "coords").
 		From("routes", "r").
 		Where(query.EQUEL, "id", id)
@@ -148,6 +241,23 @@ func (r *repo) GetDbById(id int) (Db, error) {
 	return data, err
 }
 
+func (r *repo) GetDbByTitle(title string) (Db, error) {
+	q := query.New[Db](r.ctx, r.db).
+		Select("r.*", "").
+		From("routes", "r").
+		Where(query.EQUEL, "lower(r.title)", strings.ToLower(title))
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
 func (r *repo) List(params transport.QueryParams) (query.List[Route], error) {
 	q := query.New[Route](r.ctx, r.db).
 		Select("r.id", "").
@@ -156,6 +266,7 @@ func (r *repo) List(params transport.QueryParams) (query.List[Route], error) {
 		AddSelect("r.points", "").
 		AddSelect("r.length", "").
 		AddSelect("r.travel_time", "").
+		AddSelect("r.corridor", "").
 		AddSelect("null", "coords").
 		From("routes", "r").
 		FilterWhere(params.FindType, "title", params.Find).
@@ -10,11 +10,15 @@ type Db struct {
 	Id         int       `json:"id"`
 	Title      string    `json:"title" validate:"required"`
 	Points     []string  `json:"points" validate:"required"`
-	Length     int       `json:"length" validate:"max=32767,min=0"`
+	Length     int       `json:"length" validate:"max=32767,min=0"` // в километрах
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 	TravelTime int       `json:"travel_time" db:"travel_time"`
+	Corridor   int       `json:"corridor" validate:"max=32767,min=0"`
 }
 
+// DEFAULT_CORRIDOR допустимое отклонение от маршрута (в метрах), если не задано при создании
+const DEFAULT_CORRIDOR = 500
+
 func (r Db) getId() int {
 	return r.Id
 }
@@ -28,10 +32,13 @@ type Repo interface {
 	Update(data Db) (Route, error)
 	GetById(id int) (Route, error)
 	GetDbById(id int) (Db, error)
+	GetDbByTitle(title string) (Db, error)
 	List(params transport.QueryParams) (query.List[Route], error)
 	Exists(id int) (bool, error)
 	ExistsByUnique(id int, title string) (bool, error)
 	DeleteById(id int) (bool, error)
+	GetPoints(id int) (Points, error)
+	UpdatePoints(data Points) error
 }
 
 type Usecase interface {
@@ -39,8 +46,11 @@ type Usecase interface {
 	Update(id int, data UpdateRequest) (Route, error)
 	GetById(id int) (Route, error)
 	GetDbById(id int) (Db, error)
+	GetDbByTitle(title string) (Db, error)
 	List(params transport.QueryParams) (query.List[Route], error)
 	Exists(id int) (bool, error)
 	ExistsByUnique(id int, title string) (bool, error)
 	DeleteById(id int) (bool, error)
+	GetPoints(id int) (Points, error)
+	UpdatePoints(id int, data Points) (Points, error)
 }
@@ -27,6 +27,10 @@ func (s *usecase) Create(data CreateRequest) (Route, error) {
 		return Route{}, app_error.InternalServerError(err)
 	}
 
+	if route.Corridor == 0 {
+		route.Corridor = DEFAULT_CORRIDOR
+	}
+
 	if errs, err := validate[Route](s, route); err != nil {
 		return Route{}, err
 	} else if len(errs) > 0 {
@@ -64,6 +68,10 @@ func (s *usecase) GetDbById(id int) (Db, error) {
 	return s.repo.GetDbById(id)
 }
 
+func (s *usecase) GetDbByTitle(title string) (Db, error) {
+	return s.repo.GetDbByTitle(title)
+}
+
 func (s *usecase) List(queryParams transport.QueryParams) (query.List[Route], error) {
 	if errs := s.validator.Struct(queryParams); errs != nil {
 		return query.List[Route]{}, app_error.ValidationError(errs)
@@ -83,3 +91,23 @@ func (s *usecase) ExistsByUnique(id int, title string) (bool, error) {
 func (s *usecase) DeleteById(id int) (bool, error) {
 	return s.repo.DeleteById(id)
 }
+
+func (s *usecase) GetPoints(id int) (Points, error) {
+	return s.repo.GetPoints(id)
+}
+
+func (s *usecase) UpdatePoints(id int, data Points) (Points, error) {
+	data.Route = id
+
+	if errs, err := s.validatePoints(data); err != nil {
+		return Points{}, err
+	} else if len(errs) > 0 {
+		return Points{}, app_error.ValidationError(errs)
+	}
+
+	if err := s.repo.UpdatePoints(data); err != nil {
+		return Points{}, err
+	}
+
+	return s.repo.GetPoints(id)
+}
@@ -1,6 +1,7 @@
 package route
 
 import (
+	"fmt"
 	"seal/internal/domain"
 	"sync"
 )
@@ -51,3 +52,32 @@ func existsByUnique[T valid](s *usecase, wg *sync.WaitGroup, ch chan domain.Res,
 		ch <- domain.Res{Errs: map[string]string{"title": "Не уникально"}, Err: nil}
 	}
 }
+
+// validatePoints проверка точек маршрута: координаты и существование таможен, 0 - таможня не задана
+func (s *usecase) validatePoints(points Points) (map[string]string, error) {
+	if errs := s.validator.Struct(points); errs != nil {
+		s.logger.Debug("Ошибки валидации", errs)
+		return errs, nil
+	}
+
+	errs := map[string]string{}
+	customs := map[string]int{"custom_departure": points.CustomDeparture, "custom_destination": points.CustomDestination}
+
+	for field, id := range customs {
+		if id == 0 {
+			continue
+		}
+
+		if exists, err := s.customUsecase.Exists(id); err != nil {
+			return nil, err
+		} else if !exists {
+			errs[field] = fmt.Sprintf("Таможня %d не существует", id)
+		}
+	}
+
+	if len(errs) > 0 {
+		s.logger.Debug("Ошибки валидации", errs)
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"seal/internal/domain/command"
+	"time"
+)
+
+const KIND_ONCE = "once"
+const KIND_CRON = "cron"
+const KIND_SHIPPING_START = "shipping_start"
+const KIND_SHIPPING_END = "shipping_end"
+
+const RUN_SENT = 1
+const RUN_FAILED = 2
+
+// CHECK_BATCH запланированных отправок за одну проверку
+const CHECK_BATCH = 20
+
+// LOCK_TTL сколько отправка захвачена экземпляром API, потом её может взять другой
+const LOCK_TTL = 5 * time.Minute
+
+// MAX_ATTEMPTS попыток отправки, пока сервис команд недоступен, между ними RETRY_DELAY
+const MAX_ATTEMPTS = 3
+const RETRY_DELAY = time.Minute
+
+// RUNS_IN_SCHEDULE последних отправок в ответе
+const RUNS_IN_SCHEDULE = 10
+
+type CreateRequest struct {
+	Name   string     `json:"name" validate:"required,max=50,min=5"`
+	Params any        `json:"params" validate:"required"`
+	Kind   string     `json:"kind" validate:"required,oneof=once cron shipping_start shipping_end"`
+	RunAt  *time.Time `json:"run_at"`                  // для once
+	Cron   string     `json:"cron" validate:"max=100"` // для cron, 5 полей, часовой пояс - префиксом CRON_TZ=Europe/Moscow
+}
+
+type Repo interface {
+	Device(modemId int) (device, error)
+	Create(data Db) (int, error)
+	GetById(id int) (Schedule, error)
+	ListByModem(modemId int) ([]Schedule, error)
+	Delete(id int) error
+	Due(limit int) ([]due, error)
+	Triggered(limit int) ([]due, error)
+	Finish(d due, run Run, next *time.Time, active bool, attempts int) error
+}
+
+type Usecase interface {
+	Create(modemId int, data CreateRequest, userId int, role int) (Schedule, error)
+	ListByModem(modemId int) ([]Schedule, error)
+	Delete(modemId int, id int) error
+	Check() error
+}
+
+// sender отправка команды модему, см. commands.grpcClient
+type sender interface {
+	Send(imei string, name string, params any, author string) (bool, error)
+}
+
+type CoreUseCase struct {
+	Command  command.Usecase
+	Commands sender
+}
@@ -0,0 +1,228 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"seal/pkg/app_error"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+// qDueReturning поля захваченной отправки, sc - расписание, m - модем
+const qDueReturning = `sc.id, sc.modem, m.imei::text as imei, coalesce(m.extra->>'hardware_revision', '') as hardware_revision,
+	sc.name, sc.params, sc.kind, sc.cron, sc.attempts, coalesce((select u.login from users u where u.id = sc.author), '') as author,
+	coalesce((select u.role from users u where u.id = sc.author), -1) as role`
+
+func (r *repo) Device(modemId int) (device, error) {
+	q := query.New[device](r.ctx, r.db).
+		Select("m.id", "").
+		AddSelect("m.imei::text", "imei").
+		AddSelect("coalesce(m.extra->>'hardware_revision', '')", "hardware_revision").
+		From("modems", "m").
+		Where(query.EQUEL, "m.id", modemId)
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) Create(sc Db) (int, error) {
+	q := `INSERT INTO command_schedules
+		(author, modem, name, params, kind, run_at, cron, next_run_at, last_event_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	// строка в параметрах команды - тоже json, поэтому кодируется явно
+	params, err := json.Marshal(sc.Params)
+	if err != nil {
+		return 0, err
+	}
+
+	qp := []any{sc.Author, sc.Modem, sc.Name, params, sc.Kind, sc.RunAt, sc.Cron, sc.NextRunAt, sc.LastEventAt}
+
+	err = r.db.QueryRow(r.ctx, q, qp...).Scan(&sc.Id)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(sc.Id).SetError(err).GetMsg())
+
+	return sc.Id, err
+}
+
+// scheduleSelect поля Schedule с автором и последними отправками
+var scheduleSelect = [][2]string{
+	{"sc.created_at", ""},
+	{"case when ua.id is null then null else jsonb_build_object('id', ua.id, 'login', ua.login) end", "author"},
+	{"sc.modem", ""},
+	{"sc.name", ""},
+	{"sc.params", ""},
+	{"sc.kind", ""},
+	{"sc.run_at", ""},
+	{"sc.cron", ""},
+	{"sc.next_run_at", ""},
+	{"sc.attempts", ""},
+	{"sc.active", ""},
+	{fmt.Sprintf("(select coalesce(jsonb_agg(t order by t.run_at desc), '[]') from (select r.id, r.run_at, r.shipping, r.status, r.error "+
+		"from command_schedule_runs r where r.schedule = sc.id order by r.run_at desc limit %d) t)", RUNS_IN_SCHEDULE), "runs"},
+}
+
+func (r *repo) GetById(id int) (Schedule, error) {
+	q := query.New[Schedule](r.ctx, r.db).
+		Select("sc.id", "")
+	for _, field := range scheduleSelect {
+		q.AddSelect(field[0], field[1])
+	}
+
+	q.From("command_schedules", "sc").
+		LeftJoin("ua", "users", "ua.id = sc.author").
+		Where(query.EQUEL, "sc.id", id)
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data.Id).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) ListByModem(modemId int) ([]Schedule, error) {
+	q := query.New[Schedule](r.ctx, r.db).
+		Select("sc.id", "")
+	for _, field := range scheduleSelect {
+		q.AddSelect(field[0], field[1])
+	}
+
+	q.From("command_schedules", "sc").
+		LeftJoin("ua", "users", "ua.id = sc.author").
+		Where(query.EQUEL, "sc.modem", modemId).
+		OrderBy("sc.id")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) Delete(id int) error {
+	q := `DELETE FROM command_schedules WHERE id = $1`
+
+	_, err := r.db.Exec(r.ctx, q, id)
+	r.logger.DebugOrError(err, query.NewLogSql(q, id).SetError(err).GetMsg())
+
+	return err
+}
+
+// Due захватывает отправки, время которых пришло: once, cron и повторы shipping_start/shipping_end.
+// SKIP LOCKED и locked_until не дают двум экземплярам API отправить одно и то же
+func (r *repo) Due(limit int) ([]due, error) {
+	q := fmt.Sprintf(`UPDATE command_schedules sc
+		SET locked_until = now() + make_interval(secs => $2)
+		FROM modems m
+		WHERE m.id = sc.modem AND sc.id IN (
+			SELECT id FROM command_schedules
+			WHERE active AND next_run_at <= now()
+				AND (locked_until IS NULL OR locked_until < now())
+			ORDER BY next_run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s, sc.retry_shipping as shipping
+	`, qDueReturning)
+
+	return r.claim(q, limit, LOCK_TTL.Seconds())
+}
+
+// Triggered захватывает по одному необработанному началу или завершению перевозки модема
+// на расписание, сдвигая last_event_at, поэтому каждое событие отправляется один раз.
+// Пока предыдущее событие ждёт повтора (next_run_at), следующие не берутся
+func (r *repo) Triggered(limit int) ([]due, error) {
+	event := fmt.Sprintf("(case when t.kind = '%s' then s.time_start else s.time_end end)", KIND_SHIPPING_START)
+
+	q := fmt.Sprintf(`UPDATE command_schedules sc
+		SET last_event_at = e.event_at
+		FROM (
+			SELECT t.id, ev.shipping, ev.event_at
+			FROM command_schedules t
+			CROSS JOIN LATERAL (
+				SELECT s.id as shipping, %[1]s as event_at
+				FROM shipping s
+				WHERE s.modem = t.modem AND (t.kind = '%[2]s' OR s.status = %[5]d)
+					AND %[1]s > t.last_event_at AND %[1]s <= now()
+				ORDER BY 2
+				LIMIT 1
+			) ev
+			WHERE t.active AND t.kind IN ('%[2]s', '%[3]s') AND t.next_run_at IS NULL
+			ORDER BY ev.event_at
+			LIMIT $1
+			FOR UPDATE OF t SKIP LOCKED
+		) e, modems m
+		WHERE sc.id = e.id AND m.id = sc.modem
+		RETURNING %[4]s, e.shipping
+	`, event, KIND_SHIPPING_START, KIND_SHIPPING_END, qDueReturning, shipping.STATUS_END)
+
+	return r.claim(q, limit)
+}
+
+func (r *repo) claim(q string, qp ...any) ([]due, error) {
+	rows, err := r.db.Query(r.ctx, q, qp...)
+	if err != nil {
+		r.logger.Error(query.NewLogSql(q, qp...).SetError(err).GetMsg())
+		return nil, err
+	}
+
+	data, err := pgx.CollectRows(rows, pgx.RowToStructByName[due])
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Finish записывает результат отправки и следующее время, снимая захват. Для shipping_start/shipping_end
+// next - время повтора, перевозка запоминается в retry_shipping
+func (r *repo) Finish(d due, run Run, next *time.Time, active bool, attempts int) error {
+	q := `WITH r AS (
+			INSERT INTO command_schedule_runs (schedule, shipping, status, error)
+			VALUES ($1, $2, $3, $4)
+		)
+		UPDATE command_schedules
+		SET next_run_at = $5, active = $6, attempts = $7, locked_until = null,
+			retry_shipping = case when $5::timestamptz is not null then $2::int4 end
+		WHERE id = $1
+	`
+
+	var errJson []byte
+	if run.Error != nil {
+		errJson, _ = json.Marshal(run.Error)
+	}
+
+	qp := []any{d.Id, d.Shipping, run.Status, errJson, next, active, attempts}
+
+	_, err := r.db.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetError(err).GetMsg())
+
+	return err
+}
@@ -0,0 +1,67 @@
+package schedule
+
+import (
+	"seal/internal/domain/user"
+	"time"
+)
+
+type Db struct {
+	Id          int        `json:"id"`
+	Author      *int       `json:"author"`
+	Modem       int        `json:"modem"`
+	Name        string     `json:"name"`
+	Params      any        `json:"params"`
+	Kind        string     `json:"kind"`
+	RunAt       *time.Time `json:"run_at" db:"run_at"`
+	Cron        *string    `json:"cron"`
+	NextRunAt   *time.Time `json:"next_run_at" db:"next_run_at"`
+	LastEventAt *time.Time `json:"last_event_at" db:"last_event_at"`
+}
+
+// Schedule запланированная команда с последними отправками
+type Schedule struct {
+	Id        int          `json:"id"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	Author    *user.Author `json:"author"`
+	Modem     int          `json:"modem"`
+	Name      string       `json:"name"`
+	Params    any          `json:"params"`
+	Kind      string       `json:"kind"`
+	RunAt     *time.Time   `json:"run_at" db:"run_at"`
+	Cron      *string      `json:"cron"`
+	NextRunAt *time.Time   `json:"next_run_at" db:"next_run_at"`
+	Attempts  int          `json:"attempts"`
+	Active    bool         `json:"active"`
+	Runs      []Run        `json:"runs"`
+}
+
+// Run результат отправки, Shipping - перевозка, начало или завершение которой вызвало отправку
+type Run struct {
+	Id       int       `json:"id"`
+	RunAt    time.Time `json:"run_at" db:"run_at"`
+	Shipping *int      `json:"shipping"`
+	Status   int       `json:"status"`
+	Error    any       `json:"error"`
+}
+
+type device struct {
+	Id               int    `db:"id"`
+	Imei             string `db:"imei"`
+	HardwareRevision string `db:"hardware_revision"`
+}
+
+// due отправка, захваченная для выполнения
+type due struct {
+	Id               int     `db:"id"`
+	Modem            int     `db:"modem"`
+	Imei             string  `db:"imei"`
+	HardwareRevision string  `db:"hardware_revision"`
+	Name             string  `db:"name"`
+	Params           any     `db:"params"`
+	Kind             string  `db:"kind"`
+	Cron             *string `db:"cron"`
+	Attempts         int     `db:"attempts"`
+	Author           string  `db:"author"`
+	Role             int     `db:"role"`
+	Shipping         *int    `db:"shipping"`
+}
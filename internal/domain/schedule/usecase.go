@@ -0,0 +1,194 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/pkg/app_error"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+type usecase struct {
+	repo      Repo
+	logger    app_interface.Logger
+	validator app_interface.Validator
+	usecase   CoreUseCase
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, coreUsecase CoreUseCase) Usecase {
+	return &usecase{repo, logger, validator, coreUsecase}
+}
+
+// Create планирует команду модему. Планировать можно только команды из каталога: роль автора,
+// параметры и модель модема проверяются сейчас и повторно при отправке
+func (s *usecase) Create(modemId int, data CreateRequest, userId int, role int) (Schedule, error) {
+	if errs := s.validator.Struct(data); errs != nil {
+		return Schedule{}, app_error.ValidationError(errs)
+	}
+
+	device, err := s.repo.Device(modemId)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	template, err := s.usecase.Command.Check(data.Name, data.Params, role)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	if template == nil {
+		return Schedule{}, app_error.ValidationError(map[string]string{"name": "Команды нет в каталоге"})
+	}
+
+	if !template.Supports(device.HardwareRevision) {
+		return Schedule{}, app_error.ValidationError(map[string]string{"name": "Команда не поддерживается моделью модема"})
+	}
+
+	now := time.Now()
+	sc := Db{
+		Author: &userId,
+		Modem:  modemId,
+		Name:   data.Name,
+		Params: data.Params,
+		Kind:   data.Kind,
+	}
+
+	switch data.Kind {
+	case KIND_ONCE:
+		if data.RunAt == nil || !data.RunAt.After(now) {
+			return Schedule{}, app_error.ValidationError(map[string]string{"run_at": "Должно быть в будущем"})
+		}
+
+		sc.RunAt = data.RunAt
+		sc.NextRunAt = data.RunAt
+	case KIND_CRON:
+		schedule, err := cron.ParseStandard(data.Cron)
+		if err != nil {
+			return Schedule{}, app_error.ValidationError(map[string]string{"cron": err.Error()})
+		}
+
+		next := schedule.Next(now)
+		sc.Cron = &data.Cron
+		sc.NextRunAt = &next
+	default:
+		// перевозки, начатые или завершённые до создания расписания, не учитываются
+		sc.LastEventAt = &now
+	}
+
+	id, err := s.repo.Create(sc)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	return s.repo.GetById(id)
+}
+
+func (s *usecase) ListByModem(modemId int) ([]Schedule, error) {
+	if _, err := s.repo.Device(modemId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByModem(modemId)
+}
+
+func (s *usecase) Delete(modemId int, id int) error {
+	sc, err := s.repo.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	if sc.Modem != modemId {
+		return app_error.ErrNotFound
+	}
+
+	return s.repo.Delete(id)
+}
+
+// Check отправляет команды, время которых пришло, и команды по началу и завершению перевозок
+func (s *usecase) Check() error {
+	due, err := s.repo.Due(CHECK_BATCH)
+	if err != nil {
+		return err
+	}
+
+	triggered, err := s.repo.Triggered(CHECK_BATCH)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range append(due, triggered...) {
+		if err := s.run(d); err != nil {
+			s.logger.Error(fmt.Sprintf("Command schedule %d: %v", d.Id, err))
+		}
+	}
+
+	return nil
+}
+
+// run отправляет команду и записывает результат. Пока сервис команд недоступен, отправка
+// любого вида повторяется через RETRY_DELAY до MAX_ATTEMPTS раз, ошибки проверки не повторяются
+func (s *usecase) run(d due) error {
+	now := time.Now()
+	run := Run{Status: RUN_SENT}
+
+	sendErr := s.send(d)
+	if sendErr != nil {
+		run.Status = RUN_FAILED
+		run.Error = errorBody(sendErr)
+	}
+
+	var appErr *app_error.AppError
+	if sendErr != nil && !errors.As(sendErr, &appErr) && d.Attempts+1 < MAX_ATTEMPTS {
+		next := now.Add(RETRY_DELAY)
+		return s.repo.Finish(d, run, &next, true, d.Attempts+1)
+	}
+
+	switch d.Kind {
+	case KIND_ONCE:
+		return s.repo.Finish(d, run, nil, false, 0)
+	case KIND_CRON:
+		var next *time.Time
+		if d.Cron != nil {
+			if schedule, err := cron.ParseStandard(*d.Cron); err == nil {
+				n := schedule.Next(now)
+				next = &n
+			}
+		}
+
+		return s.repo.Finish(d, run, next, next != nil, 0)
+	}
+
+	return s.repo.Finish(d, run, nil, true, 0)
+}
+
+func (s *usecase) send(d due) error {
+	// каталог мог измениться, а модем - смениться на другую ревизию
+	template, err := s.usecase.Command.Check(d.Name, d.Params, d.Role)
+	if err != nil {
+		return err
+	}
+
+	if template == nil {
+		return app_error.ValidationError("Команды нет в каталоге")
+	}
+
+	if !template.Supports(d.HardwareRevision) {
+		return app_error.ValidationError("Команда не поддерживается моделью модема")
+	}
+
+	_, err = s.usecase.Commands.Send(d.Imei, d.Name, d.Params, d.Author)
+
+	return err
+}
+
+// errorBody тело ошибки проверки от сервиса команд или текст ошибки
+func errorBody(err error) any {
+	var appErr *app_error.AppError
+	if errors.As(err, &appErr) {
+		return appErr.GetBody()
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,176 @@
+package schedule
+
+import (
+	"errors"
+	"seal/internal/domain/command"
+	"seal/pkg/app_error"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type finished struct {
+	next     *time.Time
+	active   bool
+	attempts int
+	status   int
+	shipping *int
+}
+
+type fakeRepo struct {
+	Repo
+	device   device
+	created  []Db
+	finished []finished
+}
+
+func (r *fakeRepo) Device(modemId int) (device, error) { return r.device, nil }
+
+func (r *fakeRepo) Create(data Db) (int, error) {
+	r.created = append(r.created, data)
+	return len(r.created), nil
+}
+
+func (r *fakeRepo) GetById(id int) (Schedule, error) { return Schedule{Id: id}, nil }
+
+func (r *fakeRepo) Finish(d due, run Run, next *time.Time, active bool, attempts int) error {
+	r.finished = append(r.finished, finished{next, active, attempts, run.Status, d.Shipping})
+	return nil
+}
+
+// fakeCatalog каталог из одной команды set-connect-period для роли 1 и ревизии rev2
+type fakeCatalog struct {
+	command.Usecase
+}
+
+func (c fakeCatalog) Check(name string, params any, role int) (*command.Template, error) {
+	template := command.Template{Name: "set-connect-period", Models: []string{"rev2"}, Roles: []int{1}}
+	if name != template.Name {
+		return nil, nil
+	}
+
+	if !template.Allowed(role) {
+		return nil, app_error.ErrUnauthorized
+	}
+
+	return &template, nil
+}
+
+type fakeSender struct {
+	err   error
+	calls int
+}
+
+func (s *fakeSender) Send(imei string, name string, params any, author string) (bool, error) {
+	s.calls++
+	return s.err == nil, s.err
+}
+
+type fakeValidator struct{}
+
+func (fakeValidator) Struct(interface{}) map[string]string { return nil }
+
+func newTestUsecase(sendErr error) (*usecase, *fakeRepo, *fakeSender) {
+	repo := &fakeRepo{device: device{Id: 1, Imei: "1", HardwareRevision: "rev2"}}
+	sender := &fakeSender{err: sendErr}
+
+	return &usecase{repo: repo, validator: fakeValidator{}, usecase: CoreUseCase{Command: fakeCatalog{}, Commands: sender}},
+		repo, sender
+}
+
+func TestCreate(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		request  CreateRequest
+		role     int
+		revision string
+		err      error
+	}{
+		{"once", CreateRequest{Name: "set-connect-period", Kind: KIND_ONCE, RunAt: &future}, 1, "rev2", nil},
+		{"once в прошлом", CreateRequest{Name: "set-connect-period", Kind: KIND_ONCE, RunAt: &past}, 1, "rev2", app_error.ErrValidation},
+		{"cron", CreateRequest{Name: "set-connect-period", Kind: KIND_CRON, Cron: "*/5 * * * *"}, 1, "rev2", nil},
+		{"неверный cron", CreateRequest{Name: "set-connect-period", Kind: KIND_CRON, Cron: "* *"}, 1, "rev2", app_error.ErrValidation},
+		{"по началу перевозки", CreateRequest{Name: "set-connect-period", Kind: KIND_SHIPPING_START}, 1, "rev2", nil},
+		{"роль не из каталога", CreateRequest{Name: "set-connect-period", Kind: KIND_SHIPPING_END}, 2, "rev2", app_error.ErrUnauthorized},
+		{"команды нет в каталоге", CreateRequest{Name: "reboot-now", Kind: KIND_SHIPPING_END}, 1, "rev2", app_error.ErrValidation},
+		{"другая ревизия модема", CreateRequest{Name: "set-connect-period", Kind: KIND_SHIPPING_END}, 1, "rev1", app_error.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, _ := newTestUsecase(nil)
+			repo.device.HardwareRevision = tt.revision
+
+			_, err := s.Create(1, tt.request, 1, tt.role)
+
+			if tt.err == nil {
+				assert.Nil(t, err)
+				assert.Len(t, repo.created, 1)
+				return
+			}
+
+			var appErr *app_error.AppError
+			assert.True(t, errors.As(err, &appErr))
+			assert.Equal(t, tt.err.(*app_error.AppError).GetHttpCode(), appErr.GetHttpCode())
+			assert.Len(t, repo.created, 0)
+		})
+	}
+}
+
+func TestRunRetry(t *testing.T) {
+	cronSpec := "0 * * * *"
+	shippingId := 7
+	unavailable := errors.New("commands service unavailable")
+
+	tests := []struct {
+		name     string
+		due      due
+		sendErr  error
+		next     bool
+		active   bool
+		attempts int
+		status   int
+	}{
+		{"once отправлена", due{Kind: KIND_ONCE}, nil, false, false, 0, RUN_SENT},
+		{"once повтор", due{Kind: KIND_ONCE}, unavailable, true, true, 1, RUN_FAILED},
+		{"once попытки кончились", due{Kind: KIND_ONCE, Attempts: MAX_ATTEMPTS - 1}, unavailable, false, false, 0, RUN_FAILED},
+		{"cron отправлена", due{Kind: KIND_CRON, Cron: &cronSpec}, nil, true, true, 0, RUN_SENT},
+		{"cron повтор", due{Kind: KIND_CRON, Cron: &cronSpec, Attempts: 1}, unavailable, true, true, 2, RUN_FAILED},
+		{"перевозка отправлена", due{Kind: KIND_SHIPPING_START, Shipping: &shippingId}, nil, false, true, 0, RUN_SENT},
+		{"перевозка повтор", due{Kind: KIND_SHIPPING_END, Shipping: &shippingId}, unavailable, true, true, 1, RUN_FAILED},
+		{"перевозка попытки кончились", due{Kind: KIND_SHIPPING_END, Shipping: &shippingId, Attempts: MAX_ATTEMPTS - 1},
+			unavailable, false, true, 0, RUN_FAILED},
+		{"ошибка проверки не повторяется", due{Kind: KIND_SHIPPING_START, Shipping: &shippingId},
+			app_error.ValidationError("bad params"), false, true, 0, RUN_FAILED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, sender := newTestUsecase(tt.sendErr)
+			tt.due.Name, tt.due.Role, tt.due.HardwareRevision = "set-connect-period", 1, "rev2"
+
+			assert.Nil(t, s.run(tt.due))
+			assert.Equal(t, 1, sender.calls)
+			assert.Len(t, repo.finished, 1)
+
+			f := repo.finished[0]
+			assert.Equal(t, tt.next, f.next != nil)
+			assert.Equal(t, tt.active, f.active)
+			assert.Equal(t, tt.attempts, f.attempts)
+			assert.Equal(t, tt.status, f.status)
+			assert.Equal(t, tt.due.Shipping, f.shipping)
+		})
+	}
+}
+
+func TestRunNotInCatalog(t *testing.T) {
+	s, repo, sender := newTestUsecase(nil)
+
+	assert.Nil(t, s.run(due{Kind: KIND_ONCE, Name: "reboot-now", Role: 1}))
+	assert.Equal(t, 0, sender.calls)
+	assert.Equal(t, RUN_FAILED, repo.finished[0].status)
+}
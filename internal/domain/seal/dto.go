@@ -1,6 +1,7 @@
 package seal
 
 import (
+	"seal/internal/domain/battery"
 	"time"
 )
 
@@ -64,11 +65,12 @@ type Modem struct {
 }
 
 type Seal struct {
-	Id      int     `json:"id"`
-	Serial  uint64  `json:"serial"`
-	Last    *Data   `json:"last"`
-	Comment string  `json:"comment"`
-	Modems  []Modem `json:"modems"`
+	Id      int               `json:"id"`
+	Serial  uint64            `json:"serial"`
+	Last    *Data             `json:"last"`
+	Comment string            `json:"comment"`
+	Modems  []Modem           `json:"modems"`
+	Battery *battery.Forecast `json:"battery" db:"-"`
 }
 
 type ArchiveQueryParams struct {
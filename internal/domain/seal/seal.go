@@ -13,6 +13,19 @@ type Db struct {
 	Comment     string             `json:"comment"`
 }
 
+// Биты seals_data.status, общие для всех моделей пломб.
+// Используются при поиске инцидентов вскрытия и в отчёте по перевозке, прочие биты расшифровываются через status_flags
+const (
+	STATUS_CABLE_CUT   = 1 << 0 // трос перерезан
+	STATUS_CASE_OPENED = 1 << 1 // корпус пломбы вскрыт
+)
+
+// Биты seals_data.errors, общие для всех моделей пломб. Установка любого из них - инцидент "Ошибка датчика"
+const (
+	ERROR_CABLE_SENSOR = 1 << 0 // неисправен датчик троса
+	ERROR_CASE_SENSOR  = 1 << 1 // неисправен датчик вскрытия корпуса
+)
+
 type Repo interface {
 	GetById(id int) (Seal, error)
 	GetDbById(id int) (Db, error)
@@ -23,6 +36,7 @@ type Repo interface {
 
 type Usecase interface {
 	GetById(id int) (Seal, error)
+	WithBattery(seal Seal) Seal
 	GetDbById(id int) (Db, error)
 	List(params transport.QueryParams) (query.List[SealForList], error)
 	Exists(id int) (bool, error)
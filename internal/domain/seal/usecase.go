@@ -1,7 +1,9 @@
 package seal
 
 import (
+	"fmt"
 	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/battery"
 	sealData "seal/internal/domain/seal_data"
 	"seal/internal/repository/pg/query"
 	"seal/internal/transport"
@@ -10,6 +12,7 @@ import (
 )
 
 type CoreUseCase struct {
+	Battery  battery.Usecase
 	SealData sealData.Usecase
 }
 
@@ -28,6 +31,19 @@ func (s *usecase) GetById(id int) (Seal, error) {
 	return s.repo.GetById(id)
 }
 
+// WithBattery дополняет пломбу прогнозом батареи для карточки пломбы
+func (s *usecase) WithBattery(seal Seal) Seal {
+	// без прогноза пломба всё равно нужна, ошибка только логируется
+	if forecasts, err := s.usecase.Battery.Seals([]int{seal.Id}); err != nil {
+		s.logger.Error(fmt.Sprintf("Battery forecast seal %d: %v", seal.Id, err))
+	} else {
+		forecast := forecasts[seal.Id]
+		seal.Battery = &forecast
+	}
+
+	return seal
+}
+
 func (s *usecase) GetDbById(id int) (Db, error) {
 	return s.repo.GetDbById(id)
 }
@@ -0,0 +1,142 @@
+package shipping
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"seal/pkg/app_error"
+	"strings"
+	"time"
+)
+
+const BUNDLE_MANIFEST = "manifest.json"
+
+// BundleEntry описание файла в manifest.json архива перевозки
+type BundleEntry struct {
+	Entry       string `json:"entry"`
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Type        int    `json:"type"`
+	SealId      int    `json:"seal_id,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	Checksum    string `json:"checksum"`
+	UploadToken int    `json:"upload_token,omitempty"`
+	Size        int64  `json:"size"`
+	Error       string `json:"error,omitempty"`
+}
+
+type BundleManifest struct {
+	Shipping     int           `json:"shipping"`
+	CustomNumber string        `json:"custom_number"`
+	CreatedAt    time.Time     `json:"created_at"`
+	Files        []BundleEntry `json:"files"`
+}
+
+// BundleFiles файлы перевозки для архива с фильтром по типу и пломбе
+func (s *usecase) BundleFiles(id int, params BundleQueryParams) ([]File, error) {
+	if errs := s.validator.Struct(params); errs != nil {
+		return nil, app_error.ValidationError(errs)
+	}
+
+	shipping, err := s.GetDbById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	for _, file := range shipping.Files {
+		if params.Type != nil && file.Type != *params.Type || params.SealId > 0 && file.SealId != params.SealId {
+			continue
+		}
+
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		return nil, app_error.ErrNotFound
+	}
+
+	return files, nil
+}
+
+// WriteBundle пишет zip с файлами перевозки и manifest.json потоком, не загружая файлы в память.
+// Отсутствующие в хранилище файлы пропускаются и отмечаются в манифесте
+func (s *usecase) WriteBundle(w io.Writer, id int, files []File) error {
+	shipping, err := s.GetDbById(id)
+	if err != nil {
+		return err
+	}
+
+	manifest := BundleManifest{Shipping: id, CustomNumber: shipping.CustomNumber, CreatedAt: time.Now()}
+	archive := zip.NewWriter(w)
+	names := map[string]bool{BUNDLE_MANIFEST: true}
+
+	for _, file := range files {
+		entry := BundleEntry{
+			Entry:       bundleEntryName(file.Title, names),
+			Name:        file.Name,
+			Title:       file.Title,
+			Type:        file.Type,
+			SealId:      file.SealId,
+			Comment:     file.Comment,
+			Checksum:    file.Checksum,
+			UploadToken: file.UploadToken,
+		}
+
+		if entry.Size, err = s.writeBundleFile(archive, id, file.Name, entry.Entry); err != nil {
+			s.logger.Error(fmt.Sprintf("Shipping %d bundle file %s: %v", id, file.Name, err))
+			entry.Entry = ""
+			entry.Error = err.Error()
+		}
+
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	mw, err := archive.CreateHeader(&zip.FileHeader{Name: BUNDLE_MANIFEST, Method: zip.Deflate, Modified: manifest.CreatedAt})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(mw)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func (s *usecase) writeBundleFile(archive *zip.Writer, id int, name, entry string) (int64, error) {
+	r, _, err := s.OpenFile(id, name)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	fw, err := archive.CreateHeader(&zip.FileHeader{Name: entry, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return 0, err
+	}
+
+	return io.Copy(fw, r)
+}
+
+// bundleEntryName имя файла в архиве по исходному названию, повторы получают суффикс " (n)"
+func bundleEntryName(title string, names map[string]bool) string {
+	title = strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSpace(title))
+	if title == "" || title == "." || title == ".." {
+		title = "file"
+	}
+
+	name := title
+	ext := path.Ext(title)
+	for i := 2; names[name]; i++ {
+		name = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(title, ext), i, ext)
+	}
+
+	names[name] = true
+
+	return name
+}
@@ -0,0 +1,80 @@
+package shipping
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"seal/pkg/app_error"
+	"seal/pkg/storage"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle(t *testing.T) {
+	store := storage.NewLocal(t.TempDir())
+	files := []File{
+		{Name: "a.pdf", Title: "act.pdf", Type: FILE_TYPE_ACT, Checksum: "1"},
+		{Name: "b.pdf", Title: "act.pdf", Type: FILE_TYPE_ACT, Checksum: "2"},
+		{Name: "c.jpg", Title: "seal.jpg", Type: FILE_TYPE_SEAL_PHOTO, SealId: 3, Checksum: "3"},
+		{Name: "lost.jpg", Title: "cargo.jpg", Type: FILE_TYPE_CARGO_PHOTO, Checksum: "4"},
+	}
+
+	for _, name := range []string{"a.pdf", "b.pdf", "c.jpg"} {
+		require.NoError(t, store.Put(fileKey(1, name), strings.NewReader("content "+name), int64(len("content "+name)), ""))
+	}
+
+	repo := newFakeRepo(Db{Id: 1, CustomNumber: "00123456", Files: files})
+	s := newTestUsecase(repo, store)
+
+	t.Run("фильтр", func(t *testing.T) {
+		act := FILE_TYPE_ACT
+
+		data, err := s.BundleFiles(1, BundleQueryParams{Type: &act})
+		require.NoError(t, err)
+		assert.Len(t, data, 2)
+
+		data, err = s.BundleFiles(1, BundleQueryParams{SealId: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []File{files[2]}, data)
+
+		_, err = s.BundleFiles(1, BundleQueryParams{SealId: 4})
+		assert.ErrorIs(t, err, app_error.ErrNotFound)
+	})
+
+	t.Run("архив", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, s.WriteBundle(&buf, 1, files))
+
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+
+		entries := map[string]string{}
+		for _, f := range archive.File {
+			r, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(r)
+			require.NoError(t, err)
+			r.Close()
+
+			entries[f.Name] = string(content)
+		}
+
+		assert.Equal(t, "content a.pdf", entries["act.pdf"])
+		assert.Equal(t, "content b.pdf", entries["act (2).pdf"])
+		assert.Equal(t, "content c.jpg", entries["seal.jpg"])
+		assert.NotContains(t, entries, "cargo.jpg")
+
+		var manifest BundleManifest
+		require.NoError(t, json.Unmarshal([]byte(entries[BUNDLE_MANIFEST]), &manifest))
+		assert.Equal(t, "00123456", manifest.CustomNumber)
+		require.Len(t, manifest.Files, 4)
+		assert.Equal(t, int64(len("content c.jpg")), manifest.Files[2].Size)
+		assert.Equal(t, 3, manifest.Files[2].SealId)
+		assert.Empty(t, manifest.Files[3].Entry)
+		assert.NotEmpty(t, manifest.Files[3].Error)
+	})
+}
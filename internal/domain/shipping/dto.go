@@ -1,6 +1,7 @@
 package shipping
 
 import (
+	"seal/internal/domain/status_flag"
 	"seal/internal/domain/user"
 	"time"
 )
@@ -44,7 +45,12 @@ type Shipping struct {
 			Rssi         int16 `json:"rssi"`
 		} `json:"last"`
 	} `json:"seals"`
-	EstimatedArrivalTime time.Time `json:"estimated_arrival_time" db:"estimated_arrival_time"`
+	PlannedArrivalTime   time.Time  `json:"planned_arrival_time" db:"planned_arrival_time"`
+	EstimatedArrivalTime time.Time  `json:"estimated_arrival_time" db:"estimated_arrival_time"`
+	RemainingDistance    *int       `json:"remaining_distance" db:"remaining_distance"`
+	EtaCalculatedAt      *time.Time `json:"eta_calculated_at" db:"eta_calculated_at"`
+	DrivenDistance       *float64   `json:"driven_distance" db:"driven_distance"`
+	MovingTime           *int       `json:"moving_time" db:"moving_time"`
 }
 
 type ShippingForList struct {
@@ -70,6 +76,7 @@ type ShippingForList struct {
 		TravelTime int      `json:"travel_time"`
 	} `json:"route"`
 	Files                []File    `json:"files"`
+	PlannedArrivalTime   time.Time `json:"planned_arrival_time" db:"planned_arrival_time"`
 	EstimatedArrivalTime time.Time `json:"estimated_arrival_time" db:"estimated_arrival_time"`
 }
 
@@ -110,6 +117,7 @@ type trackResponseTelemetry struct {
 	Altitude    int32                   `json:"altitude"`
 	SealsData   []trackResponseSealData `json:"sealsData"`
 	ErrorsFlags int32                   `json:"errors_flags"`
+	Flags       status_flag.Decoded     `json:"flags"`
 }
 
 type trackResponseCoordinate struct {
@@ -126,6 +134,38 @@ type TrackQueryParams struct {
 	To        time.Time `form:"to"`
 	Limit     int       `form:"limit" validate:"max=500000"`
 	OrderDesc bool      `form:"order_desc"`
+	Tolerance int       `form:"tolerance" validate:"max=100000,min=0"`
+	Zoom      *int      `form:"zoom" validate:"omitempty,max=22,min=0"`
+}
+
+type TransitionRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=1024"`
+}
+
+type History struct {
+	Id         int         `json:"id"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+	Author     user.Author `json:"author"`
+	StatusFrom int         `json:"status_from" db:"status_from"`
+	StatusTo   int         `json:"status_to" db:"status_to"`
+	Reason     string      `json:"reason"`
+}
+
+type HistoryDb struct {
+	Shipping   int    `json:"shipping"`
+	Author     int    `json:"author"`
+	StatusFrom int    `json:"status_from" db:"status_from"`
+	StatusTo   int    `json:"status_to" db:"status_to"`
+	Reason     string `json:"reason"`
+}
+
+// Pause приостановка перевозки по истории статусов. Для незавершённой приостановки TimeEnd пустой,
+// а длительность считается до текущего момента
+type Pause struct {
+	TimeStart time.Time  `json:"time_start"`
+	TimeEnd   *time.Time `json:"time_end"`
+	Duration  int        `json:"duration"` // секунды
+	Reason    string     `json:"reason"`
 }
 
 type AddFileRequest struct {
@@ -138,3 +178,12 @@ type UpdateFileRequest struct {
 	Title   *string `json:"title,omitempty"`
 	SealId  *int    `json:"seal_id,omitempty"`
 }
+
+type BundleQueryParams struct {
+	Type   *int `form:"type" validate:"omitempty,min=0"`
+	SealId int  `form:"seal_id" validate:"min=0"`
+}
+
+type ImportRequest struct {
+	DryRun bool `form:"dry_run"`
+}
@@ -0,0 +1,55 @@
+package shipping
+
+import (
+	"fmt"
+	"seal/internal/domain/modem"
+	"seal/pkg/app_error"
+	"seal/pkg/track_export"
+)
+
+// ExportTrack трек перевозки с плановым маршрутом для выгрузки в GIS-форматы
+func (s *usecase) ExportTrack(params TrackQueryParams) (track_export.Track, error) {
+	shipping, err := s.GetDbById(params.Id)
+	if err != nil {
+		return track_export.Track{}, err
+	}
+
+	if shipping.Modem == nil || shipping.TimeStart == nil {
+		return track_export.Track{}, app_error.ValidationError(`Поездка не начата`)
+	}
+
+	timeFrom := *shipping.TimeStart
+	if !params.From.IsZero() && params.From.After(timeFrom) {
+		timeFrom = params.From
+	}
+
+	queryParams := modem.TrackQueryParams{
+		Id:        *shipping.Modem,
+		From:      timeFrom,
+		Limit:     params.Limit,
+		Tolerance: params.Tolerance,
+		Zoom:      params.Zoom,
+	}
+
+	if shipping.TimeEnd != nil {
+		queryParams.To = *shipping.TimeEnd
+	}
+
+	name := fmt.Sprintf("%s/%s/%d", shipping.CustomNumber, shipping.CreateDate, shipping.Number)
+
+	track, err := s.usecase.Modem.ExportTrack(queryParams, name)
+	if err != nil {
+		return track, err
+	}
+
+	route, err := s.usecase.Route.GetById(shipping.Route)
+	if err != nil {
+		return track, err
+	}
+
+	for _, coord := range route.Coords {
+		track.Route = append(track.Route, track_export.Point{Latitude: float64(coord[0]), Longitude: float64(coord[1])})
+	}
+
+	return track, nil
+}
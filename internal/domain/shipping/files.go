@@ -0,0 +1,244 @@
+package shipping
+
+import (
+	"fmt"
+	"path/filepath"
+	"seal/pkg/app_error"
+	"slices"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// FILE_MAX_COUNT максимум файлов в одной перевозке
+const FILE_MAX_COUNT = 100
+
+// FILE_SNIFF_LEN сколько байт начала файла нужно для определения типа по содержимому
+const FILE_SNIFF_LEN = 3072
+
+// FILE_MAX_SIZE наибольший размер одного файла для типов документов и типов без своего правила
+const FILE_MAX_SIZE = 20 << 20
+
+// FILE_MAX_PHOTO_SIZE наибольший размер одной фотографии
+const FILE_MAX_PHOTO_SIZE = 10 << 20
+
+// FILE_MAX_REQUEST_SIZE наибольший размер всего запроса загрузки, тело читается не дальше этого размера
+const FILE_MAX_REQUEST_SIZE = 100 << 20
+
+// типы файлов перевозки (File.Type)
+const FILE_TYPE_DOCUMENT = 0    // сопроводительные документы и их сканы
+const FILE_TYPE_SEAL_PHOTO = 1  // фото пломб
+const FILE_TYPE_CARGO_PHOTO = 2 // фото груза и транспортного средства
+const FILE_TYPE_ACT = 3         // акты и прочие документы
+
+// FileRule ограничения на загружаемые файлы одного File.Type
+type FileRule struct {
+	MimeTypes []string
+	MaxSize   int64
+	MaxCount  int
+}
+
+var imageMimeTypes = []string{
+	"image/jpeg", "image/png", "image/webp", "image/heic", "image/heif", "image/gif", "image/bmp", "image/tiff",
+}
+
+var documentMimeTypes = []string{
+	"application/pdf",
+	"application/msword",
+	"application/vnd.ms-excel",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"application/vnd.oasis.opendocument.text",
+	"application/vnd.oasis.opendocument.spreadsheet",
+	"text/rtf",
+	"text/plain",
+}
+
+var photoFileRule = FileRule{
+	MimeTypes: imageMimeTypes,
+	MaxSize:   FILE_MAX_PHOTO_SIZE,
+	MaxCount:  50,
+}
+
+// documentFileRule документы вместе со сканами и фотографиями документов
+var documentFileRule = FileRule{
+	MimeTypes: append(append([]string{}, documentMimeTypes...), imageMimeTypes...),
+	MaxSize:   FILE_MAX_SIZE,
+	MaxCount:  20,
+}
+
+// FileRules правила по File.Type. Для остальных типов действует documentFileRule, см. GetFileRule
+var FileRules = map[int]FileRule{
+	FILE_TYPE_DOCUMENT:    documentFileRule,
+	FILE_TYPE_SEAL_PHOTO:  photoFileRule,
+	FILE_TYPE_CARGO_PHOTO: photoFileRule,
+	FILE_TYPE_ACT:         documentFileRule,
+}
+
+// GetFileRule правило для типа файла. Типы без своего правила принимаются как документы
+func GetFileRule(fileType int) FileRule {
+	if rule, ok := FileRules[fileType]; ok {
+		return rule
+	}
+
+	return documentFileRule
+}
+
+// forbiddenMimeTypes исполняемые файлы и архивы, проверяются с учётом родительских типов
+var forbiddenMimeTypes = []string{
+	"application/vnd.microsoft.portable-executable",
+	"application/x-elf",
+	"application/x-mach-binary",
+	"application/x-ms-installer",
+	"application/jar",
+	"application/x-shockwave-flash",
+	"application/wasm",
+	"application/zip",
+	"application/x-rar-compressed",
+	"application/x-7z-compressed",
+	"application/x-tar",
+	"application/gzip",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/zstd",
+	"application/vnd.ms-cab-compressed",
+	"application/x-rpm",
+	"application/x-lzip",
+}
+
+// forbiddenExtensions скрипты и архивы, которые по содержимому выглядят как текст или документ
+var forbiddenExtensions = []string{
+	".exe", ".com", ".bat", ".cmd", ".msi", ".scr", ".dll", ".so", ".sh", ".bash", ".ps1", ".vbs", ".js", ".jar",
+	".apk", ".app", ".bin", ".run", ".py", ".php", ".pl", ".lnk", ".reg", ".hta",
+	".zip", ".rar", ".7z", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".zst", ".cab",
+}
+
+// Upload загружаемый файл до сохранения в хранилище
+type Upload struct {
+	Title    string
+	Size     int64
+	Head     []byte // первые FILE_SNIFF_LEN байт содержимого
+	Checksum string
+}
+
+// FileError ошибка проверки одного загружаемого файла, Index - номер в запросе
+type FileError struct {
+	Index int    `json:"index"`
+	Title string `json:"title"`
+	Error string `json:"error"`
+}
+
+// DetectMime тип файла по содержимому
+func DetectMime(head []byte) string {
+	return mimetype.Detect(head).String()
+}
+
+// CheckFileSeal файл можно отнести только к пломбе, привязанной к перевозке
+func (s *usecase) CheckFileSeal(id int, sealId int) error {
+	if sealId == 0 {
+		return nil
+	}
+
+	sealIds, err := s.repo.SealIds(id)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(sealIds, sealId) {
+		return app_error.ValidationError(map[string]string{"seal_id": "Пломба не привязана к перевозке"})
+	}
+
+	return nil
+}
+
+// ValidateUploads проверка файлов перед загрузкой: тип по содержимому, размер, количество и повторы по контрольной сумме
+func (s *usecase) ValidateUploads(id int, fileType int, uploads []Upload) error {
+	if fileType < 0 {
+		return app_error.ValidationError(map[string]string{"type": "Неизвестный тип файла"})
+	}
+
+	rule := GetFileRule(fileType)
+
+	if len(uploads) == 0 {
+		return app_error.ValidationError(map[string]string{"file": "Не выбраны файлы"})
+	}
+
+	shipping, err := s.GetDbById(id)
+	if err != nil {
+		return err
+	}
+
+	if len(shipping.Files)+len(uploads) > FILE_MAX_COUNT {
+		return app_error.ValidationError(map[string]string{
+			"file": fmt.Sprintf("В перевозке может быть не больше %d файлов", FILE_MAX_COUNT),
+		})
+	}
+
+	count := len(uploads)
+	checksums := make(map[string]bool)
+	for _, file := range shipping.Files {
+		if file.Type == fileType {
+			count++
+		}
+		if file.Checksum != "" {
+			checksums[file.Checksum] = true
+		}
+	}
+
+	if count > rule.MaxCount {
+		return app_error.ValidationError(map[string]string{
+			"file": fmt.Sprintf("Файлов этого типа может быть не больше %d", rule.MaxCount),
+		})
+	}
+
+	var errs []FileError
+	for i, upload := range uploads {
+		if msg := checkUpload(rule, upload); msg != "" {
+			errs = append(errs, FileError{i, upload.Title, msg})
+		} else if checksums[upload.Checksum] {
+			errs = append(errs, FileError{i, upload.Title, "Файл уже прикреплён к перевозке"})
+		}
+
+		checksums[upload.Checksum] = true
+	}
+
+	if len(errs) > 0 {
+		return app_error.ValidationError(map[string][]FileError{"files": errs})
+	}
+
+	return nil
+}
+
+func checkUpload(rule FileRule, upload Upload) string {
+	if upload.Size == 0 {
+		return "Пустой файл"
+	}
+
+	if upload.Size > rule.MaxSize {
+		return fmt.Sprintf("Размер файла больше %d МБ", rule.MaxSize>>20)
+	}
+
+	ext := strings.ToLower(filepath.Ext(upload.Title))
+	for _, forbidden := range forbiddenExtensions {
+		if ext == forbidden {
+			return "Исполняемые файлы и архивы запрещены"
+		}
+	}
+
+	detected := mimetype.Detect(upload.Head)
+	for _, allowed := range rule.MimeTypes {
+		if detected.Is(allowed) {
+			return ""
+		}
+	}
+
+	for m := detected; m != nil; m = m.Parent() {
+		for _, forbidden := range forbiddenMimeTypes {
+			if m.Is(forbidden) {
+				return "Исполняемые файлы и архивы запрещены"
+			}
+		}
+	}
+
+	return fmt.Sprintf("Недопустимый тип файла %s", detected.String())
+}
@@ -0,0 +1,37 @@
+package shipping
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var png = append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), bytes.Repeat([]byte{0}, 64)...)
+var pdf = []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+func TestCheckUpload(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType int
+		upload   Upload
+		ok       bool
+	}{
+		{"фото пломбы", FILE_TYPE_SEAL_PHOTO, Upload{Title: "seal.png", Size: 100, Head: png}, true},
+		{"документ вместо фото", FILE_TYPE_SEAL_PHOTO, Upload{Title: "cmr.pdf", Size: 100, Head: pdf}, false},
+		{"фото больше лимита", FILE_TYPE_CARGO_PHOTO, Upload{Title: "cargo.png", Size: FILE_MAX_PHOTO_SIZE + 1, Head: png}, false},
+		{"документ", FILE_TYPE_DOCUMENT, Upload{Title: "cmr.pdf", Size: FILE_MAX_PHOTO_SIZE + 1, Head: pdf}, true},
+		{"скан акта", FILE_TYPE_ACT, Upload{Title: "act.png", Size: 100, Head: png}, true},
+		{"тип без правила", 7, Upload{Title: "other.pdf", Size: 100, Head: pdf}, true},
+		{"пустой файл", FILE_TYPE_DOCUMENT, Upload{Title: "empty.pdf", Head: pdf}, false},
+		{"исполняемый файл", FILE_TYPE_DOCUMENT, Upload{Title: "photo.jpg", Size: 100, Head: []byte("MZ\x90\x00")}, false},
+		{"скрипт", FILE_TYPE_DOCUMENT, Upload{Title: "run.sh", Size: 100, Head: []byte("echo")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := checkUpload(GetFileRule(tt.fileType), tt.upload)
+			assert.Equal(t, tt.ok, msg == "", msg)
+		})
+	}
+}
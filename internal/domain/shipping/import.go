@@ -0,0 +1,198 @@
+package shipping
+
+import (
+	"errors"
+	"fmt"
+	"seal/pkg/app_error"
+	"strconv"
+	"strings"
+)
+
+// IMPORT_MAX_ROWS максимальное количество перевозок в одном файле импорта
+const IMPORT_MAX_ROWS = 1000
+
+// importColumns колонки файла импорта. Транспорт указывается регистрационным номером или наименованием,
+// маршрут - наименованием
+var importColumns = []string{"custom_number", "create_date", "number", "transport", "route"}
+
+type ImportRow struct {
+	Line         int
+	CustomNumber string
+	CreateDate   string
+	Number       string
+	Transport    string
+	Route        string
+}
+
+type ImportRowResult struct {
+	Line     int               `json:"line"`
+	Valid    bool              `json:"valid"`
+	Shipping *Shipping         `json:"shipping,omitempty"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Valid   int               `json:"valid"`
+	Created int               `json:"created"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// ParseImportRows разбирает строки таблицы, первая строка - заголовок с наименованиями колонок importColumns.
+// Пустые строки пропускаются и в ограничение IMPORT_MAX_ROWS не входят
+func ParseImportRows(table [][]string) ([]ImportRow, error) {
+	if len(table) < 2 {
+		return nil, app_error.ValidationError(`Файл не содержит строк для импорта`)
+	}
+
+	index := map[string]int{}
+	for i, title := range table[0] {
+		index[strings.ToLower(strings.TrimSpace(title))] = i
+	}
+
+	var missing []string
+	for _, column := range importColumns {
+		if _, ok := index[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, app_error.ValidationError(fmt.Sprintf(`В заголовке нет колонок: %s`, strings.Join(missing, ", ")))
+	}
+
+	cell := func(row []string, column string) string {
+		if i := index[column]; i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var rows []ImportRow
+	for i, row := range table[1:] {
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		rows = append(rows, ImportRow{
+			Line:         i + 2,
+			CustomNumber: cell(row, "custom_number"),
+			CreateDate:   cell(row, "create_date"),
+			Number:       cell(row, "number"),
+			Transport:    cell(row, "transport"),
+			Route:        cell(row, "route"),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, app_error.ValidationError(`Файл не содержит строк для импорта`)
+	}
+
+	if len(rows) > IMPORT_MAX_ROWS {
+		return nil, app_error.ValidationError(fmt.Sprintf(`Файл содержит более %d строк`, IMPORT_MAX_ROWS))
+	}
+
+	return rows, nil
+}
+
+// Import проверяет каждую строку той же валидацией, что и создание перевозки.
+// Перевозки создаются одной транзакцией и только если корректны все строки. В режиме dryRun перевозки не создаются
+func (s *usecase) Import(rows []ImportRow, userId int, dryRun bool) (ImportResult, error) {
+	result := ImportResult{DryRun: dryRun, Total: len(rows), Rows: []ImportRowResult{}}
+	seen := map[string]int{}
+	var models []Db
+
+	for _, row := range rows {
+		rowResult := ImportRowResult{Line: row.Line}
+
+		model, errs, err := s.importModel(row, userId)
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		key := fmt.Sprintf("%s/%s/%d", model.CustomNumber, model.CreateDate, model.Number)
+		if line, ok := seen[key]; ok && len(errs) == 0 {
+			errs = map[string]string{"number": fmt.Sprintf("Повторяет строку %d", line)}
+		}
+
+		if len(errs) > 0 {
+			rowResult.Errors = errs
+		} else {
+			seen[key] = row.Line
+			rowResult.Valid = true
+			result.Valid++
+			models = append(models, model)
+		}
+
+		result.Rows = append(result.Rows, rowResult)
+	}
+
+	if dryRun || result.Valid != result.Total {
+		return result, nil
+	}
+
+	ids, err := s.repo.CreateMany(models)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	result.Created = len(ids)
+
+	// все строки корректны, порядок ids совпадает с порядком строк
+	for i, id := range ids {
+		created, err := s.repo.GetById(id)
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		result.Rows[i].Shipping = &created
+	}
+
+	return result, nil
+}
+
+func (s *usecase) importModel(row ImportRow, userId int) (Db, map[string]string, error) {
+	model := Db{
+		Author:       userId,
+		CustomNumber: row.CustomNumber,
+		CreateDate:   row.CreateDate,
+	}
+	errs := map[string]string{}
+
+	if number, err := strconv.Atoi(row.Number); err != nil {
+		errs["number"] = "Должно быть целым числом"
+	} else {
+		model.Number = number
+	}
+
+	if row.Transport == "" {
+		errs["transport"] = "Не указан транспорт"
+	} else if transport, err := s.usecase.Transport.GetDbByRegistrationNumberOrTitle(row.Transport); err == nil {
+		model.Transport = transport.Id
+	} else if errors.Is(err, app_error.ErrNotFound) {
+		errs["transport"] = fmt.Sprintf(`Транспорт "%s" не найден`, row.Transport)
+	} else if appErr, ok := err.(*app_error.AppError); ok && appErr.GetHttpCode() == app_error.ErrValidation.GetHttpCode() {
+		errs["transport"] = appErr.Error()
+	} else {
+		return model, nil, err
+	}
+
+	if row.Route == "" {
+		errs["route"] = "Не указан маршрут"
+	} else if route, err := s.usecase.Route.GetDbByTitle(row.Route); err == nil {
+		model.Route = route.Id
+	} else if errors.Is(err, app_error.ErrNotFound) {
+		errs["route"] = fmt.Sprintf(`Маршрут "%s" не найден`, row.Route)
+	} else {
+		return model, nil, err
+	}
+
+	if len(errs) > 0 {
+		return model, errs, nil
+	}
+
+	validateErrs, err := s.validate(model)
+
+	return model, validateErrs, err
+}
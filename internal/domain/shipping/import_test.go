@@ -0,0 +1,140 @@
+package shipping
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportRows(t *testing.T) {
+	header := []string{"Custom_Number", "create_date", "number", "transport", "route"}
+	row := []string{"10000", "01.01.2024", "1", "A001AA", "Москва"}
+	blank := []string{"", " ", "", "", ""}
+
+	t.Run("пустые строки пропускаются", func(t *testing.T) {
+		table := [][]string{header, blank, row, blank}
+
+		rows, err := ParseImportRows(table)
+
+		assert.NoError(t, err)
+		assert.Len(t, rows, 1)
+		assert.Equal(t, 3, rows[0].Line)
+		assert.Equal(t, "A001AA", rows[0].Transport)
+	})
+
+	t.Run("пустые строки не входят в ограничение", func(t *testing.T) {
+		table := [][]string{header}
+		for i := 0; i < IMPORT_MAX_ROWS; i++ {
+			table = append(table, row, blank)
+		}
+
+		rows, err := ParseImportRows(table)
+
+		assert.NoError(t, err)
+		assert.Len(t, rows, IMPORT_MAX_ROWS)
+	})
+
+	t.Run("превышено ограничение", func(t *testing.T) {
+		table := [][]string{header}
+		for i := 0; i <= IMPORT_MAX_ROWS; i++ {
+			table = append(table, row)
+		}
+
+		_, err := ParseImportRows(table)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("только пустые строки", func(t *testing.T) {
+		_, err := ParseImportRows([][]string{header, blank})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("нет колонки", func(t *testing.T) {
+		_, err := ParseImportRows([][]string{header[:4], row})
+
+		assert.ErrorContains(t, err, "route")
+		assert.False(t, strings.Contains(err.Error(), "number"))
+	})
+}
+
+func TestImport(t *testing.T) {
+	row := func(line int, customNumber, number, transport, route string) ImportRow {
+		return ImportRow{Line: line, CustomNumber: customNumber, CreateDate: "010124", Number: number,
+			Transport: transport, Route: route}
+	}
+	valid := []ImportRow{
+		row(2, "00000001", "1", "A001AA", "Москва - Казань"),
+		row(3, "00000002", "2", "A001AA", "Москва - Казань"),
+	}
+
+	t.Run("проверка без создания", func(t *testing.T) {
+		repo := newFakeRepo()
+		s := newTestUsecase(repo, nil)
+
+		res, err := s.Import(valid, 1, true)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, res.Valid)
+		assert.Equal(t, 0, res.Created)
+		assert.Empty(t, repo.batches)
+	})
+
+	t.Run("все строки создаются одной транзакцией", func(t *testing.T) {
+		repo := newFakeRepo()
+		s := newTestUsecase(repo, nil)
+
+		res, err := s.Import(valid, 1, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, res.Created)
+		require.Len(t, repo.batches, 1)
+		assert.Len(t, repo.batches[0], 2)
+
+		for i, r := range res.Rows {
+			require.NotNil(t, r.Shipping)
+			assert.Equal(t, valid[i].CustomNumber, r.Shipping.CustomNumber)
+			assert.Equal(t, 1, r.Shipping.Db.Route)
+			assert.Equal(t, 1, r.Shipping.Db.Transport)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		rows   []ImportRow
+		errors map[int][]string
+	}{
+		{"неизвестные транспорт и маршрут", []ImportRow{valid[0], row(3, "00000002", "2", "B002BB", "Нет")},
+			map[int][]string{1: {"transport", "route"}}},
+		{"номер не число", []ImportRow{row(2, "00000001", "x", "A001AA", "Москва - Казань")},
+			map[int][]string{0: {"number"}}},
+		{"повтор строки в файле", []ImportRow{valid[0], valid[0]}, map[int][]string{1: {"number"}}},
+		{"перевозка уже есть", []ImportRow{valid[0], row(3, "00000099", "2", "A001AA", "Москва - Казань")},
+			map[int][]string{1: {"custom_number", "create_date", "number"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			repo.unique["00000099"] = true
+			s := newTestUsecase(repo, nil)
+
+			res, err := s.Import(tt.rows, 1, false)
+
+			require.NoError(t, err)
+			assert.Equal(t, 0, res.Created)
+			assert.Empty(t, repo.batches)
+			assert.Equal(t, len(tt.rows)-len(tt.errors), res.Valid)
+
+			for i, r := range res.Rows {
+				assert.Equal(t, len(tt.errors[i]) == 0, r.Valid)
+				for _, field := range tt.errors[i] {
+					assert.Contains(t, r.Errors, field)
+				}
+			}
+		})
+	}
+}
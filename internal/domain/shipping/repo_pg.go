@@ -3,6 +3,7 @@ package shipping
 import (
 	"context"
 	"errors"
+	"fmt"
 	app_interface "seal/internal/app/interface"
 	"seal/internal/repository/pg"
 	"seal/internal/repository/pg/query"
@@ -11,6 +12,16 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// etaJoinOn прогноз прибытия учитывается только для перевозок в пути
+var etaJoinOn = fmt.Sprintf("eta.shipping=s.id and s.status in (%d, %d)", STATUS_ACTIVE, STATUS_SUSPENDED)
+
+// estimatedArrivalSql прогноз прибытия, без прогноза - плановое время. Пока модем не выходит на связь,
+// прогноз не пересчитывается, поэтому до прибытия он не может быть раньше текущего момента
+const estimatedArrivalSql = `coalesce(
+	case when eta.remaining_distance > 0 then greatest(eta.arrival_time, now()) else eta.arrival_time end,
+	coalesce(s.time_start, now()) + (r.travel_time * interval '1 minute')
+)`
+
 type repo struct {
 	db     pg.DbClient
 	logger app_interface.Logger
@@ -41,6 +52,46 @@ func (r *repo) Create(sh Db) (Shipping, error) {
 	return r.GetById(sh.Id)
 }
 
+// CreateMany создаёт перевозки в одной транзакции: при ошибке любой строки не создаётся ни одна
+func (r *repo) CreateMany(list []Db) ([]int, error) {
+	var err error
+	var tx pgx.Tx
+
+	if tx, err = r.db.Begin(r.ctx); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(r.ctx)
+		}
+	}()
+
+	q := `INSERT INTO shipping
+		(author, custom_number, create_date, number, transport, route)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	ids := make([]int, len(list))
+
+	for i, sh := range list {
+		qp := []any{sh.Author, sh.CustomNumber, sh.CreateDate, sh.Number, sh.Transport, sh.Route}
+
+		err = tx.QueryRow(r.ctx, q, qp...).Scan(&ids[i])
+		r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(ids[i]).SetError(err).GetMsg())
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(r.ctx); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
 func (r *repo) Update(sh Db) (Shipping, error) {
 	q := `UPDATE shipping 
 		set (author, custom_number, create_date, number, transport, route, status, time_start, time_end, files, modem) = 
@@ -63,6 +114,78 @@ func (r *repo) Update(sh Db) (Shipping, error) {
 	return r.GetById(sh.Id)
 }
 
+func (r *repo) Transit(sh Db, history HistoryDb) (Shipping, error) {
+	var err error
+	var tx pgx.Tx
+
+	if tx, err = r.db.Begin(r.ctx); err != nil {
+		return Shipping{}, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(r.ctx)
+		}
+	}()
+
+	q := `UPDATE shipping
+		set (status, time_start, time_end) = ($2, $3, $4)
+		where id = $1
+		RETURNING id
+	`
+
+	qp := []any{sh.Id, sh.Status, sh.TimeStart, sh.TimeEnd}
+
+	logSql := query.NewLogSql(q, qp...)
+
+	if err = tx.QueryRow(r.ctx, q, qp...).Scan(&sh.Id); err != nil {
+		r.logger.Error(logSql.SetError(err).GetMsg())
+		return Shipping{}, err
+	}
+
+	qh := `INSERT INTO shipping_history
+		(shipping, author, status_from, status_to, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	qhp := []any{history.Shipping, history.Author, history.StatusFrom, history.StatusTo, history.Reason}
+
+	logSqlHistory := query.NewLogSql(qh, qhp...)
+
+	if _, err = tx.Exec(r.ctx, qh, qhp...); err != nil {
+		r.logger.Error(logSqlHistory.SetError(err).GetMsg())
+		return Shipping{}, err
+	}
+
+	if err = tx.Commit(r.ctx); err != nil {
+		return Shipping{}, err
+	}
+
+	r.logger.Debug(logSql.SetResult(sh.Id).GetMsg())
+	r.logger.Debug(logSqlHistory.SetResult("ok").GetMsg())
+
+	return r.GetById(sh.Id)
+}
+
+func (r *repo) History(id int) ([]History, error) {
+	q := query.New[History](r.ctx, r.db).
+		Select("h.id", "").
+		AddSelect("h.created_at", "").
+		AddSelect("jsonb_build_object('id', u.id, 'login', u.login)", "author").
+		AddSelect("h.status_from", "").
+		AddSelect("h.status_to", "").
+		AddSelect("h.reason", "").
+		From("shipping_history", "h").
+		LeftJoin("u", "users", "u.id=h.author").
+		Where(query.EQUEL, "h.shipping", id).
+		OrderBy("h.created_at, h.id")
+
+	data, err := q.All()
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
 func (r *repo) GetById(id int) (Shipping, error) {
 	q := query.New[Shipping](r.ctx, r.db).
 		Select("s.id", "").
@@ -74,24 +197,28 @@ func (r *repo) GetById(id int) (Shipping, error) {
 		AddSelect("s.time_start", "").
 		AddSelect("s.time_end", "").
 		AddSelect("s.files", "").
-		AddSelect("coalesce(s.time_start, now()) + (r.travel_time * interval '1 minute')", "estimated_arrival_time").
+		AddSelect("coalesce(s.time_start, now()) + (r.travel_time * interval '1 minute')", "planned_arrival_time").
+		AddSelect(estimatedArrivalSql, "estimated_arrival_time").
+		AddSelect("eta.remaining_distance", "").
+		AddSelect("eta.calculated_at", "eta_calculated_at").
+		AddSelect("round(od.distance / 1000.0, 1)", "driven_distance").
+		AddSelect("od.moving_time", "").
 		AddSelect("jsonb_build_object('id', u.id, 'login', u.login)", "author").
 		AddSelect("(to_jsonb(t.*) || jsonb_build_object('type', to_jsonb(tt.*)))", "transport").
 		AddSelect("to_jsonb(r.*)", "route").
 		AddSelect("to_jsonb(m.*) || jsonb_build_object('last', to_jsonb(ml.*))", "modem").
-		AddSelect("(with r as (select distinct seal as seal_id from seals_data sd "+
-			//"where sd.dev_time >= coalesce(s.time_start, s.created_at) and sd.dev_time < coalesce(s.time_end, now()) "+
-			"where sd.dev_time >= s.created_at and sd.dev_time < coalesce(s.time_end, now()) "+
-			"and modem = s.modem) "+
-			"select coalesce(jsonb_agg(jsonb_build_object('id', seals.id, 'serial', seals.serial, 'last', to_jsonb(sd.*)) "+
+		AddSelect("(select coalesce(jsonb_agg(jsonb_build_object('id', seals.id, 'serial', seals.serial, 'last', to_jsonb(sd.*)) "+
 			"order by seals.serial), '[]') "+
-			"from r inner join seals on seals.id = r.seal_id "+
-			"left join lateral (select * from seals_data where seal = seals.id and modem = m.id order by dev_time desc limit 1) sd ON true)", "seals").
+			"from shipping_seals ss inner join seals on seals.id = ss.seal "+
+			"left join lateral (select * from seals_data where seal = seals.id and modem = m.id order by dev_time desc limit 1) sd ON true "+
+			"where ss.shipping = s.id)", "seals").
 		From("shipping", "s").
 		LeftJoin("u", "users", "u.id=s.author").
 		LeftJoin("t", "transports", "t.id=s.transport").
 		LeftJoin("tt", "transport_types", `tt.id=t.type`).
 		LeftJoin("r", "routes", "r.id=s.route").
+		LeftJoin("eta", "shipping_eta", etaJoinOn).
+		LeftJoin("od", "shipping_odometer", "od.shipping=s.id").
 		LeftJoin("m", "modems", "m.id=s.modem").
 		LeftJoin("ml", "modems_data", "ml.dev_time = m.last_dev_time and ml.modem = m.id").
 		Where(query.EQUEL, "s.id", id)
@@ -156,7 +283,8 @@ func (r *repo) List(params QueryParams) (query.List[ShippingForList], error) {
 		AddSelect("s.status", "").
 		//AddSelect("s.time_start", "").
 		//AddSelect("s.time_end", "").
-		AddSelect("coalesce(s.time_start, now()) + (r.travel_time * interval '1 minute')", "estimated_arrival_time").
+		AddSelect("coalesce(s.time_start, now()) + (r.travel_time * interval '1 minute')", "planned_arrival_time").
+		AddSelect(estimatedArrivalSql, "estimated_arrival_time").
 		AddSelect("s.files", "").
 		//AddSelect("jsonb_build_object('id', u.id, 'login', u.login)", "author").
 		AddSelect("(to_jsonb(t.*) || jsonb_build_object('type', to_jsonb(tt.*)))", "transport").
@@ -166,6 +294,7 @@ func (r *repo) List(params QueryParams) (query.List[ShippingForList], error) {
 		LeftJoin("t", "transports", "t.id=s.transport").
 		LeftJoin("tt", "transport_types", `tt.id=t.type`).
 		LeftJoin("r", "routes", "r.id=s.route").
+		LeftJoin("eta", "shipping_eta", etaJoinOn).
 		FilterWhere(params.FindType, "custom_number", params.Find).
 		OrFilterWhere(params.FindType, "create_date", params.Find).
 		OrFilterWhere(params.FindType, "number", params.Find).
@@ -207,6 +336,68 @@ func (r *repo) ExistsByUnique(customNumber, createDate string, id, number int) (
 	return data, err
 }
 
+func (r *repo) SealIds(id int) ([]int, error) {
+	q := `SELECT seal FROM shipping_seals where shipping = $1 order by seal`
+
+	rows, err := r.db.Query(r.ctx, q, id)
+	if err != nil {
+		r.logger.Error(query.NewLogSql(q, id).SetError(err).GetMsg())
+		return nil, err
+	}
+
+	data, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	r.logger.DebugOrError(err, query.NewLogSql(q, id).SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) AttachSeal(id, sealId, author int) error {
+	q := `INSERT INTO shipping_seals (shipping, seal, author) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+
+	_, err := r.db.Exec(r.ctx, q, id, sealId, author)
+	r.logger.DebugOrError(err, query.NewLogSql(q, id, sealId, author).SetError(err).GetMsg())
+
+	return err
+}
+
+func (r *repo) DetachSeal(id, sealId int) (bool, error) {
+	q := `DELETE FROM shipping_seals where shipping = $1 and seal = $2`
+
+	commandTag, err := r.db.Exec(r.ctx, q, id, sealId)
+	r.logger.DebugOrError(err, query.NewLogSql(q, id, sealId).SetResult(commandTag.RowsAffected() > 0).SetError(err).GetMsg())
+	return commandTag.RowsAffected() > 0, err
+}
+
+// DetachForeignSeals отвязывает пломбы, серийных номеров которых нет в списке модема
+func (r *repo) DetachForeignSeals(id int, serials []uint64) error {
+	q := `DELETE FROM shipping_seals ss using seals where ss.shipping = $1 and seals.id = ss.seal 
+		and not seals.serial = any($2)`
+
+	if serials == nil {
+		serials = []uint64{}
+	}
+
+	_, err := r.db.Exec(r.ctx, q, id, serials)
+	r.logger.DebugOrError(err, query.NewLogSql(q, id, serials).SetError(err).GetMsg())
+
+	return err
+}
+
+// SealInUse привязана ли пломба к другой незавершённой перевозке
+func (r *repo) SealInUse(sealId, exceptId int) (bool, error) {
+	q := fmt.Sprintf(`select exists(
+			select 1 from shipping_seals ss
+			join shipping s on s.id = ss.shipping
+			where ss.seal = $1 and s.id <> $2 and s.status in (%d, %d, %d)
+		)`, STATUS_NEW, STATUS_ACTIVE, STATUS_SUSPENDED)
+
+	var data bool
+	err := r.db.QueryRow(r.ctx, q, sealId, exceptId).Scan(&data)
+	r.logger.DebugOrError(err, query.NewLogSql(q, sealId, exceptId).SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
 func (r *repo) DeleteById(id int) (bool, error) {
 	q := `DELETE FROM shipping where id = $1`
 
@@ -0,0 +1,69 @@
+package shipping
+
+import (
+	"fmt"
+	"seal/pkg/app_error"
+	"slices"
+)
+
+// AttachSeal привязывает пломбу к новой перевозке. Пломба должна быть в списке пломб модема перевозки
+// и не должна быть привязана к другой незавершённой перевозке
+func (s *usecase) AttachSeal(shippingId, sealId, userId int) (Shipping, error) {
+	shipping, err := s.GetDbById(shippingId)
+	if err != nil {
+		return Shipping{}, err
+	}
+
+	if shipping.Status != STATUS_NEW {
+		return Shipping{}, app_error.ValidationError(`Привязать пломбу можно только к перевозке со статусом "Новый"`)
+	}
+
+	if shipping.Modem == nil {
+		return Shipping{}, app_error.ValidationError(`К перевозке не привязан модем`)
+	}
+
+	seal, err := s.usecase.Seal.GetDbById(sealId)
+	if err != nil {
+		return Shipping{}, err
+	}
+
+	modem, err := s.usecase.Modem.GetById(*shipping.Modem)
+	if err != nil {
+		return Shipping{}, err
+	}
+
+	if !slices.Contains(modem.SerialsOfSeals, seal.Serial) {
+		return Shipping{}, app_error.ValidationError(fmt.Sprintf(`Пломба %d не зарегистрирована на модеме %d`, seal.Serial, modem.Serial))
+	}
+
+	if inUse, err := s.repo.SealInUse(seal.Id, shipping.Id); err != nil {
+		return Shipping{}, err
+	} else if inUse {
+		return Shipping{}, app_error.ValidationError(fmt.Sprintf(`Пломба %d привязана к другой перевозке`, seal.Serial))
+	}
+
+	if err := s.repo.AttachSeal(shipping.Id, seal.Id, userId); err != nil {
+		return Shipping{}, err
+	}
+
+	return s.GetById(shipping.Id)
+}
+
+func (s *usecase) DetachSeal(shippingId, sealId int) (Shipping, error) {
+	shipping, err := s.GetDbById(shippingId)
+	if err != nil {
+		return Shipping{}, err
+	}
+
+	if shipping.Status != STATUS_NEW {
+		return Shipping{}, app_error.ValidationError(`Отвязать пломбу можно только от перевозки со статусом "Новый"`)
+	}
+
+	if ok, err := s.repo.DetachSeal(shipping.Id, sealId); err != nil {
+		return Shipping{}, err
+	} else if !ok {
+		return Shipping{}, app_error.ErrNotFound
+	}
+
+	return s.GetById(shipping.Id)
+}
@@ -0,0 +1,170 @@
+package shipping
+
+import (
+	"context"
+	"net/http"
+	"seal/internal/domain/modem"
+	"seal/internal/domain/seal"
+	"seal/internal/repository/pg"
+	"seal/pkg/app_error"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct{}
+
+func (testLogger) Fatal(msg string, args ...any)                   {}
+func (testLogger) Error(msg string, args ...any)                   {}
+func (testLogger) Info(msg string, args ...any)                    {}
+func (testLogger) Debug(msg string, args ...any)                   {}
+func (testLogger) DebugOrError(err error, msg string, args ...any) {}
+
+// fakeDb отдаёт на QueryRow одно значение и запоминает запрос
+type fakeDb struct {
+	pg.DbClient
+	value any
+	sql   string
+	args  []any
+}
+
+func (db *fakeDb) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.sql, db.args = sql, args
+	return fakeRow{db.value}
+}
+
+type fakeRow struct {
+	value any
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	*dest[0].(*bool) = r.value.(bool)
+	return nil
+}
+
+func TestSealInUse(t *testing.T) {
+	db := &fakeDb{value: true}
+	r := NewRepo(context.Background(), db, testLogger{})
+
+	inUse, err := r.SealInUse(3, 7)
+
+	require.NoError(t, err)
+	assert.True(t, inUse)
+	assert.Equal(t, []any{3, 7}, db.args)
+	assert.Contains(t, db.sql, "join shipping s on s.id = ss.shipping")
+	assert.Contains(t, db.sql, "s.status in (0, 1, 4)")
+}
+
+type sealsRepo struct {
+	Repo
+	shipping Db
+	inUse    bool
+	attached []int
+}
+
+func (r *sealsRepo) GetDbById(id int) (Db, error) {
+	if id != r.shipping.Id {
+		return Db{}, app_error.ErrNotFound
+	}
+
+	return r.shipping, nil
+}
+
+func (r *sealsRepo) GetById(id int) (Shipping, error) {
+	sh := Shipping{Db: r.shipping}
+	sh.Seals = slices.Grow(sh.Seals, len(r.attached))[:len(r.attached)]
+
+	for i, sealId := range r.attached {
+		sh.Seals[i].Id = sealId
+	}
+
+	return sh, nil
+}
+
+func (r *sealsRepo) SealInUse(sealId, exceptId int) (bool, error) {
+	return r.inUse, nil
+}
+
+func (r *sealsRepo) AttachSeal(id, sealId, author int) error {
+	r.attached = append(r.attached, sealId)
+	return nil
+}
+
+func (r *sealsRepo) DetachSeal(id, sealId int) (bool, error) {
+	for i, v := range r.attached {
+		if v == sealId {
+			r.attached = append(r.attached[:i], r.attached[i+1:]...)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type sealsSeal struct {
+	seal.Usecase
+}
+
+func (sealsSeal) GetDbById(id int) (seal.Db, error) {
+	return seal.Db{Id: id, Serial: uint64(100 + id)}, nil
+}
+
+type sealsModem struct {
+	modem.Usecase
+}
+
+func (sealsModem) GetById(id int) (modem.Modem, error) {
+	return modem.Modem{Id: id, Serial: 55, SerialsOfSeals: []uint64{101, 102}}, nil
+}
+
+func TestAttachSeal(t *testing.T) {
+	modemId := 9
+	newService := func(status int, inUse bool) (*usecase, *sealsRepo) {
+		repo := &sealsRepo{shipping: Db{Id: 1, Status: status, Modem: &modemId}, inUse: inUse}
+		return &usecase{repo: repo, usecase: CoreUseCase{Seal: sealsSeal{}, Modem: sealsModem{}}}, repo
+	}
+
+	t.Run("привязка и отвязка", func(t *testing.T) {
+		s, repo := newService(STATUS_NEW, false)
+
+		sh, err := s.AttachSeal(1, 1, 5)
+		require.NoError(t, err)
+		require.Len(t, sh.Seals, 1)
+		assert.Equal(t, 1, sh.Seals[0].Id)
+
+		sh, err = s.DetachSeal(1, 1)
+		require.NoError(t, err)
+		assert.Empty(t, sh.Seals)
+		assert.Empty(t, repo.attached)
+
+		_, err = s.DetachSeal(1, 1)
+		assert.ErrorIs(t, err, app_error.ErrNotFound)
+	})
+
+	tests := []struct {
+		name   string
+		status int
+		inUse  bool
+		sealId int
+	}{
+		{"перевозка не новая", STATUS_ACTIVE, false, 1},
+		{"пломба не зарегистрирована на модеме", STATUS_NEW, false, 3},
+		{"пломба привязана к другой перевозке", STATUS_NEW, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newService(tt.status, tt.inUse)
+
+			_, err := s.AttachSeal(1, tt.sealId, 5)
+
+			var appErr *app_error.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, http.StatusUnprocessableEntity, appErr.GetHttpCode())
+			assert.Empty(t, repo.attached)
+		})
+	}
+}
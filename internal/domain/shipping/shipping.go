@@ -1,19 +1,23 @@
 package shipping
 
 import (
+	"io"
 	"seal/internal/domain/route"
+	"seal/internal/domain/shipping_status"
 	"seal/internal/repository/pg/query"
 	transp "seal/internal/transport"
+	"seal/pkg/track_export"
 	"time"
 )
 
 type File struct {
-	Name     string `json:"name"`
-	Title    string `json:"title"`
-	Comment  string `json:"comment,omitempty"`
-	SealId   int    `json:"seal_id,omitempty"`
-	Type     int    `json:"type"`
-	Checksum string `json:"checksum"`
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Comment     string `json:"comment,omitempty"`
+	SealId      int    `json:"seal_id,omitempty"`
+	Type        int    `json:"type"`
+	Checksum    string `json:"checksum"`
+	UploadToken int    `json:"upload_token,omitempty"` // ключ загрузки водителя, по которому загружен файл
 }
 
 type Db struct {
@@ -27,14 +31,16 @@ type Db struct {
 	Number       int        `json:"number" validate:"required,max=2147483647,min=1"`
 	Transport    int        `json:"transport" validate:"required,max=2147483647,min=1"`
 	Route        int        `json:"route" validate:"required,max=2147483647,min=1"`
-	Status       int        `json:"status" validate:"max=2,min=0"`
+	Status       int        `json:"status" validate:"max=4,min=0"`
 	Files        []File     `json:"files" db:"files"`
 	Modem        *int       `json:"modem" db:"modem"`
 }
 
-const STATUS_NEW = 0
-const STATUS_ACTIVE = 1
-const STATUS_END = 2
+const STATUS_NEW = shipping_status.NEW
+const STATUS_ACTIVE = shipping_status.ACTIVE
+const STATUS_END = shipping_status.END
+const STATUS_CANCELLED = shipping_status.CANCELLED
+const STATUS_SUSPENDED = shipping_status.SUSPENDED
 
 type QueryParams struct {
 	transp.QueryParams
@@ -50,7 +56,10 @@ type TelemetryQueryParams struct {
 
 type Repo interface {
 	Create(data Db) (Shipping, error)
+	CreateMany(list []Db) ([]int, error)
 	Update(data Db) (Shipping, error)
+	Transit(data Db, history HistoryDb) (Shipping, error)
+	History(id int) ([]History, error)
 	GetDbById(id int) (Db, error)
 	GetById(id int) (Shipping, error)
 	GetActiveByModemId(id int) (Shipping, error)
@@ -58,6 +67,11 @@ type Repo interface {
 	Exists(id int) (bool, error)
 	ExistsByUnique(string, string, int, int) (bool, error)
 	DeleteById(id int) (bool, error)
+	SealIds(id int) ([]int, error)
+	AttachSeal(id, sealId, author int) error
+	DetachSeal(id, sealId int) (bool, error)
+	DetachForeignSeals(id int, serials []uint64) error
+	SealInUse(sealId, exceptId int) (bool, error)
 }
 
 type Usecase interface {
@@ -71,16 +85,31 @@ type Usecase interface {
 	ExistsByUnique(string, string, int, int) (bool, error)
 	DeleteById(id int) (bool, error)
 	Route(id int) (route.Route, error)
-	Start(data Db) (Shipping, error)
-	End(data Db) (Shipping, error)
+	Start(data Db, userId int, reason string) (Shipping, error)
+	Suspend(data Db, userId int, reason string) (Shipping, error)
+	Resume(data Db, userId int, reason string) (Shipping, error)
+	End(data Db, userId int, reason string) (Shipping, error)
+	Cancel(data Db, userId int, reason string) (Shipping, error)
+	History(id int) ([]History, error)
+	Pauses(id int) ([]Pause, error)
 	AddFiles(id int, files []File) (Shipping, error)
 	RemoveFile(id int, name string) error
-	RemoveFilesFromDisk(id int, files []File) error
-	GetFilesDirectory(id int) string
+	RemoveFilesFromStorage(id int, files []File) error
+	ValidateUploads(id int, fileType int, uploads []Upload) error
+	CheckFileSeal(id int, sealId int) error
+	NewFileName(id int, title string) (string, error)
+	UploadFile(id int, name string, r io.Reader, size int64, contentType string) error
+	OpenFile(id int, name string) (io.ReadCloser, int64, error)
 	GetFileInfo(id int, name string) (File, error)
 	UpdateFileInfo(id int, name string, data UpdateFileRequest) (Shipping, error)
+	BundleFiles(id int, params BundleQueryParams) ([]File, error)
+	WriteBundle(w io.Writer, id int, files []File) error
 	SetModemById(shippingId int, modemId int) (bool, error)
 	SetModemByImei(shippingId int, imei uint64) (bool, error)
+	AttachSeal(shippingId, sealId, userId int) (Shipping, error)
+	DetachSeal(shippingId, sealId int) (Shipping, error)
 	Coordinates(params TrackQueryParams) ([]trackResponseCoordinate, error)
 	Telemetry(params TrackQueryParams) ([]trackResponseTelemetry, error)
+	ExportTrack(params TrackQueryParams) (track_export.Track, error)
+	Import(rows []ImportRow, userId int, dryRun bool) (ImportResult, error)
 }
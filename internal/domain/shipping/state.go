@@ -0,0 +1,150 @@
+package shipping
+
+import (
+	"fmt"
+	"seal/pkg/app_error"
+	"slices"
+	"time"
+)
+
+const ACTION_START = "start"
+const ACTION_SUSPEND = "suspend"
+const ACTION_RESUME = "resume"
+const ACTION_END = "end"
+const ACTION_CANCEL = "cancel"
+
+type transition struct {
+	from  []int
+	to    int
+	title string
+}
+
+// transitions допустимые переходы между статусами перевозки
+var transitions = map[string]transition{
+	ACTION_START:   {[]int{STATUS_NEW}, STATUS_ACTIVE, "Начать"},
+	ACTION_SUSPEND: {[]int{STATUS_ACTIVE}, STATUS_SUSPENDED, "Приостановить"},
+	ACTION_RESUME:  {[]int{STATUS_SUSPENDED}, STATUS_ACTIVE, "Возобновить"},
+	ACTION_END:     {[]int{STATUS_ACTIVE, STATUS_SUSPENDED}, STATUS_END, "Завершить"},
+	ACTION_CANCEL:  {[]int{STATUS_NEW, STATUS_ACTIVE, STATUS_SUSPENDED}, STATUS_CANCELLED, "Отменить"},
+}
+
+var statusTitles = map[int]string{
+	STATUS_NEW:       "Новая",
+	STATUS_ACTIVE:    "Активная",
+	STATUS_END:       "Завершена",
+	STATUS_CANCELLED: "Отменена",
+	STATUS_SUSPENDED: "Приостановлена",
+}
+
+// StatusTitle наименование статуса перевозки
+func StatusTitle(status int) string {
+	return statusTitles[status]
+}
+
+func (s *usecase) Start(shipping Db, userId int, reason string) (Shipping, error) {
+	return s.transit(shipping, ACTION_START, userId, reason)
+}
+
+func (s *usecase) Suspend(shipping Db, userId int, reason string) (Shipping, error) {
+	return s.transit(shipping, ACTION_SUSPEND, userId, reason)
+}
+
+func (s *usecase) Resume(shipping Db, userId int, reason string) (Shipping, error) {
+	return s.transit(shipping, ACTION_RESUME, userId, reason)
+}
+
+func (s *usecase) End(shipping Db, userId int, reason string) (Shipping, error) {
+	return s.transit(shipping, ACTION_END, userId, reason)
+}
+
+func (s *usecase) Cancel(shipping Db, userId int, reason string) (Shipping, error) {
+	return s.transit(shipping, ACTION_CANCEL, userId, reason)
+}
+
+func (s *usecase) History(id int) ([]History, error) {
+	if exists, err := s.repo.Exists(id); err != nil {
+		return []History{}, err
+	} else if !exists {
+		return []History{}, app_error.ErrNotFound
+	}
+
+	return s.repo.History(id)
+}
+
+// Pauses интервалы приостановки перевозки, восстановленные из истории статусов
+func (s *usecase) Pauses(id int) ([]Pause, error) {
+	history, err := s.History(id)
+	if err != nil {
+		return []Pause{}, err
+	}
+
+	return pauses(history, time.Now()), nil
+}
+
+// pauses интервал начинается переходом в STATUS_SUSPENDED и заканчивается первым переходом из него
+func pauses(history []History, now time.Time) []Pause {
+	data := []Pause{}
+
+	for _, h := range history {
+		if h.StatusTo == STATUS_SUSPENDED {
+			data = append(data, Pause{TimeStart: h.CreatedAt, Reason: h.Reason})
+			continue
+		}
+
+		if h.StatusFrom == STATUS_SUSPENDED && len(data) > 0 && data[len(data)-1].TimeEnd == nil {
+			timeEnd := h.CreatedAt
+			data[len(data)-1].TimeEnd = &timeEnd
+		}
+	}
+
+	for i, p := range data {
+		end := now
+		if p.TimeEnd != nil {
+			end = *p.TimeEnd
+		}
+		data[i].Duration = int(end.Sub(p.TimeStart).Seconds())
+	}
+
+	return data
+}
+
+func (s *usecase) transit(shipping Db, action string, userId int, reason string) (Shipping, error) {
+	t, ok := transitions[action]
+	if !ok {
+		return Shipping{}, app_error.ValidationError(fmt.Sprintf(`Неизвестный переход "%s"`, action))
+	}
+
+	if !slices.Contains(t.from, shipping.Status) {
+		return Shipping{}, app_error.ValidationError(
+			fmt.Sprintf(`Действие "%s" недоступно для перевозки со статусом "%s"`, t.title, statusTitles[shipping.Status]))
+	}
+
+	history := HistoryDb{
+		Shipping:   shipping.Id,
+		Author:     userId,
+		StatusFrom: shipping.Status,
+		StatusTo:   t.to,
+		Reason:     reason,
+	}
+
+	now := time.Now()
+	shipping.Status = t.to
+
+	// время начала и окончания фиксируется один раз, остальные переходы остаются только в истории.
+	// Отмена не начатой перевозки время окончания не выставляет
+	if action == ACTION_START && shipping.TimeStart == nil {
+		shipping.TimeStart = &now
+	}
+
+	if (t.to == STATUS_END || t.to == STATUS_CANCELLED) && shipping.TimeStart != nil && shipping.TimeEnd == nil {
+		shipping.TimeEnd = &now
+	}
+
+	if errs, err := s.validate(shipping); err != nil {
+		return Shipping{}, err
+	} else if len(errs) > 0 {
+		return Shipping{}, app_error.ValidationError(errs)
+	}
+
+	return s.repo.Transit(shipping, history)
+}
@@ -0,0 +1,111 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauses(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return t0.Add(time.Duration(minutes) * time.Minute) }
+	step := func(minutes, from, to int) History {
+		return History{CreatedAt: at(minutes), StatusFrom: from, StatusTo: to, Reason: "r"}
+	}
+
+	tests := []struct {
+		name      string
+		history   []History
+		durations []int
+		open      bool
+	}{
+		{"без приостановок", []History{step(0, STATUS_NEW, STATUS_ACTIVE), step(60, STATUS_ACTIVE, STATUS_END)}, []int{}, false},
+		{"приостановка и возобновление", []History{
+			step(0, STATUS_NEW, STATUS_ACTIVE),
+			step(10, STATUS_ACTIVE, STATUS_SUSPENDED),
+			step(40, STATUS_SUSPENDED, STATUS_ACTIVE),
+			step(50, STATUS_ACTIVE, STATUS_SUSPENDED),
+			step(55, STATUS_SUSPENDED, STATUS_END),
+		}, []int{30 * 60, 5 * 60}, false},
+		{"незавершённая приостановка", []History{
+			step(0, STATUS_NEW, STATUS_ACTIVE),
+			step(10, STATUS_ACTIVE, STATUS_SUSPENDED),
+		}, []int{110 * 60}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := pauses(tt.history, at(120))
+
+			durations := []int{}
+			for _, p := range data {
+				durations = append(durations, p.Duration)
+			}
+			assert.Equal(t, tt.durations, durations)
+
+			if len(data) > 0 {
+				assert.Equal(t, tt.open, data[len(data)-1].TimeEnd == nil)
+			}
+		})
+	}
+}
+
+func TestTransit(t *testing.T) {
+	base := Db{Id: 1, Author: 1, CustomNumber: "00123456", CreateDate: "010124", Number: 1, Transport: 1, Route: 1}
+	started := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	withStatus := func(status int, timeStart *time.Time) Db {
+		sh := base
+		sh.Status, sh.TimeStart = status, timeStart
+		return sh
+	}
+
+	tests := []struct {
+		name    string
+		action  string
+		from    Db
+		to      int
+		allowed bool
+		timeEnd bool
+	}{
+		{"начало", ACTION_START, withStatus(STATUS_NEW, nil), STATUS_ACTIVE, true, false},
+		{"повторное начало", ACTION_START, withStatus(STATUS_ACTIVE, &started), 0, false, false},
+		{"приостановка", ACTION_SUSPEND, withStatus(STATUS_ACTIVE, &started), STATUS_SUSPENDED, true, false},
+		{"приостановка новой", ACTION_SUSPEND, withStatus(STATUS_NEW, nil), 0, false, false},
+		{"возобновление", ACTION_RESUME, withStatus(STATUS_SUSPENDED, &started), STATUS_ACTIVE, true, false},
+		{"возобновление активной", ACTION_RESUME, withStatus(STATUS_ACTIVE, &started), 0, false, false},
+		{"завершение приостановленной", ACTION_END, withStatus(STATUS_SUSPENDED, &started), STATUS_END, true, true},
+		{"отмена новой", ACTION_CANCEL, withStatus(STATUS_NEW, nil), STATUS_CANCELLED, true, false},
+		{"отмена завершённой", ACTION_CANCEL, withStatus(STATUS_END, &started), 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo(tt.from)
+			s := newTestUsecase(repo, nil)
+
+			data, err := s.transit(tt.from, tt.action, 7, "таможенный досмотр")
+
+			if !tt.allowed {
+				assert.Error(t, err)
+				assert.Empty(t, repo.history)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, data.Status)
+			assert.Equal(t, tt.action != ACTION_CANCEL, data.TimeStart != nil)
+			assert.Equal(t, tt.timeEnd, data.TimeEnd != nil)
+
+			if tt.from.TimeStart != nil {
+				assert.Equal(t, started, *data.TimeStart)
+			}
+
+			require.Len(t, repo.history, 1)
+			assert.Equal(t, HistoryDb{Shipping: 1, Author: 7, StatusFrom: tt.from.Status, StatusTo: tt.to,
+				Reason: "таможенный досмотр"}, repo.history[0])
+		})
+	}
+}
@@ -1,29 +1,35 @@
 package shipping
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"path/filepath"
 	app_interface "seal/internal/app/interface"
 	"seal/internal/domain/modem"
 	modemData "seal/internal/domain/modem_data"
 	"seal/internal/domain/route"
 	"seal/internal/domain/seal"
+	"seal/internal/domain/status_flag"
 	transp "seal/internal/domain/transport"
 	"seal/internal/domain/user"
 	"seal/internal/repository/pg/query"
 	"seal/pkg/app_error"
+	"seal/pkg/storage"
 	"seal/pkg/utils"
+	"slices"
 	"strconv"
 	"time"
 )
 
 type CoreUseCase struct {
-	User      user.Usecase
-	Route     route.Usecase
-	Seal      seal.Usecase
-	Transport transp.Usecase
-	Modem     modem.Usecase
-	ModemData modemData.Usecase
+	User       user.Usecase
+	Route      route.Usecase
+	Seal       seal.Usecase
+	Transport  transp.Usecase
+	Modem      modem.Usecase
+	ModemData  modemData.Usecase
+	StatusFlag status_flag.Usecase
 }
 
 type usecase struct {
@@ -31,11 +37,11 @@ type usecase struct {
 	logger    app_interface.Logger
 	validator app_interface.Validator
 	usecase   CoreUseCase
-	filesPath string
+	storage   storage.Storage
 }
 
-func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, filesPath string, coreUsecase CoreUseCase) Usecase {
-	return &usecase{repo, logger, validator, coreUsecase, filesPath}
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, storage storage.Storage, coreUsecase CoreUseCase) Usecase {
+	return &usecase{repo, logger, validator, coreUsecase, storage}
 }
 
 func (s *usecase) Create(data CreateRequest, userId int) (Shipping, error) {
@@ -76,42 +82,6 @@ func (s *usecase) Update(id int, data UpdateRequest) (Shipping, error) {
 	return s.repo.Update(shipping)
 }
 
-func (s *usecase) Start(shipping Db) (Shipping, error) {
-	if shipping.Status != STATUS_NEW {
-		return Shipping{}, app_error.ValidationError(`Начать можно только перевозку со статусом "Новый"`)
-	}
-
-	now := time.Now()
-	shipping.Status = STATUS_ACTIVE
-	shipping.TimeStart = &now
-
-	if errs, err := s.validate(shipping); err != nil {
-		return Shipping{}, err
-	} else if len(errs) > 0 {
-		return Shipping{}, app_error.ValidationError(errs)
-	}
-
-	return s.repo.Update(shipping)
-}
-
-func (s *usecase) End(shipping Db) (Shipping, error) {
-	if shipping.Status != STATUS_ACTIVE {
-		return Shipping{}, app_error.ValidationError(`Завершить можно только перевозку со статусом "Активная"`)
-	}
-
-	now := time.Now()
-	shipping.Status = STATUS_END
-	shipping.TimeEnd = &now
-
-	if errs, err := s.validate(shipping); err != nil {
-		return Shipping{}, err
-	} else if len(errs) > 0 {
-		return Shipping{}, app_error.ValidationError(errs)
-	}
-
-	return s.repo.Update(shipping)
-}
-
 func (s *usecase) GetById(id int) (Shipping, error) {
 	return s.repo.GetById(id)
 }
@@ -146,8 +116,7 @@ func (s *usecase) ExistsByUnique(customNumber, createDate string, id, number int
 }
 
 func (s *usecase) DeleteById(id int) (bool, error) {
-	filesDirectory := s.GetFilesDirectory(id)
-	if err := os.RemoveAll(filesDirectory); err != nil {
+	if err := s.storage.DeletePrefix(fmt.Sprintf("%d/", id)); err != nil {
 		return false, err
 	}
 
@@ -174,13 +143,11 @@ func (s *usecase) AddFiles(id int, files []File) (Shipping, error) {
 	return s.repo.Update(shipping)
 }
 
-func (s *usecase) RemoveFilesFromDisk(id int, files []File) error {
+func (s *usecase) RemoveFilesFromStorage(id int, files []File) error {
 	var res error
 
 	for _, file := range files {
-		filesDirectory := s.GetFilesDirectory(id)
-		filePath := fmt.Sprintf("%s/%s", filesDirectory, file.Name)
-		if err := os.Remove(filePath); err != nil {
+		if err := s.storage.Delete(fileKey(id, file.Name)); err != nil {
 			res = err
 		}
 	}
@@ -188,8 +155,38 @@ func (s *usecase) RemoveFilesFromDisk(id int, files []File) error {
 	return res
 }
 
-func (s *usecase) GetFilesDirectory(id int) string {
-	return fmt.Sprintf("%s/%d", s.filesPath, id)
+// NewFileName уникальное имя для загружаемого файла перевозки, расширение берётся из исходного имени
+func (s *usecase) NewFileName(id int, title string) (string, error) {
+	now := time.Now().UnixNano()
+	ext := filepath.Ext(title)
+
+	for i := 0; i <= 500; i++ {
+		name := fmt.Sprintf("%d%s", now, ext)
+		if i > 0 {
+			name = fmt.Sprintf("%d_%d%s", now, i, ext)
+		}
+
+		if exists, err := s.storage.Exists(fileKey(id, name)); err != nil {
+			return "", err
+		} else if !exists {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("file exists %s", fileKey(id, fmt.Sprintf("%d%s", now, ext)))
+}
+
+func (s *usecase) UploadFile(id int, name string, r io.Reader, size int64, contentType string) error {
+	return s.storage.Put(fileKey(id, name), r, size, contentType)
+}
+
+func (s *usecase) OpenFile(id int, name string) (io.ReadCloser, int64, error) {
+	r, info, err := s.storage.Get(fileKey(id, name))
+	if errors.Is(err, storage.ErrNotExist) {
+		return nil, 0, app_error.ErrNotFound
+	}
+
+	return r, info.Size, err
 }
 
 func (s *usecase) GetFileInfo(id int, name string) (File, error) {
@@ -201,9 +198,9 @@ func (s *usecase) GetFileInfo(id int, name string) (File, error) {
 
 	for _, file := range shipping.Files {
 		if file.Name == name {
-			filesDirectory := s.GetFilesDirectory(id)
-			filePath := fmt.Sprintf("%s/%s", filesDirectory, name)
-			if _, err := os.Stat(filePath); err == nil {
+			if exists, err := s.storage.Exists(fileKey(id, name)); err != nil {
+				return File{}, err
+			} else if exists {
 				return file, nil
 			} else {
 				return File{}, app_error.ErrNotFound
@@ -228,7 +225,7 @@ func (s *usecase) RemoveFile(id int, name string) error {
 				return err
 			}
 
-			if err := s.RemoveFilesFromDisk(id, []File{file}); err != nil {
+			if err := s.RemoveFilesFromStorage(id, []File{file}); err != nil {
 				return err
 			}
 
@@ -239,6 +236,10 @@ func (s *usecase) RemoveFile(id int, name string) error {
 	return app_error.ErrNotFound
 }
 
+func fileKey(id int, name string) string {
+	return fmt.Sprintf("%d/%s", id, name)
+}
+
 func (s *usecase) SetModemById(shippingId int, modemId int) (bool, error) {
 	shipping, err := s.GetDbById(shippingId)
 
@@ -268,6 +269,10 @@ func (s *usecase) SetModemById(shippingId int, modemId int) (bool, error) {
 		return false, err
 	}
 
+	if err := s.repo.DetachForeignSeals(shipping.Id, modem.SerialsOfSeals); err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
 
@@ -298,6 +303,10 @@ func (s *usecase) SetModemByImei(shippingId int, imei uint64) (bool, error) {
 		return false, err
 	}
 
+	if err := s.repo.DetachForeignSeals(shipping.Id, modem.SerialsOfSeals); err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
 
@@ -324,6 +333,8 @@ func (s *usecase) Coordinates(params TrackQueryParams) ([]trackResponseCoordinat
 		From:      timeFrom,
 		Limit:     params.Limit,
 		OrderDesc: params.OrderDesc,
+		Tolerance: params.Tolerance,
+		Zoom:      params.Zoom,
 	}
 
 	if shipping.TimeEnd != nil {
@@ -381,10 +392,15 @@ func (s *usecase) Telemetry(params TrackQueryParams) ([]trackResponseTelemetry,
 		return response, err
 	}
 
+	sealIds, err := s.repo.SealIds(shipping.Id)
+	if err != nil {
+		return response, err
+	}
+
 	for _, row := range arch {
 		var sealsData []trackResponseSealData
 		for _, data := range row.SealsData {
-			if data.Status == 0 && data.Errors == 0 {
+			if data.Status == 0 && data.Errors == 0 || !slices.Contains(sealIds, data.Seal.Id) {
 				continue
 			}
 
@@ -412,6 +428,12 @@ func (s *usecase) Telemetry(params TrackQueryParams) ([]trackResponseTelemetry,
 			Altitude:    row.Altitude,
 			SealsData:   sealsData,
 			ErrorsFlags: row.ErrorsFlags,
+			Flags: s.usecase.StatusFlag.Decode(int(row.Model), int(row.Protocol), status_flag.Values{
+				Status:          int64(row.Status),
+				StatusRaw:       int64(row.StatusRaw),
+				ErrorsFlags:     int64(row.ErrorsFlags),
+				ModemErrorsCode: int64(row.ModemErrorsCode),
+			}),
 		})
 	}
 
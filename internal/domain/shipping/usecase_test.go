@@ -0,0 +1,143 @@
+package shipping
+
+import (
+	"seal/internal/domain/route"
+	transp "seal/internal/domain/transport"
+	"seal/internal/domain/user"
+	"seal/pkg/app_error"
+	"seal/pkg/storage"
+	"seal/pkg/validator"
+	"sync"
+)
+
+// fakeRepo перевозки в памяти для проверки usecase без базы
+type fakeRepo struct {
+	Repo
+	mu      sync.Mutex
+	items   map[int]Db
+	unique  map[string]bool
+	history []HistoryDb
+	batches [][]Db
+	nextId  int
+}
+
+func newFakeRepo(items ...Db) *fakeRepo {
+	r := &fakeRepo{items: map[int]Db{}, unique: map[string]bool{}, nextId: 100}
+	for _, item := range items {
+		r.items[item.Id] = item
+	}
+
+	return r
+}
+
+func (r *fakeRepo) GetDbById(id int) (Db, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if item, ok := r.items[id]; ok {
+		return item, nil
+	}
+
+	return Db{}, app_error.ErrNotFound
+}
+
+func (r *fakeRepo) GetById(id int) (Shipping, error) {
+	item, err := r.GetDbById(id)
+	return Shipping{Db: item}, err
+}
+
+func (r *fakeRepo) ExistsByUnique(customNumber, createDate string, id, number int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.unique[customNumber], nil
+}
+
+func (r *fakeRepo) Transit(data Db, history HistoryDb) (Shipping, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items[data.Id] = data
+	r.history = append(r.history, history)
+
+	return Shipping{Db: data}, nil
+}
+
+func (r *fakeRepo) CreateMany(list []Db) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches = append(r.batches, list)
+
+	ids := make([]int, len(list))
+	for i, item := range list {
+		r.nextId++
+		item.Id = r.nextId
+		r.items[item.Id] = item
+		ids[i] = item.Id
+	}
+
+	return ids, nil
+}
+
+type fakeUser struct {
+	user.Usecase
+}
+
+func (fakeUser) Exists(id int) (bool, error) {
+	return id > 0, nil
+}
+
+// fakeRoute маршруты по названию, id маршрута - его позиция + 1
+type fakeRoute struct {
+	route.Usecase
+	titles []string
+}
+
+func (f fakeRoute) GetDbByTitle(title string) (route.Db, error) {
+	for i, v := range f.titles {
+		if v == title {
+			return route.Db{Id: i + 1, Title: v}, nil
+		}
+	}
+
+	return route.Db{}, app_error.ErrNotFound
+}
+
+func (f fakeRoute) Exists(id int) (bool, error) {
+	return id > 0 && id <= len(f.titles), nil
+}
+
+// fakeTransport транспорт по регистрационному номеру, id - позиция + 1
+type fakeTransport struct {
+	transp.Usecase
+	numbers []string
+}
+
+func (f fakeTransport) GetDbByRegistrationNumberOrTitle(value string) (transp.Db, error) {
+	for i, v := range f.numbers {
+		if v == value {
+			return transp.Db{Id: i + 1}, nil
+		}
+	}
+
+	return transp.Db{}, app_error.ErrNotFound
+}
+
+func (f fakeTransport) Exists(id int) (bool, error) {
+	return id > 0 && id <= len(f.numbers), nil
+}
+
+func newTestUsecase(repo *fakeRepo, store storage.Storage) *usecase {
+	return &usecase{
+		repo:      repo,
+		logger:    testLogger{},
+		validator: validator.NewValidator(),
+		storage:   store,
+		usecase: CoreUseCase{
+			User:      fakeUser{},
+			Route:     fakeRoute{titles: []string{"Москва - Казань"}},
+			Transport: fakeTransport{numbers: []string{"A001AA"}},
+		},
+	}
+}
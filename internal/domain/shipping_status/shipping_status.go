@@ -0,0 +1,9 @@
+// Package shipping_status статусы перевозки. Вынесены из shipping, чтобы их могли
+// использовать пакеты, от которых shipping сам зависит (modem, battery)
+package shipping_status
+
+const NEW = 0
+const ACTIVE = 1
+const END = 2
+const CANCELLED = 3
+const SUSPENDED = 4
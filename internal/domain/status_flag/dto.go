@@ -0,0 +1,34 @@
+package status_flag
+
+import "time"
+
+const FIELD_STATUS = "status"
+const FIELD_STATUS_RAW = "status_raw"
+const FIELD_ERRORS_FLAGS = "errors_flags"
+const FIELD_MODEM_ERRORS_CODE = "modem_errors_code"
+
+const SEVERITY_INFO = "info"
+const SEVERITY_WARNING = "warning"
+const SEVERITY_CRITICAL = "critical"
+
+// CODE_UNKNOWN установленный бит или код ошибки, которого нет в справочнике для модели и протокола
+const CODE_UNKNOWN = "unknown"
+
+// CACHE_TTL сколько справочник хранится в памяти, изменения в status_flags видны не позже
+const CACHE_TTL = 5 * time.Minute
+
+// Values сырые значения телеметрии модема
+type Values struct {
+	Status          int64
+	StatusRaw       int64
+	ErrorsFlags     int64
+	ModemErrorsCode int64
+}
+
+type Repo interface {
+	List() ([]Definition, error)
+}
+
+type Usecase interface {
+	Decode(model int, protocol int, values Values) Decoded
+}
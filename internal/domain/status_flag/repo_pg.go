@@ -0,0 +1,30 @@
+package status_flag
+
+import (
+	"context"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+func (r *repo) List() ([]Definition, error) {
+	q := query.New[Definition](r.ctx, r.db).
+		Select("f.*", "").
+		From("status_flags", "f").
+		OrderBy("f.field, f.value, f.id")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
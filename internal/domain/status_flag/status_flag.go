@@ -0,0 +1,143 @@
+package status_flag
+
+import "fmt"
+
+// Definition значение бита или кода ошибки. Model и Protocol null - для всех моделей и версий протокола
+type Definition struct {
+	Id       int    `json:"id"`
+	Model    *int   `json:"model"`
+	Protocol *int   `json:"protocol"`
+	Field    string `json:"field"`
+	Value    int    `json:"value"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	LabelRu  string `json:"label_ru" db:"label_ru"`
+	LabelEn  string `json:"label_en" db:"label_en"`
+}
+
+// specificity насколько определение привязано к модели и протоколу, побеждает наибольшее
+func (d Definition) specificity(model, protocol int) int {
+	if d.Model != nil && *d.Model != model || d.Protocol != nil && *d.Protocol != protocol {
+		return -1
+	}
+
+	s := 0
+	if d.Model != nil {
+		s += 2
+	}
+
+	if d.Protocol != nil {
+		s++
+	}
+
+	return s
+}
+
+// Flag установленный бит (Value - номер бита) или код ошибки (Value - код)
+type Flag struct {
+	Value    int    `json:"value"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	LabelRu  string `json:"label_ru"`
+	LabelEn  string `json:"label_en"`
+}
+
+// Decoded расшифровка телеметрии, поле без установленных битов не выводится
+type Decoded struct {
+	Status          []Flag `json:"status,omitempty"`
+	StatusRaw       []Flag `json:"status_raw,omitempty"`
+	ErrorsFlags     []Flag `json:"errors_flags,omitempty"`
+	ModemErrorsCode []Flag `json:"modem_errors_code,omitempty"`
+}
+
+// table справочник для одной модели и протокола: поле -> бит или код -> определение
+type table map[string]map[int]Definition
+
+func newTable(definitions []Definition, model, protocol int) table {
+	t := table{}
+	best := map[string]map[int]int{}
+
+	for _, d := range definitions {
+		s := d.specificity(model, protocol)
+		if s < 0 {
+			continue
+		}
+
+		if t[d.Field] == nil {
+			t[d.Field] = map[int]Definition{}
+			best[d.Field] = map[int]int{}
+		}
+
+		if prev, ok := best[d.Field][d.Value]; ok && prev >= s {
+			continue
+		}
+
+		t[d.Field][d.Value] = d
+		best[d.Field][d.Value] = s
+	}
+
+	return t
+}
+
+func (t table) decode(values Values) Decoded {
+	return Decoded{
+		Status:          t.bits(FIELD_STATUS, values.Status),
+		StatusRaw:       t.bits(FIELD_STATUS_RAW, values.StatusRaw),
+		ErrorsFlags:     t.bits(FIELD_ERRORS_FLAGS, values.ErrorsFlags),
+		ModemErrorsCode: t.code(FIELD_MODEM_ERRORS_CODE, values.ModemErrorsCode),
+	}
+}
+
+// bits установленные биты значения от младшего к старшему
+func (t table) bits(field string, value int64) []Flag {
+	var flags []Flag
+
+	for bit := 0; bit < 32; bit++ {
+		if value&(1<<bit) == 0 {
+			continue
+		}
+
+		if d, ok := t[field][bit]; ok {
+			flags = append(flags, flag(bit, d))
+		} else {
+			flags = append(flags, Flag{
+				Value:    bit,
+				Code:     CODE_UNKNOWN,
+				Severity: SEVERITY_INFO,
+				LabelRu:  fmt.Sprintf("Неизвестный бит %d", bit),
+				LabelEn:  fmt.Sprintf("Unknown bit %d", bit),
+			})
+		}
+	}
+
+	return flags
+}
+
+// code код ошибки, 0 - ошибки нет
+func (t table) code(field string, value int64) []Flag {
+	if value == 0 {
+		return nil
+	}
+
+	if d, ok := t[field][int(value)]; ok {
+		return []Flag{flag(int(value), d)}
+	}
+
+	return []Flag{{
+		Value:    int(value),
+		Code:     CODE_UNKNOWN,
+		Severity: SEVERITY_WARNING,
+		LabelRu:  fmt.Sprintf("Неизвестная ошибка %d", value),
+		LabelEn:  fmt.Sprintf("Unknown error %d", value),
+	}}
+}
+
+func flag(value int, d Definition) Flag {
+	return Flag{
+		Value:    value,
+		Code:     d.Code,
+		Severity: d.Severity,
+		LabelRu:  d.LabelRu,
+		LabelEn:  d.LabelEn,
+	}
+}
@@ -0,0 +1,124 @@
+package status_flag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr(v int) *int {
+	return &v
+}
+
+func TestSpecificity(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      Definition
+		expected int
+	}{
+		{"общее", Definition{}, 0},
+		{"протокол", Definition{Protocol: ptr(2)}, 1},
+		{"модель", Definition{Model: ptr(3)}, 2},
+		{"модель и протокол", Definition{Model: ptr(3), Protocol: ptr(2)}, 3},
+		{"другая модель", Definition{Model: ptr(4)}, -1},
+		{"другой протокол", Definition{Model: ptr(3), Protocol: ptr(1)}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.def.specificity(3, 2))
+		})
+	}
+}
+
+func TestNewTablePrecedence(t *testing.T) {
+	definitions := []Definition{
+		{Model: ptr(3), Protocol: ptr(2), Field: FIELD_STATUS, Value: 0, Code: "exact"},
+		{Field: FIELD_STATUS, Value: 0, Code: "common"},
+		{Model: ptr(3), Field: FIELD_STATUS, Value: 0, Code: "model"},
+		{Protocol: ptr(2), Field: FIELD_STATUS, Value: 0, Code: "protocol"},
+		{Field: FIELD_STATUS, Value: 1, Code: "common"},
+		{Protocol: ptr(2), Field: FIELD_STATUS, Value: 1, Code: "protocol"},
+		{Model: ptr(4), Field: FIELD_STATUS, Value: 2, Code: "other_model"},
+	}
+
+	tests := []struct {
+		name            string
+		model, protocol int
+		value           int
+		expected        string
+	}{
+		{"модель и протокол важнее всех", 3, 2, 0, "exact"},
+		{"модель важнее протокола", 3, 1, 0, "model"},
+		{"протокол важнее общего", 5, 2, 0, "protocol"},
+		{"общее для прочих", 5, 1, 0, "common"},
+		{"порядок строк не важен", 5, 2, 1, "protocol"},
+		{"чужая модель не применяется", 3, 2, 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTable(definitions, tt.model, tt.protocol)[FIELD_STATUS][tt.value]
+			assert.Equal(t, tt.expected, d.Code)
+		})
+	}
+}
+
+func TestBits(t *testing.T) {
+	tbl := newTable([]Definition{
+		{Field: FIELD_ERRORS_FLAGS, Value: 0, Code: "gps", Severity: SEVERITY_CRITICAL, LabelRu: "GPS", LabelEn: "GPS"},
+		{Field: FIELD_ERRORS_FLAGS, Value: 31, Code: "high", Severity: SEVERITY_WARNING},
+	}, 1, 1)
+
+	tests := []struct {
+		name     string
+		value    int64
+		expected []string
+	}{
+		{"нет битов", 0, nil},
+		{"известный бит", 1, []string{"gps"}},
+		{"неизвестный бит", 1 << 4, []string{CODE_UNKNOWN}},
+		{"от младшего к старшему", 1<<31 | 1<<4 | 1, []string{"gps", CODE_UNKNOWN, "high"}},
+		{"отрицательное int32", int64(int32(-1 << 31)), []string{"high"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var codes []string
+			for _, f := range tbl.bits(FIELD_ERRORS_FLAGS, tt.value) {
+				codes = append(codes, f.Code)
+			}
+			assert.Equal(t, tt.expected, codes)
+		})
+	}
+
+	unknown := tbl.bits(FIELD_ERRORS_FLAGS, 1<<4)
+	assert.Equal(t, Flag{Value: 4, Code: CODE_UNKNOWN, Severity: SEVERITY_INFO,
+		LabelRu: "Неизвестный бит 4", LabelEn: "Unknown bit 4"}, unknown[0])
+}
+
+func TestCode(t *testing.T) {
+	tbl := newTable([]Definition{
+		{Field: FIELD_MODEM_ERRORS_CODE, Value: 2, Code: "no_registration", Severity: SEVERITY_WARNING},
+	}, 1, 1)
+
+	assert.Nil(t, tbl.code(FIELD_MODEM_ERRORS_CODE, 0))
+	assert.Equal(t, []Flag{{Value: 2, Code: "no_registration", Severity: SEVERITY_WARNING}},
+		tbl.code(FIELD_MODEM_ERRORS_CODE, 2))
+	// код - это не битовая маска: 3 не раскладывается на 1 и 2
+	assert.Equal(t, []Flag{{Value: 3, Code: CODE_UNKNOWN, Severity: SEVERITY_WARNING,
+		LabelRu: "Неизвестная ошибка 3", LabelEn: "Unknown error 3"}}, tbl.code(FIELD_MODEM_ERRORS_CODE, 3))
+}
+
+func TestDecodeFields(t *testing.T) {
+	tbl := newTable([]Definition{
+		{Field: FIELD_STATUS, Value: 0, Code: "moving"},
+		{Field: FIELD_STATUS_RAW, Value: 0, Code: "moving_raw"},
+	}, 1, 1)
+
+	decoded := tbl.decode(Values{Status: 1, StatusRaw: 1})
+	assert.Equal(t, "moving", decoded.Status[0].Code)
+	assert.Equal(t, "moving_raw", decoded.StatusRaw[0].Code)
+	assert.Nil(t, decoded.ErrorsFlags)
+	assert.Nil(t, decoded.ModemErrorsCode)
+}
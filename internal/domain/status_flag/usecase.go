@@ -0,0 +1,57 @@
+package status_flag
+
+import (
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"sync"
+	"time"
+)
+
+type usecase struct {
+	repo   Repo
+	logger app_interface.Logger
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	tables   map[[2]int]table
+	all      []Definition
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger) Usecase {
+	return &usecase{repo: repo, logger: logger}
+}
+
+// Decode расшифровывает биты статуса и ошибок по справочнику модели и протокола.
+// Сырые значения отдаются клиенту всегда, поэтому ошибка справочника только логируется
+func (s *usecase) Decode(model int, protocol int, values Values) Decoded {
+	return s.table(model, protocol).decode(values)
+}
+
+func (s *usecase) table(model, protocol int) table {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.loadedAt) > CACHE_TTL {
+		// при ошибке остаётся прежний справочник, следующая попытка через CACHE_TTL
+		s.loadedAt = time.Now()
+		if all, err := s.repo.List(); err != nil {
+			s.logger.Error(fmt.Sprintf("Status flags: %v", err))
+		} else {
+			s.all = all
+			s.tables = map[[2]int]table{}
+		}
+	}
+
+	if s.tables == nil {
+		s.tables = map[[2]int]table{}
+	}
+
+	key := [2]int{model, protocol}
+	t, ok := s.tables[key]
+	if !ok {
+		t = newTable(s.all, model, protocol)
+		s.tables[key] = t
+	}
+
+	return t
+}
@@ -0,0 +1,36 @@
+package stop
+
+import (
+	"seal/pkg/geo"
+	"time"
+)
+
+// STOP_SPEED скорость, ниже которой транспорт считается стоящим, км/ч
+const STOP_SPEED = 5
+
+// DEFAULT_RADIUS радиус, в пределах которого точки относятся к одной стоянке, м
+const DEFAULT_RADIUS = 100
+
+// DEFAULT_MIN_DURATION минимальная продолжительность стоянки, мин
+const DEFAULT_MIN_DURATION = 5
+
+// MAX_COORDINATES максимальное количество координат, по которым ищутся стоянки за один запрос
+const MAX_COORDINATES = 200000
+
+type QueryParams struct {
+	Id          int       `validate:"required"`
+	From        time.Time `form:"from"`
+	To          time.Time `form:"to"`
+	MinDuration int       `form:"min_duration" validate:"max=1440,min=0"`
+	Radius      int       `form:"radius" validate:"max=5000,min=0"`
+}
+
+type Repo interface {
+	Coordinates(modemId int, from, to time.Time, limit int) ([]coordinate, error)
+	Customs(points []geo.Point) ([]*Custom, error)
+}
+
+type Usecase interface {
+	ByModem(params QueryParams) ([]Stop, error)
+	ByShipping(params QueryParams) ([]Stop, error)
+}
@@ -0,0 +1,93 @@
+package stop
+
+import (
+	"context"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/custom"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"seal/pkg/geo"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+func (r *repo) Coordinates(modemId int, from, to time.Time, limit int) ([]coordinate, error) {
+	q := query.New[coordinate](r.ctx, r.db).
+		Select("c.dev_time", "").
+		AddSelect("c.latitude", "").
+		AddSelect("c.longitude", "").
+		AddSelect("c.speed", "").
+		AddSelect("count(sa.*) > 0", "in_secret_area").
+		From("coordinates", "c").
+		LeftJoin("sa", "secret_areas", "sa.area @> point(c.latitude, c.longitude)").
+		Where(query.EQUEL, "c.modem", modemId).
+		AndWhere(query.GREAT, "c.dev_time", from).
+		AndFilterWhere(query.LITTLE_OR_EQ, "c.dev_time", to).
+		AndWhere(query.IS_NOT_NULL, "nullif(c.latitude, 'NaN')", nil).
+		AndWhere(query.IS_NOT_NULL, "nullif(c.longitude, 'NaN')", nil).
+		OrderBy("c.dev_time").
+		GroupBy("c.dev_time, c.modem").
+		Limit(limit)
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+// Customs таможни, в зонах которых находятся точки, в порядке points; nil - точка вне таможен
+func (r *repo) Customs(points []geo.Point) ([]*Custom, error) {
+	res := make([]*Custom, len(points))
+	if len(points) == 0 {
+		return res, nil
+	}
+
+	latitudes := make([]float64, len(points))
+	longitudes := make([]float64, len(points))
+
+	for i, p := range points {
+		latitudes[i], longitudes[i] = p.Latitude, p.Longitude
+	}
+
+	q := `select p.index - 1 as index, z.id, z.title
+		from unnest($1::float8[], $2::float8[]) with ordinality p(latitude, longitude, index)
+		cross join lateral (
+			select z.id, z.title from customs z
+			where ` + custom.InZoneSql("z", "p.latitude", "p.longitude") + `
+			order by z.id
+			limit 1
+		) z
+	`
+
+	qp := []any{latitudes, longitudes}
+
+	rows, err := r.db.Query(r.ctx, q, qp...)
+	if err != nil {
+		r.logger.Error(query.NewLogSql(q, qp...).SetError(err).GetMsg())
+		return nil, err
+	}
+
+	data, err := pgx.CollectRows(rows, pgx.RowToStructByName[pointCustom])
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(len(data)).SetError(err).GetMsg())
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range data {
+		res[c.Index] = &Custom{Id: c.Id, Title: c.Title}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,33 @@
+package stop
+
+import "time"
+
+type Stop struct {
+	Latitude     *float64  `json:"latitude"`
+	Longitude    *float64  `json:"longitude"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Duration     int       `json:"duration"`
+	Points       int       `json:"points"`
+	InSecretArea bool      `json:"in_secret_area"`
+	Custom       *Custom   `json:"custom"`
+}
+
+type Custom struct {
+	Id    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type pointCustom struct {
+	Index int    `db:"index"`
+	Id    int    `db:"id"`
+	Title string `db:"title"`
+}
+
+type coordinate struct {
+	DevTime      time.Time `json:"dev_time" db:"dev_time"`
+	Latitude     float32   `json:"latitude"`
+	Longitude    float32   `json:"longitude"`
+	Speed        uint16    `json:"speed"`
+	InSecretArea bool      `json:"in_secret_area" db:"in_secret_area"`
+}
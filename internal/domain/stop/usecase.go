@@ -0,0 +1,185 @@
+package stop
+
+import (
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/modem"
+	"seal/internal/domain/shipping"
+	"seal/pkg/app_error"
+	"seal/pkg/geo"
+	"time"
+)
+
+type CoreUseCase struct {
+	Shipping shipping.Usecase
+	Modem    modem.Usecase
+}
+
+type usecase struct {
+	repo      Repo
+	logger    app_interface.Logger
+	validator app_interface.Validator
+	usecase   CoreUseCase
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, coreUsecase CoreUseCase) Usecase {
+	return &usecase{repo, logger, validator, coreUsecase}
+}
+
+func (s *usecase) ByModem(params QueryParams) ([]Stop, error) {
+	if errs := s.validator.Struct(params); errs != nil {
+		return []Stop{}, app_error.ValidationError(errs)
+	}
+
+	if params.From.IsZero() {
+		return []Stop{}, app_error.ValidationError(map[string]string{"from": "Не указано начало периода"})
+	}
+
+	if _, err := s.usecase.Modem.GetDbById(params.Id); err != nil {
+		return []Stop{}, err
+	}
+
+	return s.stops(params.Id, params)
+}
+
+// ByShipping стоянки модема перевозки с начала поездки до её завершения
+func (s *usecase) ByShipping(params QueryParams) ([]Stop, error) {
+	if errs := s.validator.Struct(params); errs != nil {
+		return []Stop{}, app_error.ValidationError(errs)
+	}
+
+	sh, err := s.usecase.Shipping.GetDbById(params.Id)
+	if err != nil {
+		return []Stop{}, err
+	}
+
+	if sh.Modem == nil || sh.TimeStart == nil {
+		return []Stop{}, app_error.ValidationError(`Поездка не начата`)
+	}
+
+	if params.From.Before(*sh.TimeStart) {
+		params.From = *sh.TimeStart
+	}
+
+	if sh.TimeEnd != nil && (params.To.IsZero() || params.To.After(*sh.TimeEnd)) {
+		params.To = *sh.TimeEnd
+	}
+
+	return s.stops(*sh.Modem, params)
+}
+
+func (s *usecase) stops(modemId int, params QueryParams) ([]Stop, error) {
+	radius := float64(DEFAULT_RADIUS)
+	if params.Radius > 0 {
+		radius = float64(params.Radius)
+	}
+
+	minDuration := DEFAULT_MIN_DURATION * time.Minute
+	if params.MinDuration > 0 {
+		minDuration = time.Duration(params.MinDuration) * time.Minute
+	}
+
+	coords, err := s.repo.Coordinates(modemId, params.From, params.To, MAX_COORDINATES)
+	if err != nil {
+		return []Stop{}, err
+	}
+
+	stops := detect(coords, radius, minDuration)
+
+	points := make([]geo.Point, len(stops))
+	for i, stop := range stops {
+		points[i] = geo.Point{Latitude: *stop.Latitude, Longitude: *stop.Longitude}
+	}
+
+	customs, err := s.repo.Customs(points)
+	if err != nil {
+		return []Stop{}, err
+	}
+
+	for i, stop := range stops {
+		stops[i].Custom = customs[i]
+
+		// координаты стоянок в секретных зонах не раскрываются
+		if stop.InSecretArea {
+			stops[i].Latitude = nil
+			stops[i].Longitude = nil
+		}
+	}
+
+	return stops, nil
+}
+
+// detect объединяет подряд идущие координаты в стоянку, пока они остаются в пределах radius от центра стоянки,
+// а скорость - по данным модема или по перемещению между соседними координатами - не превышает STOP_SPEED.
+// Пропуск в данных не прерывает стоянку, если после него транспорт остался на месте
+func detect(coords []coordinate, radius float64, minDuration time.Duration) []Stop {
+	stops := []Stop{}
+
+	var cluster []coordinate
+	var latitude, longitude float64
+
+	flush := func() {
+		if len(cluster) < 2 {
+			return
+		}
+
+		start, end := cluster[0].DevTime, cluster[len(cluster)-1].DevTime
+		if end.Sub(start) < minDuration {
+			return
+		}
+
+		stop := Stop{
+			Start:    start,
+			End:      end,
+			Duration: int(end.Sub(start).Seconds()),
+			Points:   len(cluster),
+		}
+
+		for _, c := range cluster {
+			stop.InSecretArea = stop.InSecretArea || c.InSecretArea
+		}
+
+		lat, lon := latitude, longitude
+		stop.Latitude, stop.Longitude = &lat, &lon
+
+		stops = append(stops, stop)
+	}
+
+	for i, c := range coords {
+		lat, lon := float64(c.Latitude), float64(c.Longitude)
+
+		if len(cluster) > 0 && geo.Distance(latitude, longitude, lat, lon) <= radius && stationary(coords[i-1], c) {
+			n := float64(len(cluster))
+			latitude = (latitude*n + lat) / (n + 1)
+			longitude = (longitude*n + lon) / (n + 1)
+			cluster = append(cluster, c)
+			continue
+		}
+
+		flush()
+		cluster = nil
+
+		if c.Speed <= STOP_SPEED {
+			cluster = []coordinate{c}
+			latitude, longitude = lat, lon
+		}
+	}
+
+	flush()
+
+	return stops
+}
+
+func stationary(prev, c coordinate) bool {
+	if c.Speed <= STOP_SPEED {
+		return true
+	}
+
+	seconds := c.DevTime.Sub(prev.DevTime).Seconds()
+	if seconds <= 0 {
+		return false
+	}
+
+	distance := geo.Distance(float64(prev.Latitude), float64(prev.Longitude), float64(c.Latitude), float64(c.Longitude))
+
+	return distance/seconds*3.6 <= STOP_SPEED
+}
@@ -0,0 +1,132 @@
+package stop
+
+import (
+	"seal/pkg/geo"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	Repo
+	coords  []coordinate
+	customs map[geo.Point]*Custom
+	calls   int
+}
+
+func (r *fakeRepo) Coordinates(modemId int, from, to time.Time, limit int) ([]coordinate, error) {
+	return r.coords, nil
+}
+
+func (r *fakeRepo) Customs(points []geo.Point) ([]*Custom, error) {
+	r.calls++
+
+	res := make([]*Custom, len(points))
+	for i, p := range points {
+		res[i] = r.customs[p]
+	}
+
+	return res, nil
+}
+
+var t0 = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// coord точка через minutes минут после t0, смещённая на north метров к северу от базовой
+func coord(minutes int, north float64, speed uint16) coordinate {
+	return coordinate{
+		DevTime:   t0.Add(time.Duration(minutes) * time.Minute),
+		Latitude:  float32(55.75 + north/geo.EARTH_RADIUS*180/3.141592653589793),
+		Longitude: 37.62,
+		Speed:     speed,
+	}
+}
+
+func TestDetect(t *testing.T) {
+	secret := coord(3, 0, 0)
+	secret.InSecretArea = true
+
+	tests := []struct {
+		name   string
+		coords []coordinate
+		stops  [][2]int // начало и конец стоянок, мин от t0
+		points []int
+	}{
+		{"нет координат", nil, nil, nil},
+		{"стоянка", []coordinate{coord(0, 0, 0), coord(3, 10, 2), coord(6, 5, 0), coord(9, 0, 0)},
+			[][2]int{{0, 9}}, []int{4}},
+		{"короче минимальной продолжительности", []coordinate{coord(0, 0, 0), coord(2, 0, 0), coord(4, 0, 0)},
+			nil, nil},
+		{"движение до и после стоянки", []coordinate{
+			coord(0, -2000, 60), coord(1, -1000, 60), coord(2, 0, 3), coord(5, 0, 0), coord(8, 0, 0),
+			coord(9, 1000, 60), coord(10, 2000, 60),
+		}, [][2]int{{2, 8}}, []int{3}},
+		{"медленный дрейф за пределы радиуса", []coordinate{
+			coord(0, 0, 4), coord(3, 80, 4), coord(6, 160, 4), coord(9, 240, 4), coord(12, 320, 4),
+		}, nil, nil},
+		{"пропуск в данных на месте не прерывает стоянку", []coordinate{coord(0, 0, 0), coord(1, 0, 0), coord(40, 0, 0)},
+			[][2]int{{0, 40}}, []int{3}},
+		{"скорость модема выше порога без перемещения", []coordinate{coord(0, 0, 0), coord(5, 0, 20), coord(10, 0, 0)},
+			[][2]int{{0, 10}}, []int{3}},
+		{"две стоянки", []coordinate{
+			coord(0, 0, 0), coord(6, 0, 0), coord(7, 1000, 60), coord(8, 2000, 0), coord(20, 2000, 0),
+		}, [][2]int{{0, 6}, {8, 20}}, []int{2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stops := detect(tt.coords, DEFAULT_RADIUS, DEFAULT_MIN_DURATION*time.Minute)
+
+			var spans [][2]int
+			var points []int
+
+			for _, stop := range stops {
+				start, end := int(stop.Start.Sub(t0).Minutes()), int(stop.End.Sub(t0).Minutes())
+				spans = append(spans, [2]int{start, end})
+				points = append(points, stop.Points)
+
+				assert.Equal(t, (end-start)*60, stop.Duration)
+				require.NotNil(t, stop.Latitude)
+				require.NotNil(t, stop.Longitude)
+			}
+
+			assert.Equal(t, tt.stops, spans)
+			assert.Equal(t, tt.points, points)
+		})
+	}
+
+	t.Run("центр стоянки и секретная зона", func(t *testing.T) {
+		stops := detect([]coordinate{coord(0, 0, 0), secret, coord(6, 30, 0)}, DEFAULT_RADIUS, DEFAULT_MIN_DURATION*time.Minute)
+
+		require.Len(t, stops, 1)
+		assert.True(t, stops[0].InSecretArea)
+		assert.InDelta(t, 10, geo.Distance(55.75, 37.62, *stops[0].Latitude, *stops[0].Longitude), 1)
+	})
+}
+
+func TestStops(t *testing.T) {
+	hidden := coord(20, 2000, 0)
+	hidden.InSecretArea = true
+
+	repo := &fakeRepo{coords: []coordinate{
+		coord(0, 0, 0), coord(6, 0, 0), coord(7, 1000, 60), coord(8, 2000, 0), hidden,
+	}}
+	repo.customs = map[geo.Point]*Custom{
+		{Latitude: float64(repo.coords[0].Latitude), Longitude: float64(repo.coords[0].Longitude)}: {Id: 1, Title: "Таможня"},
+	}
+
+	s := &usecase{repo: repo}
+
+	stops, err := s.stops(1, QueryParams{})
+	require.NoError(t, err)
+	require.Len(t, stops, 2)
+
+	assert.Equal(t, 1, repo.calls)
+	assert.Equal(t, &Custom{Id: 1, Title: "Таможня"}, stops[0].Custom)
+	assert.NotNil(t, stops[0].Latitude)
+
+	assert.Nil(t, stops[1].Custom)
+	assert.Nil(t, stops[1].Latitude)
+	assert.Nil(t, stops[1].Longitude)
+}
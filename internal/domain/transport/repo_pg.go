@@ -8,6 +8,7 @@ import (
 	"seal/internal/repository/pg/query"
 	"seal/internal/transport"
 	"seal/pkg/app_error"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -101,6 +102,22 @@ func (r *repo) GetDbById(id int) (Db, error) {
 	return data, err
 }
 
+// FindDb транспорт с регистрационным номером или наименованием value (без учёта регистра)
+func (r *repo) FindDb(value string, limit int) ([]Db, error) {
+	q := query.New[Db](r.ctx, r.db).
+		Select("*", "").
+		From("transports", "s").
+		Where(query.EQUEL, "lower(s.registration_number)", strings.ToLower(value)).
+		OrWhere(query.EQUEL, "lower(s.title)", strings.ToLower(value)).
+		OrderBy("s.id").
+		Limit(limit)
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
 func (r *repo) List(params transport.QueryParams) (query.List[Transport], error) {
 	q := query.New[Transport](r.ctx, r.db).
 		Select("t.id", "").
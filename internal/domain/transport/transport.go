@@ -20,6 +20,7 @@ type Repo interface {
 	Update(data Db) (Transport, error)
 	GetById(id int) (Transport, error)
 	GetDbById(id int) (Db, error)
+	FindDb(value string, limit int) ([]Db, error)
 	List(params transport.QueryParams) (query.List[Transport], error)
 	Exists(id int) (bool, error)
 	ExistsByUnique(id int, title string) (bool, error)
@@ -31,6 +32,7 @@ type Usecase interface {
 	Update(id int, data UpdateRequest) (Transport, error)
 	GetById(id int) (Transport, error)
 	GetDbById(id int) (Db, error)
+	GetDbByRegistrationNumberOrTitle(value string) (Db, error)
 	List(params transport.QueryParams) (query.List[Transport], error)
 	Exists(id int) (bool, error)
 	ExistsByUnique(id int, title string) (bool, error)
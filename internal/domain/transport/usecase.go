@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"fmt"
 	app_interface "seal/internal/app/interface"
 	"seal/internal/repository/pg/query"
 	"seal/internal/transport"
@@ -64,6 +65,21 @@ func (s *usecase) GetDbById(id int) (Db, error) {
 	return s.repo.GetDbById(id)
 }
 
+func (s *usecase) GetDbByRegistrationNumberOrTitle(value string) (Db, error) {
+	list, err := s.repo.FindDb(value, 2)
+	if err != nil {
+		return Db{}, err
+	}
+
+	if len(list) == 0 {
+		return Db{}, app_error.ErrNotFound
+	} else if len(list) > 1 {
+		return Db{}, app_error.ValidationError(fmt.Sprintf(`Найдено несколько транспортных средств "%s"`, value))
+	}
+
+	return list[0], nil
+}
+
 func (s *usecase) List(queryParams transport.QueryParams) (query.List[Transport], error) {
 	if errs := s.validator.Struct(queryParams); errs != nil {
 		return query.List[Transport]{}, app_error.ValidationError(errs)
@@ -0,0 +1,55 @@
+package upload_token
+
+import (
+	"seal/internal/domain/shipping"
+	"time"
+)
+
+type Db struct {
+	Id         int        `json:"id"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	Shipping   int        `json:"shipping"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	Types      []int      `json:"types"`
+	MaxUses    int        `json:"max_uses" db:"max_uses"`
+	Uses       int        `json:"uses"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+	RevokedBy  *int       `json:"revoked_by" db:"revoked_by"`
+	Author     *int       `json:"author"`
+}
+
+// TOKEN_BYTES длина ключа до кодирования в base64
+const TOKEN_BYTES = 32
+
+// HEADER заголовок запроса с ключом. В адресе ключ не принимается, чтобы он не попадал в журналы прокси
+const HEADER = "X-Upload-Token"
+
+type CreateRequest struct {
+	Types   []int `json:"types" validate:"required,min=1,dive,min=0"`
+	Ttl     int   `json:"ttl" validate:"required,min=1,max=10080"` // минут, не больше недели
+	MaxUses int   `json:"max_uses" validate:"min=0,max=100"`       // 0 - один раз
+}
+
+type Repo interface {
+	Create(data Db) (UploadToken, error)
+	GetById(id int) (UploadToken, error)
+	GetDbById(id int) (Db, error)
+	ListByShipping(shippingId int) ([]UploadToken, error)
+	Revoke(id int, userId int) (UploadToken, error)
+	Acquire(tokenHash string, shippingId int, fileType int) (int, error)
+	Release(id int) error
+}
+
+type Usecase interface {
+	Create(shippingId int, data CreateRequest, userId int) (Created, error)
+	ListByShipping(shippingId int) ([]UploadToken, error)
+	Revoke(shippingId int, id int, userId int) (UploadToken, error)
+	Acquire(token string, shippingId int, fileType int) (int, error)
+	Release(id int) error
+}
+
+type CoreUseCase struct {
+	Shipping shipping.Usecase
+}
@@ -0,0 +1,170 @@
+package upload_token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping"
+	"seal/internal/repository/pg"
+	"seal/internal/repository/pg/query"
+	"seal/pkg/app_error"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type repo struct {
+	db     pg.DbClient
+	logger app_interface.Logger
+	ctx    context.Context
+}
+
+func NewRepo(ctx context.Context, db pg.DbClient, logger app_interface.Logger) Repo {
+	return &repo{db, logger, ctx}
+}
+
+func (r *repo) Create(t Db) (UploadToken, error) {
+	q := `INSERT INTO upload_tokens
+		(shipping, token_hash, types, max_uses, expires_at, author)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	qp := []any{t.Shipping, t.TokenHash, t.Types, t.MaxUses, t.ExpiresAt, t.Author}
+
+	err := r.db.QueryRow(r.ctx, q, qp...).Scan(&t.Id)
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(t.Id).SetError(err).GetMsg())
+
+	if err != nil {
+		return UploadToken{}, err
+	}
+
+	return r.GetById(t.Id)
+}
+
+func (r *repo) GetById(id int) (UploadToken, error) {
+	q := query.New[UploadToken](r.ctx, r.db).
+		Select("t.id", "").
+		AddSelect("t.created_at", "").
+		AddSelect("t.shipping", "").
+		AddSelect("t.types", "").
+		AddSelect("t.max_uses", "").
+		AddSelect("t.uses", "").
+		AddSelect("t.expires_at", "").
+		AddSelect("t.last_used_at", "").
+		AddSelect("t.revoked_at", "").
+		AddSelect("case when ur.id is null then null else jsonb_build_object('id', ur.id, 'login', ur.login) end", "revoked_by").
+		AddSelect("case when ua.id is null then null else jsonb_build_object('id', ua.id, 'login', ua.login) end", "author").
+		AddSelect("t.revoked_at is null and t.expires_at > now() and t.uses < t.max_uses", "active").
+		From("upload_tokens", "t").
+		LeftJoin("ua", "users", "ua.id = t.author").
+		LeftJoin("ur", "users", "ur.id = t.revoked_by").
+		Where(query.EQUEL, "t.id", id)
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) GetDbById(id int) (Db, error) {
+	q := query.New[Db](r.ctx, r.db).
+		Select("*", "").
+		From("upload_tokens", "t").
+		Where(query.EQUEL, "t.id", id)
+
+	data, err := q.One()
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return data, app_error.ErrNotFound
+	}
+
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(data).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) ListByShipping(shippingId int) ([]UploadToken, error) {
+	q := query.New[UploadToken](r.ctx, r.db).
+		Select("t.id", "").
+		AddSelect("t.created_at", "").
+		AddSelect("t.shipping", "").
+		AddSelect("t.types", "").
+		AddSelect("t.max_uses", "").
+		AddSelect("t.uses", "").
+		AddSelect("t.expires_at", "").
+		AddSelect("t.last_used_at", "").
+		AddSelect("t.revoked_at", "").
+		AddSelect("case when ur.id is null then null else jsonb_build_object('id', ur.id, 'login', ur.login) end", "revoked_by").
+		AddSelect("case when ua.id is null then null else jsonb_build_object('id', ua.id, 'login', ua.login) end", "author").
+		AddSelect("t.revoked_at is null and t.expires_at > now() and t.uses < t.max_uses", "active").
+		From("upload_tokens", "t").
+		LeftJoin("ua", "users", "ua.id = t.author").
+		LeftJoin("ur", "users", "ur.id = t.revoked_by").
+		Where(query.EQUEL, "t.shipping", shippingId).
+		OrderBy("t.created_at DESC")
+
+	data, err := q.All()
+	r.logger.DebugOrError(err, q.GetLogSql().SetResult(len(data)).SetError(err).GetMsg())
+
+	return data, err
+}
+
+func (r *repo) Revoke(id int, userId int) (UploadToken, error) {
+	q := `UPDATE upload_tokens
+		set (revoked_at, revoked_by) = (now(), $2)
+		where id = $1 and revoked_at is null
+	`
+
+	qp := []any{id, userId}
+
+	commandTag, err := r.db.Exec(r.ctx, q, qp...)
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(commandTag.RowsAffected()).SetError(err).GetMsg())
+
+	if err != nil {
+		return UploadToken{}, err
+	}
+
+	return r.GetById(id)
+}
+
+// Acquire списывает одно использование действующего ключа перевозки для типа файла.
+// Загрузка возможна только в новую или активную перевозку
+func (r *repo) Acquire(tokenHash string, shippingId int, fileType int) (int, error) {
+	q := fmt.Sprintf(`UPDATE upload_tokens
+		set (uses, last_used_at) = (uses + 1, now())
+		where token_hash = $1 and shipping = $2 and $3 = any(types)
+			and revoked_at is null and expires_at > now() and uses < max_uses
+			and exists (select 1 from shipping s where s.id = $2 and s.status in (%d, %d))
+		RETURNING id
+	`, shipping.STATUS_NEW, shipping.STATUS_ACTIVE)
+
+	qp := []any{tokenHash, shippingId, fileType}
+
+	var id int
+	err := r.db.QueryRow(r.ctx, q, qp...).Scan(&id)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, app_error.ErrUnauthorized
+	}
+
+	r.logger.DebugOrError(err, query.NewLogSql(q, qp...).SetResult(id).SetError(err).GetMsg())
+
+	return id, err
+}
+
+// Release возвращает использование ключа, если загрузка не удалась
+func (r *repo) Release(id int) error {
+	q := `UPDATE upload_tokens set uses = greatest(uses - 1, 0) where id = $1`
+
+	commandTag, err := r.db.Exec(r.ctx, q, id)
+	r.logger.DebugOrError(err, query.NewLogSql(q, id).SetResult(commandTag.RowsAffected()).SetError(err).GetMsg())
+
+	return err
+}
@@ -0,0 +1,27 @@
+package upload_token
+
+import (
+	"seal/internal/domain/user"
+	"time"
+)
+
+type UploadToken struct {
+	Id         int          `json:"id"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	Shipping   int          `json:"shipping"`
+	Types      []int        `json:"types"`
+	MaxUses    int          `json:"max_uses" db:"max_uses"`
+	Uses       int          `json:"uses"`
+	ExpiresAt  time.Time    `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time   `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time   `json:"revoked_at" db:"revoked_at"`
+	RevokedBy  *user.Author `json:"revoked_by" db:"revoked_by"`
+	Author     *user.Author `json:"author"`
+	Active     bool         `json:"active"`
+}
+
+// Created ключ сразу после создания, Token возвращается только здесь
+type Created struct {
+	UploadToken
+	Token string `json:"token"`
+}
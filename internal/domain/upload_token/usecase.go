@@ -0,0 +1,118 @@
+package upload_token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	app_interface "seal/internal/app/interface"
+	"seal/internal/domain/shipping"
+	"seal/pkg/app_error"
+	"time"
+)
+
+type usecase struct {
+	repo      Repo
+	logger    app_interface.Logger
+	validator app_interface.Validator
+	usecase   CoreUseCase
+}
+
+func NewUsecase(repo Repo, logger app_interface.Logger, validator app_interface.Validator, coreUsecase CoreUseCase) Usecase {
+	return &usecase{repo, logger, validator, coreUsecase}
+}
+
+// Create выдаёт ключ загрузки файлов перевозки. Ключ показывается один раз, в базе хранится только его хеш
+func (s *usecase) Create(shippingId int, data CreateRequest, userId int) (Created, error) {
+	if errs := s.validator.Struct(data); errs != nil {
+		return Created{}, app_error.ValidationError(errs)
+	}
+
+	ship, err := s.usecase.Shipping.GetDbById(shippingId)
+	if err != nil {
+		return Created{}, err
+	}
+
+	if ship.Status == shipping.STATUS_END || ship.Status == shipping.STATUS_CANCELLED {
+		return Created{}, app_error.ValidationError(map[string]string{
+			"shipping": "Перевозка завершена или отменена",
+		})
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return Created{}, err
+	}
+
+	maxUses := data.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+
+	created, err := s.repo.Create(Db{
+		Shipping:  shippingId,
+		TokenHash: hash(token),
+		Types:     data.Types,
+		MaxUses:   maxUses,
+		ExpiresAt: time.Now().Add(time.Duration(data.Ttl) * time.Minute),
+		Author:    &userId,
+	})
+	if err != nil {
+		return Created{}, err
+	}
+
+	return Created{created, token}, nil
+}
+
+func (s *usecase) ListByShipping(shippingId int) ([]UploadToken, error) {
+	if _, err := s.usecase.Shipping.GetDbById(shippingId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByShipping(shippingId)
+}
+
+func (s *usecase) Revoke(shippingId int, id int, userId int) (UploadToken, error) {
+	token, err := s.repo.GetDbById(id)
+	if err != nil {
+		return UploadToken{}, err
+	}
+
+	if token.Shipping != shippingId {
+		return UploadToken{}, app_error.ErrNotFound
+	}
+
+	if token.RevokedAt != nil {
+		return UploadToken{}, app_error.ValidationError(`Ключ уже отозван`)
+	}
+
+	return s.repo.Revoke(id, userId)
+}
+
+// Acquire проверяет ключ для загрузки в перевозку файла типа fileType и списывает одно использование.
+// Возвращает id ключа для записи в метаданные файла
+func (s *usecase) Acquire(token string, shippingId int, fileType int) (int, error) {
+	if token == "" {
+		return 0, app_error.ErrUnauthorized
+	}
+
+	return s.repo.Acquire(hash(token), shippingId, fileType)
+}
+
+func (s *usecase) Release(id int) error {
+	return s.repo.Release(id)
+}
+
+func newToken() (string, error) {
+	b := make([]byte, TOKEN_BYTES)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,3 @@
+DROP TABLE public.shipping_deviation_checks;
+DROP TABLE public.shipping_deviations;
+ALTER TABLE public.routes DROP COLUMN corridor;
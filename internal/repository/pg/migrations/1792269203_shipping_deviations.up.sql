@@ -0,0 +1,25 @@
+ALTER TABLE public.routes ADD corridor int NOT NULL DEFAULT 500;
+COMMENT ON COLUMN public.routes.corridor IS 'Допустимое отклонение от маршрута (в метрах)';
+
+CREATE TABLE public.shipping_deviations (
+	id serial NOT NULL,
+	shipping int4 NOT NULL,
+	time_start timestamptz NOT NULL,
+	time_end timestamptz NULL,
+	max_distance int4 NOT NULL DEFAULT 0,
+	CONSTRAINT shipping_deviations_pk PRIMARY KEY (id),
+	CONSTRAINT shipping_deviations_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE
+);
+CREATE INDEX shipping_deviations_shipping_idx ON public.shipping_deviations USING btree (shipping, time_start);
+COMMENT ON TABLE public.shipping_deviations IS 'Отклонения от маршрута';
+COMMENT ON COLUMN public.shipping_deviations.time_start IS 'Время выхода из коридора маршрута';
+COMMENT ON COLUMN public.shipping_deviations.time_end IS 'Время возврата в коридор маршрута';
+COMMENT ON COLUMN public.shipping_deviations.max_distance IS 'Максимальное удаление от маршрута (в метрах)';
+
+CREATE TABLE public.shipping_deviation_checks (
+	shipping int4 NOT NULL,
+	checked_at timestamptz NOT NULL,
+	CONSTRAINT shipping_deviation_checks_pk PRIMARY KEY (shipping),
+	CONSTRAINT shipping_deviation_checks_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE
+);
+COMMENT ON TABLE public.shipping_deviation_checks IS 'Время последней проверенной координаты перевозки';
@@ -0,0 +1,2 @@
+DROP TABLE public.shipping_incident_checks;
+DROP TABLE public.seal_incidents;
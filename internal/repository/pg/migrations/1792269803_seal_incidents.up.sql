@@ -0,0 +1,38 @@
+CREATE TABLE public.seal_incidents (
+	id serial NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	shipping int4 NOT NULL,
+	seal int4 NOT NULL,
+	seal_serial int8 NOT NULL,
+	modem int4 NOT NULL,
+	dev_time timestamptz NOT NULL,
+	"type" int2 NOT NULL,
+	flag int8 NOT NULL DEFAULT 0,
+	status int8 NOT NULL DEFAULT 0,
+	errors int2 NOT NULL DEFAULT 0,
+	latitude float4 NULL,
+	longitude float4 NULL,
+	state int2 NOT NULL DEFAULT 0,
+	acknowledged_by int4 NULL,
+	acknowledged_at timestamptz NULL,
+	resolved_by int4 NULL,
+	resolved_at timestamptz NULL,
+	"comment" text NOT NULL DEFAULT '',
+	CONSTRAINT seal_incidents_pk PRIMARY KEY (id),
+	CONSTRAINT seal_incidents_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE,
+	CONSTRAINT seal_incidents_seal_fk FOREIGN KEY (seal) REFERENCES public.seals(id) ON DELETE CASCADE,
+	CONSTRAINT seal_incidents_unique UNIQUE (shipping, seal, dev_time, "type", flag)
+);
+CREATE INDEX seal_incidents_state_idx ON public.seal_incidents USING btree (state, dev_time);
+COMMENT ON TABLE public.seal_incidents IS 'Инциденты пломб (вскрытие, обрыв троса, ошибки датчиков)';
+COMMENT ON COLUMN public.seal_incidents."type" IS 'Тип инцидента (0 – Обрыв троса, 1 – Вскрытие корпуса, 2 – Ошибка датчика)';
+COMMENT ON COLUMN public.seal_incidents.flag IS 'Установившийся бит статуса или ошибки пломбы';
+COMMENT ON COLUMN public.seal_incidents.state IS 'Состояние (0 – Новый, 1 – Принят, 2 – Закрыт)';
+
+CREATE TABLE public.shipping_incident_checks (
+	shipping int4 NOT NULL,
+	checked_at timestamptz NOT NULL,
+	CONSTRAINT shipping_incident_checks_pk PRIMARY KEY (shipping),
+	CONSTRAINT shipping_incident_checks_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE
+);
+COMMENT ON TABLE public.shipping_incident_checks IS 'Время последнего проверенного пакета пломб перевозки';
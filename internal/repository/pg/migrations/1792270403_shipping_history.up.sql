@@ -0,0 +1,17 @@
+CREATE TABLE public.shipping_history (
+	id serial NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	shipping int4 NOT NULL,
+	author int4 NOT NULL,
+	status_from int2 NOT NULL,
+	status_to int2 NOT NULL,
+	reason text NOT NULL DEFAULT '',
+	CONSTRAINT shipping_history_pk PRIMARY KEY (id),
+	CONSTRAINT shipping_history_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE,
+	CONSTRAINT shipping_history_author_fk FOREIGN KEY (author) REFERENCES public.users(id)
+);
+CREATE INDEX shipping_history_shipping_idx ON public.shipping_history USING btree (shipping, created_at);
+COMMENT ON TABLE public.shipping_history IS 'История смены статусов перевозки';
+COMMENT ON COLUMN public.shipping_history.status_from IS 'Статус до перехода (0 – Новая, 1 – Активная, 2 – Завершена, 3 – Отменена, 4 – Приостановлена)';
+COMMENT ON COLUMN public.shipping_history.status_to IS 'Статус после перехода';
+COMMENT ON COLUMN public.shipping_history.reason IS 'Причина перехода';
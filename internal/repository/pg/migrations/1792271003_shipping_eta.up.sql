@@ -0,0 +1,16 @@
+CREATE TABLE public.shipping_eta (
+	shipping int4 NOT NULL,
+	calculated_at timestamptz NOT NULL DEFAULT now(),
+	dev_time timestamptz NOT NULL,
+	remaining_distance int4 NOT NULL DEFAULT 0,
+	average_speed int2 NOT NULL DEFAULT 0,
+	arrival_time timestamptz NOT NULL,
+	CONSTRAINT shipping_eta_pk PRIMARY KEY (shipping),
+	CONSTRAINT shipping_eta_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE
+);
+COMMENT ON TABLE public.shipping_eta IS 'Прогноз времени прибытия перевозки по фактическому движению';
+COMMENT ON COLUMN public.shipping_eta.dev_time IS 'Время координаты, по которой выполнен расчёт';
+COMMENT ON COLUMN public.shipping_eta.remaining_distance IS 'Оставшееся расстояние по маршруту (в метрах)';
+COMMENT ON COLUMN public.shipping_eta.average_speed IS 'Средняя скорость, использованная в расчёте (км/ч)';
+COMMENT ON COLUMN public.shipping_eta.arrival_time IS 'Прогнозируемое время прибытия';
+COMMENT ON COLUMN public.routes.length IS 'Длина маршрута (в километрах)';
@@ -0,0 +1,7 @@
+DROP TABLE public.shipping_custom_checks;
+DROP TABLE public.shipping_custom_events;
+
+ALTER TABLE public.customs DROP COLUMN area;
+ALTER TABLE public.customs DROP COLUMN radius;
+ALTER TABLE public.customs DROP COLUMN longitude;
+ALTER TABLE public.customs DROP COLUMN latitude;
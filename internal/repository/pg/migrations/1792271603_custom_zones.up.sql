@@ -0,0 +1,39 @@
+ALTER TABLE public.customs ADD latitude real NULL;
+ALTER TABLE public.customs ADD longitude real NULL;
+ALTER TABLE public.customs ADD radius int4 NOT NULL DEFAULT 0;
+ALTER TABLE public.customs ADD area polygon NULL;
+COMMENT ON COLUMN public.customs.latitude IS 'Широта центра зоны таможни';
+COMMENT ON COLUMN public.customs.longitude IS 'Долгота центра зоны таможни';
+COMMENT ON COLUMN public.customs.radius IS 'Радиус зоны таможни (в метрах), используется если не задан полигон';
+COMMENT ON COLUMN public.customs.area IS 'Полигон зоны таможни (широта, долгота)';
+
+CREATE TABLE public.shipping_custom_events (
+	id serial NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	shipping int4 NOT NULL,
+	custom int4 NOT NULL,
+	zone int2 NOT NULL,
+	"type" int2 NOT NULL,
+	dev_time timestamptz NOT NULL,
+	latitude real NOT NULL,
+	longitude real NOT NULL,
+	CONSTRAINT shipping_custom_events_pk PRIMARY KEY (id),
+	CONSTRAINT shipping_custom_events_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE,
+	CONSTRAINT shipping_custom_events_custom_fk FOREIGN KEY (custom) REFERENCES public.customs(id) ON DELETE CASCADE
+);
+CREATE INDEX shipping_custom_events_shipping_idx ON public.shipping_custom_events USING btree (shipping, dev_time);
+COMMENT ON TABLE public.shipping_custom_events IS 'Въезд и выезд перевозки из зон таможен';
+COMMENT ON COLUMN public.shipping_custom_events.zone IS 'Зона (0 – таможня отправления, 1 – таможня назначения)';
+COMMENT ON COLUMN public.shipping_custom_events."type" IS 'Событие (0 – въезд, 1 – выезд)';
+
+CREATE TABLE public.shipping_custom_checks (
+	shipping int4 NOT NULL,
+	checked_at timestamptz NOT NULL,
+	in_departure bool NOT NULL DEFAULT false,
+	in_destination bool NOT NULL DEFAULT false,
+	destination_entered_at timestamptz NULL,
+	CONSTRAINT shipping_custom_checks_pk PRIMARY KEY (shipping),
+	CONSTRAINT shipping_custom_checks_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE
+);
+COMMENT ON TABLE public.shipping_custom_checks IS 'Состояние проверки нахождения перевозки в зонах таможен';
+COMMENT ON COLUMN public.shipping_custom_checks.destination_entered_at IS 'Время въезда в зону таможни назначения';
@@ -0,0 +1,19 @@
+CREATE TABLE public.shipping_seals (
+	shipping int4 NOT NULL,
+	seal int4 NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	author int4 NULL,
+	CONSTRAINT shipping_seals_pk PRIMARY KEY (shipping, seal),
+	CONSTRAINT shipping_seals_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE,
+	CONSTRAINT shipping_seals_seal_fk FOREIGN KEY (seal) REFERENCES public.seals(id) ON DELETE CASCADE,
+	CONSTRAINT shipping_seals_author_fk FOREIGN KEY (author) REFERENCES public.users(id) ON DELETE SET NULL
+);
+CREATE INDEX shipping_seals_seal_idx ON public.shipping_seals USING btree (seal);
+COMMENT ON TABLE public.shipping_seals IS 'Пломбы, привязанные к перевозке';
+COMMENT ON COLUMN public.shipping_seals.author IS 'Пользователь, привязавший пломбу (NULL – перенесено из данных модема)';
+
+-- Перенос пломб уже созданных перевозок: пломбы, которые слышал модем перевозки
+INSERT INTO public.shipping_seals (shipping, seal)
+SELECT DISTINCT s.id, sd.seal
+FROM public.shipping s
+INNER JOIN public.seals_data sd ON sd.modem = s.modem AND sd.dev_time >= s.created_at AND sd.dev_time < coalesce(s.time_end, now());
@@ -0,0 +1,19 @@
+CREATE TABLE public.shipping_odometer (
+	shipping int4 NOT NULL,
+	calculated_at timestamptz NOT NULL DEFAULT now(),
+	checked_at timestamptz NOT NULL,
+	last_dev_time timestamptz NULL,
+	last_latitude float4 NULL,
+	last_longitude float4 NULL,
+	rejected int2 NOT NULL DEFAULT 0,
+	distance int4 NOT NULL DEFAULT 0,
+	moving_time int4 NOT NULL DEFAULT 0,
+	CONSTRAINT shipping_odometer_pk PRIMARY KEY (shipping),
+	CONSTRAINT shipping_odometer_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE
+);
+COMMENT ON TABLE public.shipping_odometer IS 'Пройденное перевозкой расстояние по координатам';
+COMMENT ON COLUMN public.shipping_odometer.checked_at IS 'Время последней обработанной координаты';
+COMMENT ON COLUMN public.shipping_odometer.last_dev_time IS 'Время последней принятой координаты';
+COMMENT ON COLUMN public.shipping_odometer.rejected IS 'Количество координат, отброшенных подряд как скачки';
+COMMENT ON COLUMN public.shipping_odometer.distance IS 'Пройденное расстояние (в метрах)';
+COMMENT ON COLUMN public.shipping_odometer.moving_time IS 'Время в движении (в секундах)';
@@ -0,0 +1,25 @@
+CREATE TABLE public.upload_tokens (
+	id serial4 NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	shipping int4 NOT NULL,
+	token_hash varchar(64) NOT NULL,
+	types int4[] NOT NULL,
+	max_uses int4 NOT NULL DEFAULT 1,
+	uses int4 NOT NULL DEFAULT 0,
+	expires_at timestamptz NOT NULL,
+	last_used_at timestamptz NULL,
+	revoked_at timestamptz NULL,
+	revoked_by int4 NULL,
+	author int4 NULL,
+	CONSTRAINT upload_tokens_pk PRIMARY KEY (id),
+	CONSTRAINT upload_tokens_token_hash_un UNIQUE (token_hash),
+	CONSTRAINT upload_tokens_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE,
+	CONSTRAINT upload_tokens_author_fk FOREIGN KEY (author) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT upload_tokens_revoked_by_fk FOREIGN KEY (revoked_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+CREATE INDEX upload_tokens_shipping_idx ON public.upload_tokens USING btree (shipping);
+COMMENT ON TABLE public.upload_tokens IS 'Одноразовые ключи загрузки файлов перевозки без учётной записи (водители)';
+COMMENT ON COLUMN public.upload_tokens.token_hash IS 'sha256 ключа, сам ключ не хранится';
+COMMENT ON COLUMN public.upload_tokens.types IS 'Типы файлов, которые можно загрузить';
+COMMENT ON COLUMN public.upload_tokens.max_uses IS 'Сколько раз можно загрузить файлы по ключу';
+COMMENT ON COLUMN public.upload_tokens.uses IS 'Сколько раз ключ использован';
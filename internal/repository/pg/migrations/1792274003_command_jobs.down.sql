@@ -0,0 +1,2 @@
+DROP TABLE public.command_job_items;
+DROP TABLE public.command_jobs;
@@ -0,0 +1,29 @@
+CREATE TABLE public.command_jobs (
+	id serial4 NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	finished_at timestamptz NULL,
+	"name" varchar(50) NOT NULL,
+	params jsonb NOT NULL,
+	filter jsonb NULL,
+	author int4 NULL,
+	total int4 NOT NULL,
+	CONSTRAINT command_jobs_pk PRIMARY KEY (id),
+	CONSTRAINT command_jobs_author_fk FOREIGN KEY (author) REFERENCES public.users(id) ON DELETE SET NULL
+);
+COMMENT ON TABLE public.command_jobs IS 'Массовая отправка команды модемам';
+COMMENT ON COLUMN public.command_jobs.filter IS 'Фильтр, по которому выбраны модемы, null - модемы указаны списком';
+COMMENT ON COLUMN public.command_jobs.finished_at IS 'Время отправки команды последнему модему';
+
+CREATE TABLE public.command_job_items (
+	job int4 NOT NULL,
+	modem int4 NOT NULL,
+	imei varchar(20) NOT NULL,
+	status int2 NOT NULL DEFAULT 0,
+	error jsonb NULL,
+	sent_at timestamptz NULL,
+	CONSTRAINT command_job_items_pk PRIMARY KEY (job, modem),
+	CONSTRAINT command_job_items_job_fk FOREIGN KEY (job) REFERENCES public.command_jobs(id) ON DELETE CASCADE,
+	CONSTRAINT command_job_items_modem_fk FOREIGN KEY (modem) REFERENCES public.modems(id) ON DELETE CASCADE
+);
+COMMENT ON TABLE public.command_job_items IS 'Результат массовой отправки команды по каждому модему';
+COMMENT ON COLUMN public.command_job_items.status IS '0 - ожидает отправки, 1 - отправлена, 2 - ошибка';
@@ -0,0 +1 @@
+DROP TABLE public.command_templates;
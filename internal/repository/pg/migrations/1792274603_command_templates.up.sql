@@ -0,0 +1,15 @@
+CREATE TABLE public.command_templates (
+	"name" varchar(50) NOT NULL,
+	device varchar(10) NOT NULL DEFAULT 'modem',
+	description text NOT NULL DEFAULT '',
+	params jsonb NOT NULL DEFAULT '{}',
+	models text[] NOT NULL DEFAULT '{}',
+	roles int4[] NOT NULL DEFAULT '{}',
+	CONSTRAINT command_templates_pk PRIMARY KEY ("name"),
+	CONSTRAINT command_templates_device_check CHECK (device IN ('modem', 'seal'))
+);
+COMMENT ON TABLE public.command_templates IS 'Каталог команд модемов и пломб, параметры известных команд проверяются до отправки в сервис команд';
+COMMENT ON COLUMN public.command_templates.device IS 'modem - команда модему, seal - пломбе через модем';
+COMMENT ON COLUMN public.command_templates.params IS 'JSON Schema параметров команды';
+COMMENT ON COLUMN public.command_templates.models IS 'Аппаратные ревизии модемов, которым можно отправить команду, пусто - всем';
+COMMENT ON COLUMN public.command_templates.roles IS 'Роли пользователей, которым разрешена команда, пусто - всем';
@@ -0,0 +1,2 @@
+DROP TABLE public.command_schedule_runs;
+DROP TABLE public.command_schedules;
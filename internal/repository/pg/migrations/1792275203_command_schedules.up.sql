@@ -0,0 +1,43 @@
+CREATE TABLE public.command_schedules (
+	id serial4 NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	author int4 NULL,
+	modem int4 NOT NULL,
+	"name" varchar(50) NOT NULL,
+	params jsonb NOT NULL,
+	kind varchar(20) NOT NULL,
+	run_at timestamptz NULL,
+	cron varchar(100) NULL,
+	next_run_at timestamptz NULL,
+	last_event_at timestamptz NULL,
+	attempts int4 NOT NULL DEFAULT 0,
+	active bool NOT NULL DEFAULT true,
+	locked_until timestamptz NULL,
+	CONSTRAINT command_schedules_pk PRIMARY KEY (id),
+	CONSTRAINT command_schedules_kind_check CHECK (kind IN ('once', 'cron', 'shipping_start', 'shipping_end')),
+	CONSTRAINT command_schedules_modem_fk FOREIGN KEY (modem) REFERENCES public.modems(id) ON DELETE CASCADE,
+	CONSTRAINT command_schedules_author_fk FOREIGN KEY (author) REFERENCES public.users(id) ON DELETE SET NULL
+);
+CREATE INDEX command_schedules_modem_idx ON public.command_schedules USING btree (modem);
+CREATE INDEX command_schedules_next_run_at_idx ON public.command_schedules USING btree (next_run_at) WHERE active;
+COMMENT ON TABLE public.command_schedules IS 'Запланированные команды модемов';
+COMMENT ON COLUMN public.command_schedules.kind IS 'once - один раз в run_at, cron - по расписанию, shipping_start/shipping_end - при начале или завершении перевозки модема';
+COMMENT ON COLUMN public.command_schedules.next_run_at IS 'Время следующей отправки для once и cron';
+COMMENT ON COLUMN public.command_schedules.last_event_at IS 'Время последнего обработанного начала или завершения перевозки';
+COMMENT ON COLUMN public.command_schedules.attempts IS 'Неудачных попыток подряд из-за недоступности сервиса команд';
+COMMENT ON COLUMN public.command_schedules.locked_until IS 'Отправка захвачена экземпляром API до этого времени';
+
+CREATE TABLE public.command_schedule_runs (
+	id serial4 NOT NULL,
+	schedule int4 NOT NULL,
+	run_at timestamptz NOT NULL DEFAULT now(),
+	shipping int4 NULL,
+	status int2 NOT NULL,
+	error jsonb NULL,
+	CONSTRAINT command_schedule_runs_pk PRIMARY KEY (id),
+	CONSTRAINT command_schedule_runs_schedule_fk FOREIGN KEY (schedule) REFERENCES public.command_schedules(id) ON DELETE CASCADE,
+	CONSTRAINT command_schedule_runs_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE SET NULL
+);
+CREATE INDEX command_schedule_runs_schedule_idx ON public.command_schedule_runs USING btree (schedule, run_at);
+COMMENT ON TABLE public.command_schedule_runs IS 'Результаты отправки запланированных команд';
+COMMENT ON COLUMN public.command_schedule_runs.status IS '1 - отправлена, 2 - ошибка';
@@ -0,0 +1 @@
+DROP TABLE public.status_flags;
@@ -0,0 +1,21 @@
+CREATE TABLE public.status_flags (
+	id serial4 NOT NULL,
+	model int4 NULL,
+	protocol int2 NULL,
+	field varchar(20) NOT NULL,
+	value int4 NOT NULL,
+	code varchar(50) NOT NULL,
+	severity varchar(10) NOT NULL DEFAULT 'info',
+	label_ru varchar(255) NOT NULL,
+	label_en varchar(255) NOT NULL,
+	CONSTRAINT status_flags_pk PRIMARY KEY (id),
+	CONSTRAINT status_flags_field_check CHECK (field IN ('status', 'status_raw', 'errors_flags', 'modem_errors_code')),
+	CONSTRAINT status_flags_severity_check CHECK (severity IN ('info', 'warning', 'critical')),
+	CONSTRAINT status_flags_value_check CHECK (field = 'modem_errors_code' OR value BETWEEN 0 AND 31)
+);
+CREATE UNIQUE INDEX status_flags_uq ON public.status_flags USING btree (coalesce(model, -1), coalesce(protocol, -1), field, value);
+COMMENT ON TABLE public.status_flags IS 'Расшифровка битов status, status_raw, errors_flags и кодов modem_errors_code телеметрии модемов';
+COMMENT ON COLUMN public.status_flags.model IS 'Модель модема из телеметрии, null - все модели';
+COMMENT ON COLUMN public.status_flags.protocol IS 'Версия протокола из телеметрии, null - все версии';
+COMMENT ON COLUMN public.status_flags.value IS 'Номер бита, для modem_errors_code - код ошибки';
+COMMENT ON COLUMN public.status_flags.severity IS 'info, warning или critical';
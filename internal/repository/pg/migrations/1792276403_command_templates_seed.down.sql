@@ -0,0 +1,2 @@
+DELETE FROM public.command_templates WHERE "name" IN ('set-connect-period', 'set-coordinates-period', 'set-satellites-search-period',
+	'set-low-power-timeout', 'set-seal-connect-period', 'set-accelerometer-sensitivity', 'set-network', 'set-cable-sensitivity');
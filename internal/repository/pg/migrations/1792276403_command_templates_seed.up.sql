@@ -0,0 +1,19 @@
+-- Команды настройки, значения которых модем и пломбы передают в телеметрии (modems_data, seals_data)
+INSERT INTO public.command_templates ("name", device, description, params) VALUES
+('set-connect-period', 'modem', 'Период выхода модема на связь, минуты',
+	'{"type": "object", "required": ["connect_period"], "additionalProperties": false, "properties": {"connect_period": {"type": "integer", "minimum": 1, "maximum": 65535, "title": "Период связи, мин"}}}'),
+('set-coordinates-period', 'modem', 'Период определения координат, минуты',
+	'{"type": "object", "required": ["coordinates_period"], "additionalProperties": false, "properties": {"coordinates_period": {"type": "integer", "minimum": 1, "maximum": 65535, "title": "Период координат, мин"}}}'),
+('set-satellites-search-period', 'modem', 'Максимальное время поиска спутников, секунды',
+	'{"type": "object", "required": ["satellites_search_period"], "additionalProperties": false, "properties": {"satellites_search_period": {"type": "integer", "minimum": 1, "maximum": 65535, "title": "Поиск спутников, с"}}}'),
+('set-low-power-timeout', 'modem', 'Время до перехода модема в режим пониженного потребления, секунды',
+	'{"type": "object", "required": ["low_power_timeout"], "additionalProperties": false, "properties": {"low_power_timeout": {"type": "integer", "minimum": 0, "maximum": 65535, "title": "Переход в сон, с"}}}'),
+('set-seal-connect-period', 'modem', 'Период опроса пломб модемом, минуты',
+	'{"type": "object", "required": ["seal_connect_period"], "additionalProperties": false, "properties": {"seal_connect_period": {"type": "integer", "minimum": 1, "maximum": 65535, "title": "Период опроса пломб, мин"}}}'),
+('set-accelerometer-sensitivity', 'modem', 'Чувствительность акселерометра',
+	'{"type": "object", "required": ["sensitivity_accelerometer"], "additionalProperties": false, "properties": {"sensitivity_accelerometer": {"type": "integer", "minimum": 0, "maximum": 32767, "title": "Чувствительность"}}}'),
+('set-network', 'modem', 'Сервер и точка доступа сотовой сети',
+	'{"type": "object", "minProperties": 1, "additionalProperties": false, "properties": {"host": {"type": "string", "maxLength": 255, "title": "Сервер"}, "apn": {"type": "string", "maxLength": 100, "title": "APN"}, "dns_1": {"type": "string", "format": "ipv4", "title": "DNS 1"}, "dns_2": {"type": "string", "format": "ipv4", "title": "DNS 2"}}}'),
+('set-cable-sensitivity', 'seal', 'Диапазон чувствительности троса пломбы',
+	'{"type": "object", "required": ["serial", "sensitivity_cable"], "additionalProperties": false, "properties": {"serial": {"type": "integer", "minimum": 1, "title": "Серийный номер пломбы"}, "sensitivity_cable": {"type": "integer", "enum": [0, 1, 2], "title": "0 – Низкая, 1 – Средняя, 2 – Высокая"}}}')
+ON CONFLICT ("name") DO NOTHING;
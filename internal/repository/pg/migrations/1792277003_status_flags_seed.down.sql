@@ -0,0 +1 @@
+DELETE FROM public.status_flags WHERE model IS NULL AND protocol IS NULL;
@@ -0,0 +1,29 @@
+-- Расшифровка по умолчанию для всех моделей и версий протокола (model и protocol null).
+-- Отличия модели или протокола добавляются строками с заполненными model/protocol, они важнее общих
+INSERT INTO public.status_flags (field, value, code, severity, label_ru, label_en)
+SELECT f.field, v.value, v.code, v.severity, v.label_ru, v.label_en
+FROM (VALUES ('status'), ('status_raw')) f(field)
+CROSS JOIN (VALUES
+	(0, 'moving', 'info', 'Движение', 'Moving'),
+	(1, 'charging', 'info', 'Заряжается', 'Charging'),
+	(2, 'external_power', 'info', 'Внешнее питание', 'External power'),
+	(3, 'low_battery', 'warning', 'Низкий заряд батареи', 'Low battery'),
+	(4, 'case_opened', 'critical', 'Вскрытие корпуса модема', 'Modem case opened'),
+	(5, 'shock', 'warning', 'Удар или наклон', 'Shock or tilt'),
+	(6, 'roaming', 'info', 'Роуминг', 'Roaming'),
+	(7, 'low_power_mode', 'info', 'Режим пониженного потребления', 'Low power mode')
+) v(value, code, severity, label_ru, label_en);
+
+INSERT INTO public.status_flags (field, value, code, severity, label_ru, label_en) VALUES
+	('errors_flags', 0, 'gps_module', 'critical', 'Ошибка GPS/ГЛОНАСС модуля', 'GNSS module failure'),
+	('errors_flags', 1, 'gsm_module', 'critical', 'Ошибка GSM модуля', 'GSM module failure'),
+	('errors_flags', 2, 'sim_card', 'critical', 'Ошибка SIM-карты', 'SIM card failure'),
+	('errors_flags', 3, 'rf_module', 'critical', 'Ошибка радиомодуля связи с пломбами', 'Seal radio module failure'),
+	('errors_flags', 4, 'accelerometer', 'warning', 'Ошибка акселерометра', 'Accelerometer failure'),
+	('errors_flags', 5, 'flash', 'warning', 'Ошибка флеш-памяти', 'Flash memory failure'),
+	('errors_flags', 6, 'rtc', 'warning', 'Ошибка часов реального времени', 'Real-time clock failure'),
+	('errors_flags', 7, 'battery', 'warning', 'Ошибка батареи', 'Battery failure'),
+	('modem_errors_code', 1, 'no_sim', 'critical', 'SIM-карта не обнаружена', 'SIM card not detected'),
+	('modem_errors_code', 2, 'no_registration', 'warning', 'Нет регистрации в сети', 'Not registered in network'),
+	('modem_errors_code', 3, 'no_pdp', 'warning', 'Не удалось активировать передачу данных', 'Data session activation failed'),
+	('modem_errors_code', 4, 'no_server', 'warning', 'Нет соединения с сервером', 'Server connection failed');
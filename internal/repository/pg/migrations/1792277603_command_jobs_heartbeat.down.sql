@@ -0,0 +1 @@
+ALTER TABLE public.command_jobs DROP COLUMN heartbeat_at;
@@ -0,0 +1,2 @@
+ALTER TABLE public.command_jobs ADD heartbeat_at timestamptz NULL;
+COMMENT ON COLUMN public.command_jobs.heartbeat_at IS 'Последний признак рассылки экземпляром API, null или давно - задание подхватит другой экземпляр';
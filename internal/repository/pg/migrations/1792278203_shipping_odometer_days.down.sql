@@ -0,0 +1,4 @@
+DROP TABLE public.shipping_odometer_days;
+ALTER TABLE public.shipping_odometer DROP COLUMN rejected_total;
+ALTER TABLE public.shipping_odometer DROP COLUMN points;
+ALTER TABLE public.shipping_odometer ALTER COLUMN distance TYPE int4 USING round(distance);
@@ -0,0 +1,20 @@
+ALTER TABLE public.shipping_odometer ALTER COLUMN distance TYPE float8;
+ALTER TABLE public.shipping_odometer ADD points int4 NOT NULL DEFAULT 0;
+ALTER TABLE public.shipping_odometer ADD rejected_total int4 NOT NULL DEFAULT 0;
+COMMENT ON COLUMN public.shipping_odometer.points IS 'Количество принятых координат';
+COMMENT ON COLUMN public.shipping_odometer.rejected_total IS 'Количество отброшенных координат';
+
+CREATE TABLE public.shipping_odometer_days (
+	shipping int4 NOT NULL,
+	"date" date NOT NULL,
+	distance float8 NOT NULL DEFAULT 0,
+	moving_time int4 NOT NULL DEFAULT 0,
+	CONSTRAINT shipping_odometer_days_pk PRIMARY KEY (shipping, "date"),
+	CONSTRAINT shipping_odometer_days_shipping_fk FOREIGN KEY (shipping) REFERENCES public.shipping(id) ON DELETE CASCADE
+);
+COMMENT ON TABLE public.shipping_odometer_days IS 'Пробег перевозки по суткам (время сервера)';
+COMMENT ON COLUMN public.shipping_odometer_days.distance IS 'Пройденное расстояние (в метрах)';
+COMMENT ON COLUMN public.shipping_odometer_days.moving_time IS 'Время в движении (в секундах)';
+
+-- для посчитанных ранее перевозок нет суточной разбивки, пробег пересчитывается заново
+DELETE FROM public.shipping_odometer;
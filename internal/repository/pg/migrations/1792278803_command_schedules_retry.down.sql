@@ -0,0 +1,2 @@
+COMMENT ON COLUMN public.command_schedules.next_run_at IS 'Время следующей отправки для once и cron';
+ALTER TABLE public.command_schedules DROP COLUMN retry_shipping;
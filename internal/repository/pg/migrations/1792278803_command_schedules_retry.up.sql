@@ -0,0 +1,3 @@
+ALTER TABLE public.command_schedules ADD retry_shipping int4 NULL;
+COMMENT ON COLUMN public.command_schedules.retry_shipping IS 'Перевозка, отправка по началу или завершению которой повторяется в next_run_at';
+COMMENT ON COLUMN public.command_schedules.next_run_at IS 'Время следующей отправки для once и cron или повтора для shipping_start/shipping_end';
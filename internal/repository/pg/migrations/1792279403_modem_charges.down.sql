@@ -0,0 +1,2 @@
+DROP TABLE public.modem_charge_checks;
+DROP TABLE public.modem_charges;
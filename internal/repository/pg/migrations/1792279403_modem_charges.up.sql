@@ -0,0 +1,25 @@
+CREATE TABLE public.modem_charges (
+	modem int4 NOT NULL,
+	time_start timestamptz NOT NULL,
+	time_end timestamptz NOT NULL,
+	start_level real NOT NULL,
+	end_level real NOT NULL,
+	duration int4 NOT NULL DEFAULT 0,
+	CONSTRAINT modem_charges_pk PRIMARY KEY (modem, time_start),
+	CONSTRAINT modem_charges_modem_fk FOREIGN KEY (modem) REFERENCES public.modems(id) ON DELETE CASCADE
+);
+CREATE INDEX modem_charges_time_end_idx ON public.modem_charges USING btree (modem, time_end);
+COMMENT ON TABLE public.modem_charges IS 'Сеансы зарядки модемов по росту уровня заряда';
+COMMENT ON COLUMN public.modem_charges.time_end IS 'Время достижения максимума, для незавершённой зарядки обновляется';
+COMMENT ON COLUMN public.modem_charges.duration IS 'Длительность (в минутах)';
+
+CREATE TABLE public.modem_charge_checks (
+	modem int4 NOT NULL,
+	checked_at timestamptz NOT NULL,
+	resume_from timestamptz NOT NULL,
+	CONSTRAINT modem_charge_checks_pk PRIMARY KEY (modem),
+	CONSTRAINT modem_charge_checks_modem_fk FOREIGN KEY (modem) REFERENCES public.modems(id) ON DELETE CASCADE
+);
+COMMENT ON TABLE public.modem_charge_checks IS 'Состояние поиска зарядок модемов';
+COMMENT ON COLUMN public.modem_charge_checks.checked_at IS 'Время последнего обработанного измерения';
+COMMENT ON COLUMN public.modem_charge_checks.resume_from IS 'Измерение, с которого продолжается поиск: начало ещё не закрытой зарядки';
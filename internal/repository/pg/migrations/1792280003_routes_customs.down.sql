@@ -0,0 +1,4 @@
+ALTER TABLE public.routes DROP CONSTRAINT IF EXISTS routes_custom_destination_fk;
+ALTER TABLE public.routes DROP CONSTRAINT IF EXISTS routes_custom_departure_fk;
+ALTER TABLE public.routes DROP COLUMN IF EXISTS custom_destination;
+ALTER TABLE public.routes DROP COLUMN IF EXISTS custom_departure;
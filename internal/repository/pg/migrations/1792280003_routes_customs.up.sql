@@ -0,0 +1,12 @@
+ALTER TABLE public.routes ADD COLUMN IF NOT EXISTS custom_departure int4 NULL;
+ALTER TABLE public.routes ADD COLUMN IF NOT EXISTS custom_destination int4 NULL;
+ALTER TABLE public.routes DROP CONSTRAINT IF EXISTS routes_custom_departure_fk;
+ALTER TABLE public.routes DROP CONSTRAINT IF EXISTS routes_custom_destination_fk;
+UPDATE public.routes r SET custom_departure = NULL
+	WHERE r.custom_departure IS NOT NULL AND NOT EXISTS (SELECT 1 FROM public.customs c WHERE c.id = r.custom_departure);
+UPDATE public.routes r SET custom_destination = NULL
+	WHERE r.custom_destination IS NOT NULL AND NOT EXISTS (SELECT 1 FROM public.customs c WHERE c.id = r.custom_destination);
+ALTER TABLE public.routes ADD CONSTRAINT routes_custom_departure_fk FOREIGN KEY (custom_departure) REFERENCES public.customs(id) ON DELETE SET NULL;
+ALTER TABLE public.routes ADD CONSTRAINT routes_custom_destination_fk FOREIGN KEY (custom_destination) REFERENCES public.customs(id) ON DELETE SET NULL;
+COMMENT ON COLUMN public.routes.custom_departure IS 'Таможня отправления';
+COMMENT ON COLUMN public.routes.custom_destination IS 'Таможня назначения';
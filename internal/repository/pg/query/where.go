@@ -45,6 +45,15 @@ func (q *q[T]) FilterWhere(findType uint8, field, value any) *q[T] {
 	return q
 }
 
+// Grouped заключает уже добавленные условия в скобки, чтобы следующие AND не разбивали цепочку OR
+func (q *q[T]) Grouped() *q[T] {
+	if len(q.where) > 0 {
+		q.where = fmt.Sprintf("WHERE (%s)", strings.TrimPrefix(q.where, "WHERE "))
+	}
+
+	return q
+}
+
 func (q *q[T]) AndWhere(findType uint8, field, value any) *q[T] {
 	return q.addWhere("AND", findType, field, value)
 }
@@ -0,0 +1,7 @@
+package service
+
+func doCheckCharges(params Params) {
+	if err := params.Usecase.Battery.Check(); err != nil {
+		params.Logger.Error("Ошибка поиска зарядок модемов: " + err.Error())
+	}
+}
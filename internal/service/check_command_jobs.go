@@ -0,0 +1,7 @@
+package service
+
+func doCheckCommandJobs(params Params) {
+	if err := params.Usecase.CommandJob.Resume(); err != nil {
+		params.Logger.Error("Ошибка возобновления массовых отправок: " + err.Error())
+	}
+}
@@ -0,0 +1,7 @@
+package service
+
+func doCheckCustomEvents(params Params) {
+	if err := params.Usecase.CustomEvent.Check(); err != nil {
+		params.Logger.Error("Ошибка проверки зон таможен: " + err.Error())
+	}
+}
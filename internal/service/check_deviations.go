@@ -0,0 +1,7 @@
+package service
+
+func doCheckDeviations(params Params) {
+	if err := params.Usecase.Deviation.Check(); err != nil {
+		params.Logger.Error("Ошибка проверки отклонений от маршрута: " + err.Error())
+	}
+}
@@ -0,0 +1,7 @@
+package service
+
+func doCheckEta(params Params) {
+	if err := params.Usecase.Eta.Check(); err != nil {
+		params.Logger.Error("Ошибка расчёта времени прибытия: " + err.Error())
+	}
+}
@@ -0,0 +1,7 @@
+package service
+
+func doCheckIncidents(params Params) {
+	if err := params.Usecase.Incident.Check(); err != nil {
+		params.Logger.Error("Ошибка проверки инцидентов пломб: " + err.Error())
+	}
+}
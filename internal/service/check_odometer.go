@@ -0,0 +1,7 @@
+package service
+
+func doCheckOdometer(params Params) {
+	if err := params.Usecase.Odometer.Check(); err != nil {
+		params.Logger.Error("Ошибка расчёта пробега: " + err.Error())
+	}
+}
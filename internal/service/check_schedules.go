@@ -0,0 +1,30 @@
+package service
+
+import "time"
+
+// SCHEDULES_INTERVAL период проверки запланированных команд. Проверка идёт в своей горутине,
+// чтобы долгие проверки телеметрии не задерживали отправку команд ко времени
+const SCHEDULES_INTERVAL = 5 * time.Second
+
+func RunCheckSchedules(params Params) {
+	go func() {
+		ticker := time.NewTicker(SCHEDULES_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-params.StopChan:
+				params.Logger.Debug("Stop RunCheckSchedules")
+				return
+			case <-ticker.C:
+				doCheckSchedules(params)
+			}
+		}
+	}()
+}
+
+func doCheckSchedules(params Params) {
+	if err := params.Usecase.Schedule.Check(); err != nil {
+		params.Logger.Error("Ошибка отправки запланированных команд: " + err.Error())
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	app_interface "seal/internal/app/interface"
+	"seal/internal/app/usecase"
 	"seal/internal/repository/pg/query"
 	"time"
 
@@ -13,6 +14,7 @@ type Params struct {
 	Ctx      context.Context
 	Db       *pgxpool.Pool
 	Logger   app_interface.Logger
+	Usecase  *usecase.Usecase
 	StopChan chan bool
 }
 
@@ -28,6 +30,13 @@ func RunCheckTelemetry(params Params) {
 			case <-time.After(100 * time.Millisecond):
 				if waitLoopNumber == 30 {
 					doCheckTelemetry(params)
+					doCheckDeviations(params)
+					doCheckIncidents(params)
+					doCheckEta(params)
+					doCheckCustomEvents(params)
+					doCheckOdometer(params)
+					doCheckCharges(params)
+					doCheckCommandJobs(params)
 
 					waitLoopNumber = 0
 					continue
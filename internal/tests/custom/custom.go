@@ -21,6 +21,9 @@ func Run(t *testing.T, data *data.TestData) custom.Custom {
 
 	add(t)
 	update(t)
+	updateZone(t)
+	wrongZone(t)
+	clearZone(t)
 	list(t)
 	get(t)
 
@@ -56,6 +59,55 @@ func update(t *testing.T) {
 	assert.Equal(t, json.NewDecoder(w.Body).Decode(&created), nil)
 }
 
+func updateZone(t *testing.T) {
+	payload := `{"latitude": 55.75, "longitude": 37.61, "radius": 300, 
+"area": [[55.751, 37.609], [55.752, 37.612], [55.749, 37.613]]}`
+
+	url := fmt.Sprintf("/api/v1/custom/%d", created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(payload))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&created), nil)
+	assert.Len(t, created.Area, 3)
+	assert.Equal(t, 300, created.Radius)
+}
+
+func wrongZone(t *testing.T) {
+	payload := `{"area": [[55.751, 37.609], [55.752, 37.612]]}`
+
+	url := fmt.Sprintf("/api/v1/custom/%d", created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(payload))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func clearZone(t *testing.T) {
+	payload := `{"area": []}`
+
+	url := fmt.Sprintf("/api/v1/custom/%d", created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(payload))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&created), nil)
+	assert.Empty(t, created.Area)
+	assert.Equal(t, 300, created.Radius)
+}
+
 func list(t *testing.T) {
 	url := fmt.Sprintf(`/api/v1/custom?find=%s`, created.Title)
 	w := httptest.NewRecorder()
@@ -0,0 +1,52 @@
+package incident
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"seal/internal/domain/incident"
+	"seal/internal/tests/data"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testData *data.TestData
+
+func Run(t *testing.T, data *data.TestData) {
+	testData = data
+
+	list(t)
+	getNotFound(t)
+}
+
+func list(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/incident?state=%d&state=%d`, incident.STATE_NEW, incident.STATE_ACKNOWLEDGED)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var listResp struct {
+		RecordsFiltered int                 `json:"records_filtered"`
+		RecordsTotal    int                 `json:"records_total"`
+		Data            []incident.Incident `json:"data"`
+	}
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&listResp), nil)
+}
+
+func getNotFound(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/incident/0", nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -6,7 +6,9 @@ import (
 	"seal/internal/tests/auth"
 	"seal/internal/tests/custom"
 	"seal/internal/tests/data"
+	"seal/internal/tests/incident"
 	"seal/internal/tests/route"
+	"seal/internal/tests/schedule"
 	"seal/internal/tests/seal"
 	"seal/internal/tests/seal_model"
 	"seal/internal/tests/secret_area"
@@ -81,6 +83,14 @@ func TestShipping(t *testing.T) {
 	shipping.Run(t, testData)
 }
 
+func TestIncident(t *testing.T) {
+	incident.Run(t, testData)
+}
+
+func TestSchedule(t *testing.T) {
+	schedule.Run(t, testData)
+}
+
 func TestDelete(t *testing.T) {
 	route.Delete(t, testData)
 	seal.Delete(t, testData)
@@ -21,6 +21,9 @@ func Run(t *testing.T, data *data.TestData) route.Route {
 
 	add(t)
 	update(t)
+	updatePoints(t)
+	wrongPoints(t)
+	getPoints(t)
 	list(t)
 	get(t)
 
@@ -56,6 +59,58 @@ func update(t *testing.T) {
 	assert.Equal(t, json.NewDecoder(w.Body).Decode(&created), nil)
 }
 
+func updatePoints(t *testing.T) {
+	payload := fmt.Sprintf(`{"custom_departure": %d, "coords": [{"latitude": 55.75, "longitude": 37.61}, {"latitude": 47.23, "longitude": 39.72}]}`,
+		testData.Custom.Id)
+
+	url := fmt.Sprintf("/api/v1/route/%d/points", created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(payload))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var points route.Points
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&points), nil)
+	assert.Equal(t, testData.Custom.Id, points.CustomDeparture)
+	assert.Equal(t, 0, points.CustomDestination)
+	assert.Len(t, points.Coords, 2)
+}
+
+func wrongPoints(t *testing.T) {
+	payload := `{"custom_destination": 32767}`
+
+	url := fmt.Sprintf("/api/v1/route/%d/points", created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(payload))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func getPoints(t *testing.T) {
+	url := fmt.Sprintf("/api/v1/route/%d/points", created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var points route.Points
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&points), nil)
+	assert.Equal(t, testData.Custom.Id, points.CustomDeparture)
+	assert.Len(t, points.Coords, 2)
+}
+
 func list(t *testing.T) {
 	url := fmt.Sprintf(`/api/v1/route?find=%s`, created.Title)
 	w := httptest.NewRecorder()
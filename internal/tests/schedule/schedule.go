@@ -0,0 +1,169 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"seal/internal/domain/schedule"
+	"seal/internal/domain/shipping"
+	"seal/internal/tests/data"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testData *data.TestData
+var modemId int
+var created schedule.Schedule
+
+func Run(t *testing.T, data *data.TestData) {
+	testData = data
+
+	ctx := testData.App.Ctx
+	db := testData.App.Db
+
+	// модемы создаются приёмом телеметрии, поэтому напрямую в БД
+	err := db.QueryRow(ctx, `INSERT INTO modems (imei, serial) VALUES ($1, $1) RETURNING id`,
+		testData.TimeStamp+1).Scan(&modemId)
+	assert.Equal(t, err, nil)
+
+	defer func() {
+		_, err := db.Exec(ctx, `DELETE FROM shipping WHERE modem = $1`, modemId)
+		assert.Equal(t, err, nil)
+		_, err = db.Exec(ctx, `DELETE FROM modems WHERE id = $1`, modemId)
+		assert.Equal(t, err, nil)
+	}()
+
+	add(t)
+	wrongAdd(t)
+	list(t)
+	due(t)
+	triggered(t)
+	del(t)
+}
+
+func request(method, url, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	return w
+}
+
+func add(t *testing.T) {
+	w := request(http.MethodPost, fmt.Sprintf("/api/v1/modem/%d/schedules", modemId),
+		`{"name":"set-connect-period","params":{"connect_period":10},"kind":"cron","cron":"*/5 * * * *"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&created), nil)
+	assert.Equal(t, schedule.KIND_CRON, created.Kind)
+	assert.NotNil(t, created.NextRunAt)
+}
+
+func wrongAdd(t *testing.T) {
+	url := fmt.Sprintf("/api/v1/modem/%d/schedules", modemId)
+
+	// команды нет в каталоге
+	w := request(http.MethodPost, url, `{"name":"reboot-now","params":{},"kind":"shipping_start"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// параметры не по схеме каталога
+	w = request(http.MethodPost, url, `{"name":"set-connect-period","params":{"connect_period":0},"kind":"shipping_start"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// once в прошлом
+	w = request(http.MethodPost, url,
+		`{"name":"set-connect-period","params":{"connect_period":10},"kind":"once","run_at":"2020-01-01T00:00:00Z"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func list(t *testing.T) {
+	w := request(http.MethodGet, fmt.Sprintf("/api/v1/modem/%d/schedules", modemId), "")
+
+	var data []schedule.Schedule
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&data), nil)
+	assert.Len(t, data, 1)
+}
+
+func due(t *testing.T) {
+	ctx := testData.App.Ctx
+	repo := schedule.NewRepo(ctx, testData.App.Db, testData.App.Logger)
+
+	var id int
+	err := testData.App.Db.QueryRow(ctx, `INSERT INTO command_schedules (modem, name, params, kind, run_at, next_run_at)
+		VALUES ($1, 'set-connect-period', '{"connect_period": 10}', $2, now() - interval '1 minute', now() - interval '1 minute')
+		RETURNING id`, modemId, schedule.KIND_ONCE).Scan(&id)
+	assert.Equal(t, err, nil)
+
+	var ids []int
+
+	first, err := repo.Due(schedule.CHECK_BATCH)
+	assert.Equal(t, err, nil)
+	for _, d := range first {
+		ids = append(ids, d.Id)
+	}
+	assert.Contains(t, ids, id)
+
+	// захваченная отправка второй раз не отдаётся
+	ids = nil
+	second, err := repo.Due(schedule.CHECK_BATCH)
+	assert.Equal(t, err, nil)
+	for _, d := range second {
+		ids = append(ids, d.Id)
+	}
+	assert.NotContains(t, ids, id)
+}
+
+func triggered(t *testing.T) {
+	ctx := testData.App.Ctx
+	db := testData.App.Db
+	repo := schedule.NewRepo(ctx, db, testData.App.Logger)
+
+	var id int
+	err := db.QueryRow(ctx, `INSERT INTO command_schedules (modem, name, params, kind, last_event_at)
+		VALUES ($1, 'set-connect-period', '{"connect_period": 10}', $2, now() - interval '1 hour')
+		RETURNING id`, modemId, schedule.KIND_SHIPPING_START).Scan(&id)
+	assert.Equal(t, err, nil)
+
+	var shippingId int
+	err = db.QueryRow(ctx, `INSERT INTO shipping (author, custom_number, create_date, number, transport, route, modem, status, time_start)
+		VALUES ($1, '87654321', '240101', $2, $3, $4, $5, $6, now() - interval '1 minute')
+		RETURNING id`, testData.User.Id, testData.TimeStamp%1000000+1, testData.Transport.Id, testData.Route.Id,
+		modemId, shipping.STATUS_ACTIVE).Scan(&shippingId)
+	assert.Equal(t, err, nil)
+
+	var ids []int
+
+	first, err := repo.Triggered(schedule.CHECK_BATCH)
+	assert.Equal(t, err, nil)
+	for _, d := range first {
+		ids = append(ids, d.Id)
+		if d.Id == id {
+			assert.Equal(t, &shippingId, d.Shipping)
+		}
+	}
+	assert.Contains(t, ids, id)
+
+	// начало перевозки отправляется один раз
+	ids = nil
+	second, err := repo.Triggered(schedule.CHECK_BATCH)
+	assert.Equal(t, err, nil)
+	for _, d := range second {
+		ids = append(ids, d.Id)
+	}
+	assert.NotContains(t, ids, id)
+}
+
+func del(t *testing.T) {
+	w := request(http.MethodDelete, fmt.Sprintf("/api/v1/modem/%d/schedules/%d", modemId, created.Id), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = request(http.MethodDelete, fmt.Sprintf("/api/v1/modem/%d/schedules/%d", modemId+1, created.Id), "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -56,7 +56,7 @@ func update(t *testing.T) {
 }
 
 func list(t *testing.T) {
-	url := fmt.Sprintf(`/api/v1/seal?find=%s`, created.SerialNumber)
+	url := fmt.Sprintf(`/api/v1/seal?find=%d`, created.Serial)
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, url, nil)
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
@@ -1,11 +1,16 @@
 package shipping
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"seal/internal/domain/odometer"
 	"seal/internal/domain/shipping"
+	"seal/internal/domain/upload_token"
 	"seal/internal/tests/data"
 	"strings"
 	"testing"
@@ -20,8 +25,12 @@ func Run(t *testing.T, data *data.TestData) {
 	testData = data
 
 	add(t)
+	wrongAttachSeal(t)
+	attachSeal(t)
 	start(t)
 	wrongStart(t)
+	suspend(t)
+	resume(t)
 	end(t)
 	wrongEnd(t)
 	update(t)
@@ -29,6 +38,22 @@ func Run(t *testing.T, data *data.TestData) {
 	get(t)
 	getRoute(t)
 	getTelemetry(t)
+	getSimplifiedCoordinates(t)
+	exportTrack(t)
+	exportTrackWrongFormat(t)
+	getDeviations(t)
+	getCustomEvents(t)
+	getStops(t)
+	getOdometer(t)
+	getHistory(t)
+	getPauses(t)
+	getReport(t)
+	importDryRun(t)
+	importInvalid(t)
+	uploadFiles(t)
+	wrongUploadFiles(t)
+	getFilesBundle(t)
+	uploadByToken(t)
 	del(t)
 }
 
@@ -37,8 +62,8 @@ func add(t *testing.T) {
 	val := numbers[len(numbers)-6:]
 
 	payload := fmt.Sprintf(`{"custom_number": "00%s", "create_date": "%s", "number": %d, "transport": %d, 
-"route": %d, "seal": %d}`, val, val, testData.TimeStamp,
-		testData.Transport.Id, testData.Route.Id, testData.Seal.Id)
+"route": %d}`, val, val, testData.TimeStamp,
+		testData.Transport.Id, testData.Route.Id)
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/shipping", strings.NewReader(payload))
@@ -49,7 +74,77 @@ func add(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, json.NewDecoder(w.Body).Decode(&created), nil)
-	assert.NotEmpty(t, created.Seal.SerialNumber)
+	assert.NotEmpty(t, created.Id)
+	assert.Empty(t, created.Seals)
+}
+
+func wrongAttachSeal(t *testing.T) {
+	url := fmt.Sprintf("/api/v1/shipping/%d/seals/%d", created.Id, testData.Seal.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func attachSeal(t *testing.T) {
+	ctx := testData.App.Ctx
+	db := testData.App.Db
+
+	// модем с пломбой в списке, модемы создаются приёмом телеметрии, поэтому напрямую в БД
+	var modemId int
+	err := db.QueryRow(ctx, `INSERT INTO modems (imei, serial, serials_of_seals) VALUES ($1, $1, $2) RETURNING id`,
+		testData.TimeStamp, []int64{int64(testData.Seal.Serial)}).Scan(&modemId)
+	assert.Equal(t, err, nil)
+
+	defer func() {
+		_, err := db.Exec(ctx, `UPDATE shipping SET modem = null WHERE id = $1`, created.Id)
+		assert.Equal(t, err, nil)
+		_, err = db.Exec(ctx, `DELETE FROM modems WHERE id = $1`, modemId)
+		assert.Equal(t, err, nil)
+	}()
+
+	_, err = db.Exec(ctx, `UPDATE shipping SET modem = $1 WHERE id = $2`, modemId, created.Id)
+	assert.Equal(t, err, nil)
+
+	url := fmt.Sprintf("/api/v1/shipping/%d/seals/%d", created.Id, testData.Seal.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var attached shipping.Shipping
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&attached), nil)
+	assert.Len(t, attached.Seals, 1)
+	if len(attached.Seals) == 1 {
+		assert.Equal(t, testData.Seal.Id, attached.Seals[0].Id)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var detached shipping.Shipping
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&detached), nil)
+	assert.Len(t, detached.Seals, 0)
+
+	// повторное отвязывание - пломбы у перевозки уже нет
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
 func start(t *testing.T) {
@@ -76,6 +171,30 @@ func wrongStart(t *testing.T) {
 	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 }
 
+func suspend(t *testing.T) {
+	url := fmt.Sprintf("/api/v1/shipping/%d/suspend", created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(`{"reason": "Таможенный досмотр"}`))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func resume(t *testing.T) {
+	url := fmt.Sprintf("/api/v1/shipping/%d/resume", created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
 func end(t *testing.T) {
 	url := fmt.Sprintf("/api/v1/shipping/%d/end", created.Id)
 	w := httptest.NewRecorder()
@@ -149,7 +268,12 @@ func get(t *testing.T) {
 
 	testData.App.Router.ServeHTTP(w, req)
 
+	var item shipping.Shipping
+
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&item), nil)
+	assert.False(t, item.PlannedArrivalTime.IsZero())
+	assert.False(t, item.EstimatedArrivalTime.IsZero())
 }
 
 func getRoute(t *testing.T) {
@@ -176,6 +300,299 @@ func getTelemetry(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func getSimplifiedCoordinates(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/coordinates?zoom=12`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func exportTrack(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/export/gpx`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/gpx+xml", w.Header().Get("Content-Type"))
+}
+
+func exportTrackWrongFormat(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/export/shp`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func getDeviations(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/deviations`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func getCustomEvents(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/custom-events`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func getStops(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/stops?min_duration=10&radius=200`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func getOdometer(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/odometer`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var data odometer.ShippingOdometer
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&data), nil)
+	assert.Equal(t, testData.Route.Length, data.PlannedDistance)
+}
+
+func getHistory(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/history`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var history []shipping.History
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&history), nil)
+	assert.Len(t, history, 4)
+}
+
+func getPauses(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/pauses`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var pauses []shipping.Pause
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&pauses), nil)
+	assert.Len(t, pauses, 1)
+	assert.NotNil(t, pauses[0].TimeEnd)
+}
+
+func getReport(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/report`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), created.CustomNumber)
+}
+
+func importDryRun(t *testing.T) {
+	csv := fmt.Sprintf("custom_number;create_date;number;transport;route\n%s;%s;%d;%s;%s\n%s;%s;%d;%s;%s\n",
+		created.CustomNumber, created.CreateDate, created.Number, testData.Transport.Title, testData.Route.Title,
+		created.CustomNumber, created.CreateDate, created.Number+1, testData.Transport.Title, testData.Route.Title)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "import.csv")
+	part.Write([]byte(csv))
+	writer.WriteField("dry_run", "true")
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/shipping/import", body)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var result shipping.ImportResult
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&result), nil)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, 0, result.Created)
+	assert.False(t, result.Rows[0].Valid)
+}
+
+func importInvalid(t *testing.T) {
+	csv := fmt.Sprintf("custom_number;create_date;number;transport;route\n%s;%s;%d;%s;%s\n;;;;\n%s;%s;%d;%s;%s\n",
+		created.CustomNumber, created.CreateDate, created.Number, testData.Transport.Title, testData.Route.Title,
+		created.CustomNumber, created.CreateDate, created.Number+1, testData.Transport.Title, testData.Route.Title)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "import.csv")
+	part.Write([]byte(csv))
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/shipping/import", body)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var result shipping.ImportResult
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&result), nil)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, 0, result.Created)
+	assert.Nil(t, result.Rows[1].Shipping)
+}
+
+var testPng = append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), bytes.Repeat([]byte{0}, 64)...)
+
+func uploadRequest(name string, content []byte) *http.Request {
+	return uploadRequestTo(fmt.Sprintf(`/api/v1/shipping/%d/files/1`, created.Id), name, content)
+}
+
+func uploadRequestTo(url, name string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", name)
+	part.Write(content)
+	writer.WriteField("comment", "test")
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, url, body)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+
+	return req
+}
+
+func uploadFiles(t *testing.T) {
+	w := httptest.NewRecorder()
+	testData.App.Router.ServeHTTP(w, uploadRequest("seal.png", testPng))
+
+	var result shipping.Shipping
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&result), nil)
+	assert.NotEmpty(t, result.Files)
+}
+
+func wrongUploadFiles(t *testing.T) {
+	// повтор уже прикреплённого файла
+	w := httptest.NewRecorder()
+	testData.App.Router.ServeHTTP(w, uploadRequest("seal_copy.png", testPng))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "seal_copy.png")
+
+	// исполняемый файл
+	w = httptest.NewRecorder()
+	testData.App.Router.ServeHTTP(w, uploadRequest("photo.jpg", append([]byte("MZ\x90\x00"), bytes.Repeat([]byte{0}, 128)...)))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "photo.jpg")
+}
+
+func getFilesBundle(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/files.zip?type=1`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.Equal(t, err, nil)
+
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Contains(t, names, "seal.png")
+	assert.Contains(t, names, shipping.BUNDLE_MANIFEST)
+}
+
+func uploadByToken(t *testing.T) {
+	url := fmt.Sprintf(`/api/v1/shipping/%d/upload-tokens`, created.Id)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(`{"types":[1],"ttl":60}`))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", testData.Jwt.Token))
+	req.Header.Add("Content-Type", "application/json")
+
+	testData.App.Router.ServeHTTP(w, req)
+
+	var token upload_token.Created
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&token), nil)
+	assert.NotEmpty(t, token.Token)
+
+	png := append(append([]byte{}, testPng...), 1)
+	url = fmt.Sprintf(`/api/v1/shipping-temp/%d/files/1`, created.Id)
+
+	// загрузка без учётной записи
+	w = httptest.NewRecorder()
+	req = uploadRequestTo(url, "driver.png", png)
+	req.Header.Del("Authorization")
+	req.Header.Set(upload_token.HEADER, token.Token)
+	testData.App.Router.ServeHTTP(w, req)
+
+	var files []shipping.File
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, json.NewDecoder(w.Body).Decode(&files), nil)
+	assert.Equal(t, token.Id, files[0].UploadToken)
+
+	// ключ одноразовый
+	w = httptest.NewRecorder()
+	req = uploadRequestTo(url, "driver2.png", append(png, 2))
+	req.Header.Del("Authorization")
+	req.Header.Set(upload_token.HEADER, token.Token)
+	testData.App.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
 func del(t *testing.T) {
 	url := fmt.Sprintf(`/api/v1/shipping/%d`, created.Id)
 	w := httptest.NewRecorder()
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"sync"
+	"time"
+)
+
+const BREAKER_CLOSED = "closed"
+const BREAKER_OPEN = "open"
+const BREAKER_HALF_OPEN = "half-open"
+
+// BREAKER_FAILURES подряд неудачных из-за недоступности сервиса вызовов, после которых вызовы не выполняются
+const BREAKER_FAILURES = 5
+
+// BREAKER_COOLDOWN сколько вызовы не выполняются, затем пропускается один пробный
+const BREAKER_COOLDOWN = 10 * time.Second
+
+// breaker размыкается после BREAKER_FAILURES отказов подряд, пока он разомкнут - вызовы сразу получают dlErrTxt
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	threshold int
+	cooldown  time.Duration
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow можно ли выполнить вызов, в полуоткрытом состоянии пропускается только один
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+
+	if time.Since(b.openedAt) < b.cooldown || b.probing {
+		return false
+	}
+
+	b.probing = true
+
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *breaker) state() (string, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.failures < b.threshold:
+		return BREAKER_CLOSED, b.failures
+	case b.probing || time.Since(b.openedAt) >= b.cooldown:
+		return BREAKER_HALF_OPEN, b.failures
+	}
+
+	return BREAKER_OPEN, b.failures
+}
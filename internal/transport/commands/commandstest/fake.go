@@ -0,0 +1,105 @@
+// Package commandstest поднимает в памяти поддельный сервис команд на bufconn
+// для проверки клиента commands без сети и настоящего сервиса
+package commandstest
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	commands_v1 "gitlab.kvant.online/seal/grpc-contracts/pkg/commands/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// ADDR адрес для commands.NewGRPC, соединение всё равно идёт через Dialer
+const ADDR = "passthrough:///bufconn"
+
+const bufSize = 1 << 20
+
+// Fake сервис команд: запоминает добавленные команды и отдаёт Commands страницами.
+// Если задан Err, все вызовы возвращают его, например status.Error(codes.Unavailable, "")
+type Fake struct {
+	commands_v1.UnimplementedCommandsServiceServer
+
+	mu       sync.Mutex
+	Added    []*commands_v1.AddRequest
+	Commands []*commands_v1.Command
+	Err      error
+	Calls    int
+}
+
+func (f *Fake) SetErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Err = err
+}
+
+func (f *Fake) CallsCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.Calls
+}
+
+func (f *Fake) Add(_ context.Context, r *commands_v1.AddRequest) (*commands_v1.AddResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Calls++
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	f.Added = append(f.Added, r)
+
+	return &commands_v1.AddResponse{}, nil
+}
+
+func (f *Fake) List(_ context.Context, r *commands_v1.ListRequest) (*commands_v1.ListResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Calls++
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	var found []*commands_v1.Command
+	for _, c := range f.Commands {
+		if r.Imei == "" || c.Imei == r.Imei {
+			found = append(found, c)
+		}
+	}
+
+	from := min(int(r.Offset), len(found))
+	to := min(from+int(r.Limit), len(found))
+
+	return &commands_v1.ListResponse{Commands: found[from:to]}, nil
+}
+
+// Logger логгер для клиента в тестах, ничего не пишет
+type Logger struct{}
+
+func (Logger) Fatal(msg string, args ...any)                   {}
+func (Logger) Error(msg string, args ...any)                   {}
+func (Logger) Info(msg string, args ...any)                    {}
+func (Logger) Debug(msg string, args ...any)                   {}
+func (Logger) DebugOrError(err error, msg string, args ...any) {}
+
+// Listen запускает сервер с fake, возвращает опцию подключения для commands.NewGRPC и остановку сервера
+func Listen(fake *Fake) (grpc.DialOption, func()) {
+	listener := bufconn.Listen(bufSize)
+
+	server := grpc.NewServer()
+	commands_v1.RegisterCommandsServiceServer(server, fake)
+
+	go server.Serve(listener)
+
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	})
+
+	return dialer, server.Stop
+}
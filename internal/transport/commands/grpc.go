@@ -4,52 +4,194 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"math/rand"
 	app_interface "seal/internal/app/interface"
 	"seal/internal/domain/command"
-	"seal/internal/repository/pg/query"
 	"seal/pkg/app_error"
 	"time"
 
 	commands_v1 "gitlab.kvant.online/seal/grpc-contracts/pkg/commands/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/status"
 )
 
 var dlErrTxt = errors.New("Сервис команд временно недоступен, повторите оерацию позже.")
 
+// LIST_RETRIES повторов List при недоступности сервиса, Add не повторяется - команда могла дойти
+const LIST_RETRIES = 3
+
+// RETRY_BACKOFF пауза перед первым повтором, дальше удваивается до RETRY_MAX_BACKOFF
+const RETRY_BACKOFF = 200 * time.Millisecond
+const RETRY_MAX_BACKOFF = 2 * time.Second
+
+// serviceConfig проверка здоровья соединения сервисом grpc.health.v1, если сервер его не реализует - проверка отключается
+const serviceConfig = `{"loadBalancingConfig": [{"round_robin": {}}], "healthCheckConfig": {"serviceName": ""}}`
+
+// Status состояние соединения с сервисом команд для проверки здоровья.
+// Адрес сервиса не отдаётся - ответ доступен без авторизации
+type Status struct {
+	State    string `json:"state"`
+	Breaker  string `json:"breaker"`
+	Failures int    `json:"failures"`
+}
+
+// Service соединение с сервисом команд, которое живёт всё время работы приложения
+type Service interface {
+	Status() Status
+	Close() error
+}
+
 type grpcClient struct {
 	ctx     context.Context
 	addr    string
-	timeout uint8
+	timeout time.Duration
 	logger  app_interface.Logger
+	conn    *grpc.ClientConn
+	client  commands_v1.CommandsServiceClient
+	breaker *breaker
 }
 
-func NewGRPC(ctx context.Context, addr string, timeout uint8, logger app_interface.Logger) grpcClient {
-	return grpcClient{
-		ctx,
-		addr,
-		timeout,
-		logger,
+// NewGRPC открывает одно соединение на всё время работы, без ожидания подключения.
+// opts дополняют и переопределяют параметры подключения, например grpc.WithContextDialer для bufconn в тестах
+func NewGRPC(ctx context.Context, addr string, timeout uint8, keepaliveTime uint16, logger app_interface.Logger, opts ...grpc.DialOption) *grpcClient {
+	s := &grpcClient{
+		ctx:     ctx,
+		addr:    addr,
+		timeout: time.Second * time.Duration(timeout),
+		logger:  logger,
+		breaker: newBreaker(BREAKER_FAILURES, BREAKER_COOLDOWN),
+	}
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		// без активных вызовов пинги не отправляются: сервер по умолчанию считает их нарушением
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    time.Second * time.Duration(keepaliveTime),
+			Timeout: s.timeout + time.Second,
+		}),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: s.timeout,
+		}),
+	}, opts...)
+
+	conn, err := grpc.DialContext(ctx, addr, dialOpts...)
+	if err != nil {
+		// до исправления адреса в конфиге все вызовы получают dlErrTxt
+		logger.Error(fmt.Sprintf("Commands service %s: %v", addr, err))
+		return s
 	}
+
+	s.conn = conn
+	s.client = commands_v1.NewCommandsServiceClient(conn)
+
+	conn.Connect()
+	go s.watch()
+
+	return s
 }
 
-func (s grpcClient) Send(imei string, name string, params any, author string) (bool, error) {
-	conn, err := s.getConnection()
+// watch пишет в лог смену состояния соединения и переподключается после простоя
+func (s *grpcClient) watch() {
+	for {
+		state := s.conn.GetState()
 
-	if err != nil {
-		return false, err
+		if state == connectivity.Shutdown {
+			return
+		}
+
+		if state == connectivity.Idle {
+			s.conn.Connect()
+		}
+
+		if !s.conn.WaitForStateChange(s.ctx, state) {
+			return
+		}
+
+		s.logger.Info(fmt.Sprintf("Commands service %s connection %s", s.addr, s.conn.GetState()))
 	}
+}
+
+func (s *grpcClient) Status() Status {
+	status := Status{State: connectivity.Shutdown.String()}
+	if s.conn != nil {
+		status.State = s.conn.GetState().String()
+	}
+
+	status.Breaker, status.Failures = s.breaker.state()
 
-	defer conn.Close()
+	return status
+}
 
-	ctx, cancel := context.WithTimeout(s.ctx, time.Second*time.Duration(s.timeout))
-	defer cancel()
+func (s *grpcClient) Close() error {
+	if s.conn == nil {
+		return nil
+	}
 
-	c := commands_v1.NewCommandsServiceClient(conn)
+	return s.conn.Close()
+}
+
+// call выполняет вызов с таймаутом и повторяет его retries раз, пока сервис недоступен
+func (s *grpcClient) call(retries int, fn func(ctx context.Context) error) error {
+	if s.client == nil {
+		return dlErrTxt
+	}
+
+	pause := RETRY_BACKOFF
+
+	for attempt := 0; ; attempt++ {
+		if !s.breaker.allow() {
+			return dlErrTxt
+		}
+
+		ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+		err := fn(ctx)
+		cancel()
+
+		if !unavailable(err) {
+			s.breaker.success()
+			return err
+		}
+
+		s.breaker.failure()
+
+		if attempt >= retries {
+			return err
+		}
 
+		// разброс, чтобы повторы разных запросов не приходили одновременно
+		jitter := time.Duration(rand.Int63n(int64(pause) / 5))
+
+		select {
+		case <-s.ctx.Done():
+			return err
+		case <-time.After(pause + jitter):
+		}
+
+		pause = min(pause*2, RETRY_MAX_BACKOFF)
+	}
+}
+
+func unavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	code := status.Code(err)
+
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+func (s *grpcClient) Send(imei string, name string, params any, author string) (bool, error) {
 	jsonParams, err := json.Marshal(params)
 	if err != nil {
 		s.logger.Error(err.Error())
@@ -64,9 +206,15 @@ func (s grpcClient) Send(imei string, name string, params any, author string) (b
 		Author:   author,
 	}
 
-	r, err := c.Add(ctx, rBody)
+	var r *commands_v1.AddResponse
+	err = s.call(0, func(ctx context.Context) (err error) {
+		r, err = s.client.Add(ctx, rBody)
+		return err
+	})
 
-	if err != nil {
+	if errors.Is(err, dlErrTxt) {
+		return false, err
+	} else if err != nil {
 
 		st, _ := status.FromError(err)
 		s.logger.Error(st.Message())
@@ -100,43 +248,34 @@ func (s grpcClient) Send(imei string, name string, params any, author string) (b
 	return true, nil
 }
 
-func (s grpcClient) List(imei string) (query.List[command.Command], error) {
-	conn, err := s.getConnection()
-
-	if err != nil {
-		return query.List[command.Command]{}, err
-	}
-
-	defer conn.Close()
-
-	ctx, cancel := context.WithTimeout(s.ctx, time.Second*time.Duration(s.timeout))
-	defer cancel()
-
-	c := commands_v1.NewCommandsServiceClient(conn)
-
+func (s *grpcClient) List(imei string, offset int, limit int, newOnly bool) ([]command.Command, error) {
 	rBody := &commands_v1.ListRequest{
 		Imei:    imei,
-		Offset:  0,
-		Limit:   10,
-		NewOnly: false,
+		Offset:  int32(offset),
+		Limit:   int32(limit),
+		NewOnly: newOnly,
 	}
 
-	r, err := c.List(ctx, rBody)
+	var r *commands_v1.ListResponse
+	err := s.call(LIST_RETRIES, func(ctx context.Context) (err error) {
+		r, err = s.client.List(ctx, rBody)
+		return err
+	})
 
-	if err != nil {
+	if errors.Is(err, dlErrTxt) {
+		return nil, err
+	} else if err != nil {
 
 		s.logger.Error(err.Error())
 		st, _ := status.FromError(err)
 		if st.Code() == codes.DeadlineExceeded || st.Code() == codes.Unavailable {
-			return query.List[command.Command]{}, dlErrTxt
+			return nil, dlErrTxt
 		}
 
-		return query.List[command.Command]{}, err
+		return nil, err
 	}
 
-	cmdList := query.List[command.Command]{
-		Data: make([]command.Command, 0),
-	}
+	cmdList := make([]command.Command, 0, len(r.GetCommands()))
 
 	for _, cmd := range r.GetCommands() {
 
@@ -157,7 +296,7 @@ func (s grpcClient) List(imei string) (query.List[command.Command], error) {
 
 		response := cmd.GetResponse()
 
-		cmdList.Data = append(cmdList.Data, command.Command{
+		cmdList = append(cmdList, command.Command{
 			Id:           cmd.GetId(),
 			Serial:       cmd.GetImei(),
 			Name:         cmd.GetName(),
@@ -176,22 +315,3 @@ func (s grpcClient) List(imei string) (query.List[command.Command], error) {
 
 	return cmdList, nil
 }
-
-func (s grpcClient) getConnection() (*grpc.ClientConn, error) {
-	ctx, cf1 := context.WithTimeout(s.ctx, time.Second*time.Duration(s.timeout))
-	defer cf1()
-
-	conn, err := grpc.DialContext(ctx, s.addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
-
-	if err != nil {
-		s.logger.Error(err.Error())
-
-		if errors.Is(err, context.DeadlineExceeded) {
-			return nil, dlErrTxt
-		}
-
-		return nil, err
-	}
-
-	return conn, nil
-}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"seal/internal/transport/commands/commandstest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	commands_v1 "gitlab.kvant.online/seal/grpc-contracts/pkg/commands/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestClient(t *testing.T, fake *commandstest.Fake) *grpcClient {
+	dialer, stop := commandstest.Listen(fake)
+	client := NewGRPC(context.Background(), commandstest.ADDR, 1, 60, commandstest.Logger{}, dialer)
+
+	t.Cleanup(func() {
+		client.Close()
+		stop()
+	})
+
+	return client
+}
+
+func TestBreaker(t *testing.T) {
+	b := newBreaker(2, 50*time.Millisecond)
+
+	state, _ := b.state()
+	assert.Equal(t, BREAKER_CLOSED, state)
+
+	b.failure()
+	state, failures := b.state()
+	assert.Equal(t, BREAKER_CLOSED, state)
+	assert.Equal(t, 1, failures)
+	assert.True(t, b.allow())
+
+	b.failure()
+	state, _ = b.state()
+	assert.Equal(t, BREAKER_OPEN, state)
+	assert.False(t, b.allow())
+
+	time.Sleep(60 * time.Millisecond)
+	state, _ = b.state()
+	assert.Equal(t, BREAKER_HALF_OPEN, state)
+	assert.True(t, b.allow(), "пробный вызов")
+	assert.False(t, b.allow(), "второй вызов до результата пробного")
+
+	b.failure()
+	state, _ = b.state()
+	assert.Equal(t, BREAKER_OPEN, state)
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, b.allow())
+	b.success()
+	state, failures = b.state()
+	assert.Equal(t, BREAKER_CLOSED, state)
+	assert.Equal(t, 0, failures)
+}
+
+func TestSendAndList(t *testing.T) {
+	fake := &commandstest.Fake{Commands: []*commands_v1.Command{{Id: 1, Imei: "100"}, {Id: 2, Imei: "200"}}}
+	client := newTestClient(t, fake)
+
+	ok, err := client.Send("100", "reboot", map[string]int{"delay": 1}, "admin")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, fake.Added, 1)
+	assert.Equal(t, `{"delay":1}`, fake.Added[0].Params)
+
+	list, err := client.List("200", 0, 10, false)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, int32(2), list[0].Id)
+}
+
+func TestRetries(t *testing.T) {
+	fake := &commandstest.Fake{}
+	fake.SetErr(status.Error(codes.Unavailable, "down"))
+	client := newTestClient(t, fake)
+	// выключатель не должен разомкнуться за время проверки повторов
+	client.breaker = newBreaker(100, BREAKER_COOLDOWN)
+
+	_, err := client.List("100", 0, 10, false)
+	assert.ErrorIs(t, err, dlErrTxt)
+	assert.Equal(t, LIST_RETRIES+1, fake.CallsCount(), "List повторяется")
+
+	_, err = client.Send("100", "reboot", nil, "admin")
+	assert.ErrorIs(t, err, dlErrTxt)
+	assert.Equal(t, LIST_RETRIES+2, fake.CallsCount(), "Add не повторяется")
+
+	assert.Equal(t, LIST_RETRIES+2, client.Status().Failures)
+
+	fake.SetErr(status.Error(codes.InvalidArgument, "bad"))
+	_, err = client.Send("100", "reboot", nil, "admin")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, dlErrTxt)
+	assert.Equal(t, 0, client.Status().Failures, "ошибка запроса не считается отказом сервиса")
+}
+
+func TestBreakerOpens(t *testing.T) {
+	fake := &commandstest.Fake{}
+	fake.SetErr(status.Error(codes.Unavailable, "down"))
+	client := newTestClient(t, fake)
+	client.breaker = newBreaker(BREAKER_FAILURES, 50*time.Millisecond)
+
+	for i := 0; i < BREAKER_FAILURES; i++ {
+		client.Send("100", "reboot", nil, "admin")
+	}
+
+	assert.Equal(t, BREAKER_OPEN, client.Status().Breaker)
+
+	_, err := client.Send("100", "reboot", nil, "admin")
+	assert.ErrorIs(t, err, dlErrTxt)
+	assert.Equal(t, BREAKER_FAILURES, fake.CallsCount(), "разомкнутый выключатель не пропускает вызовы")
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, BREAKER_HALF_OPEN, client.Status().Breaker)
+
+	fake.SetErr(nil)
+	ok, err := client.Send("100", "reboot", nil, "admin")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, BREAKER_CLOSED, client.Status().Breaker)
+}
@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"seal/internal/domain/command"
+	"seal/pkg/app_error"
+)
+
+// List for swagger only
+type listCommand struct {
+	RecordsTotal    int                  `json:"records_total"`
+	RecordsFiltered int                  `json:"records_filtered"`
+	Data            []command.Command    `json:"data"`
+	Modems          []int                `json:"modems"`
+	Errors          []command.FleetError `json:"errors"`
+}
+
+func (h *Handler) registerCommandHandler(api *gin.RouterGroup) {
+	group := api.Group("/commands")
+	{
+		group.GET("", h.commandList)
+	}
+}
+
+// ListCommands godoc
+// @Summary      List commands of all modems
+// @Description  pending, delivered, failed and aborted commands of a page of modems (by ids, by search or all, 50 modems max), default period is the last 24 hours. Modems whose commands could not be loaded are listed in errors, commands of the others are returned
+// @Tags         command
+// @Accept       json
+// @Param        find    	  query     string  false  "modem serial or imei search string"
+// @Param        find_type    query     int     false  "search type (0 - '=', 1 - 'like', 2 = 'ilike')"	Enums(0, 1, 2)
+// @Param        modem        query     []int   false  "modem ids"	collectionFormat(multi)
+// @Param        modem_limit  query     int     false  "modems on page, default 50"	minimum(0)	maximum (50)
+// @Param        modem_offset query     int     false  "modems offset"	minimum(0)	maximum (32767)
+// @Param        limit        query     int     false  "limit, default 10"	minimum(0)	maximum (100)
+// @Param        offset       query     int     false  "offset"	minimum(0)	maximum (32767)
+// @Param        new_only     query     bool    false  "new_only"
+// @Param        from		  query	    string	false "from"
+// @Param        to	    	  query	    string	false "to"
+// @Param        status       query     []string false "status"	Enums(pending, delivered, failed, aborted)	collectionFormat(multi)
+// @Success      200	{object}	listCommand
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /commands [get]
+// @Security 	 BearerAuth
+func (h *Handler) commandList(c *gin.Context) {
+	var queryParams command.FleetQueryParams
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if list, err := h.Usecase.Command.Fleet(queryParams); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, list)
+	}
+}
@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"seal/internal/domain/command"
+	"seal/internal/domain/command_job"
+	"seal/pkg/app_error"
+	"strconv"
+)
+
+// CommandCatalogModem godoc
+// @Summary      Command catalog
+// @Description  known modem and seal commands with JSON Schema of params for forms. Params of catalog commands are validated before sending, models and roles restrict who and where can send them
+// @Tags         modem
+// @Accept       json
+// @Produce      json
+// @Param        device       query     string     false  "device"	Enums(modem, seal)
+// @Success      200	{array}	command.Template
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/commands/catalog [get]
+// @Security 	 BearerAuth
+func (h *Handler) modemCommandCatalog(c *gin.Context) {
+	var queryParams command.CatalogQueryParams
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if data, err := h.Usecase.Command.Catalog(queryParams); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// BulkCommandModem godoc
+// @Summary      Send command to many modems
+// @Description  send command to modems by ids or by filter (software/hardware version, serial or imei search). Command is dispatched in background, poll the job for per-modem results
+// @Tags         modem
+// @Accept       json
+// @Produce      json
+// @Param		 data	body	command_job.BulkRequest	true	"data"
+// @Success      202	{object}	command_job.Job
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/commands/bulk [post]
+// @Security 	 BearerAuth
+func (h *Handler) modemBulkCommand(c *gin.Context) {
+	var fromRequest command_job.BulkRequest
+	if err := c.ShouldBind(&fromRequest); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	userId := c.GetInt("userId")
+	if userId == 0 {
+		c.Error(app_error.InternalServerError(errors.New("can't get user")))
+		return
+	}
+	user, uerr := h.Usecase.User.GetById(userId)
+	if uerr != nil {
+		c.Error(app_error.InternalServerError(errors.New("can't get user by id")))
+		return
+	}
+
+	if data, err := h.Usecase.CommandJob.Bulk(fromRequest, userId, user.Login, user.Role); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusAccepted, data)
+	}
+}
+
+// BulkCommandJobModem godoc
+// @Summary      Bulk command job
+// @Description  bulk command progress and per-modem results (status 0 - pending, 1 - sent, 2 - failed), finished_at is set when all modems are processed
+// @Tags         modem
+// @Accept       json
+// @Produce      json
+// @Param        job_id       path     int     true  "job id"	minimum(1)
+// @Success      200	{object}	command_job.Job
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/commands/bulk/{job_id} [get]
+// @Security 	 BearerAuth
+func (h *Handler) modemBulkCommandJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("job_id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.CommandJob.GetById(id); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
@@ -23,6 +23,7 @@ func (h *Handler) Register(api *gin.RouterGroup) {
 	v1 := api.Group("/v1")
 	{
 		h.registerAuthHandler(v1)
+		h.registerHealthHandler(v1)
 		h.registerShippingTempHandler(v1)
 		v1.Use(middleware.Auth(h.JwtWorker))
 		h.registerCustomHandler(v1)
@@ -35,6 +36,8 @@ func (h *Handler) Register(api *gin.RouterGroup) {
 		h.registerTransportTypeHandler(v1)
 		h.registerUserHandler(v1)
 		h.registerModemHandler(v1)
+		h.registerCommandHandler(v1)
+		h.registerIncidentHandler(v1)
 
 	}
 }
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"net/http"
+	"seal/internal/transport/commands"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/connectivity"
+)
+
+const HEALTH_OK = "ok"
+const HEALTH_DEGRADED = "degraded" // API работает, но сервис команд недоступен
+
+type health struct {
+	Status   string          `json:"status"`
+	Commands commands.Status `json:"commands"`
+}
+
+func (h *Handler) registerHealthHandler(api *gin.RouterGroup) {
+	api.GET("/health", h.health)
+}
+
+// Health godoc
+// @Summary      Health
+// @Description  API health and commands service connection state (gRPC connectivity state and circuit breaker). Status is degraded while commands can't be sent
+// @Tags         health
+// @Produce      json
+// @Success      200	{object}	health
+// @Router       /health [get]
+func (h *Handler) health(c *gin.Context) {
+	res := health{Status: HEALTH_OK, Commands: h.Usecase.CommandsService.Status()}
+
+	if res.Commands.Breaker != commands.BREAKER_CLOSED || res.Commands.State == connectivity.TransientFailure.String() ||
+		res.Commands.State == connectivity.Shutdown.String() {
+		res.Status = HEALTH_DEGRADED
+	}
+
+	c.JSON(http.StatusOK, res)
+}
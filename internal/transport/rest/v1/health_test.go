@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seal/internal/app/usecase"
+	"seal/internal/transport/commands"
+	"seal/internal/transport/commands/commandstest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fake := &commandstest.Fake{}
+	dialer, stop := commandstest.Listen(fake)
+	defer stop()
+
+	client := commands.NewGRPC(context.Background(), commandstest.ADDR, 1, 60, commandstest.Logger{}, dialer)
+	defer client.Close()
+
+	h := &Handler{Usecase: &usecase.Usecase{CommandsService: client}}
+	router := gin.New()
+	h.registerHealthHandler(router.Group("/v1"))
+
+	get := func() (health, string) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/health", nil))
+
+		var res health
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+		return res, w.Body.String()
+	}
+
+	_, err := client.Send("100", "reboot", nil, "admin")
+	assert.NoError(t, err)
+
+	res, body := get()
+	assert.Equal(t, HEALTH_OK, res.Status)
+	assert.Equal(t, commands.BREAKER_CLOSED, res.Commands.Breaker)
+	assert.NotContains(t, body, "addr")
+	assert.NotContains(t, body, commandstest.ADDR)
+
+	fake.SetErr(status.Error(codes.Unavailable, "down"))
+	for i := 0; i < commands.BREAKER_FAILURES; i++ {
+		client.Send("100", "reboot", nil, "admin")
+	}
+
+	res, _ = get()
+	assert.Equal(t, HEALTH_DEGRADED, res.Status)
+	assert.Equal(t, commands.BREAKER_OPEN, res.Commands.Breaker)
+	assert.Equal(t, commands.BREAKER_FAILURES, res.Commands.Failures)
+}
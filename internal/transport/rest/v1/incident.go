@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"seal/internal/domain/incident"
+	"seal/pkg/app_error"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// List for swagger only
+type incidentList struct {
+	RecordsTotal    int                 `json:"records_total"`
+	RecordsFiltered int                 `json:"records_filtered"`
+	Data            []incident.Incident `json:"data"`
+}
+
+func (h *Handler) registerIncidentHandler(api *gin.RouterGroup) {
+	group := api.Group("/incident")
+	{
+		group.GET(":id", h.incident)
+		group.PUT(":id/acknowledge", h.incidentAcknowledge)
+		group.PUT(":id/resolve", h.incidentResolve)
+		group.GET("", h.incidentList)
+	}
+}
+
+// ItemIncident godoc
+// @Summary      Seal incident info
+// @Description  seal incident info
+// @Tags         incident
+// @Accept       json
+// @Param        id       path     int     true  "id"	minimum(0)
+// @Success      200	{object}	incident.Incident
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /incident/{id} [get]
+// @Security 	 BearerAuth
+func (h *Handler) incident(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.Incident.GetById(id); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ListIncident godoc
+// @Summary      List seal incidents
+// @Description  get seal incidents
+// @Tags         incident
+// @Accept       json
+// @Param        find    	  query     string  false  "search string (seal serial)"
+// @Param        find_type    query     int     false  "search type (0 - '=', 1 - 'like', 2 = 'ilike')"	Enums(0, 1, 2)
+// @Param        limit        query     int     false  "limit"	minimum(0)	maximum (100)
+// @Param        offset       query     int     false  "offset"	minimum(0)	maximum (32767)
+// @Param        shipping     query     []int   false  "shipping"	collectionFormat(multi)
+// @Param        seal         query     []int   false  "seal"	collectionFormat(multi)
+// @Param        type         query     []int   false  "type (0 - cable cut, 1 - case opened, 2 - sensor error)"	collectionFormat(multi)	enums(0,1,2)
+// @Param        state        query     []int   false  "state (0 - new, 1 - acknowledged, 2 - resolved)"	collectionFormat(multi)	enums(0,1,2)
+// @Success      200	{object}	incidentList
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /incident [get]
+// @Security 	 BearerAuth
+func (h *Handler) incidentList(c *gin.Context) {
+	var queryParams incident.QueryParams
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if list, err := h.Usecase.Incident.List(queryParams); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+// AcknowledgeIncident godoc
+// @Summary      Acknowledge seal incident
+// @Description  acknowledge seal incident
+// @Tags         incident
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int     true  "id"	minimum(0)
+// @Param		 data	body	incident.UpdateStateRequest	false	"data"
+// @Success      200	{object}	incident.Incident
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /incident/{id}/acknowledge [put]
+// @Security 	 BearerAuth
+func (h *Handler) incidentAcknowledge(c *gin.Context) {
+	h.incidentUpdateState(c, h.Usecase.Incident.Acknowledge)
+}
+
+// ResolveIncident godoc
+// @Summary      Resolve seal incident
+// @Description  resolve seal incident
+// @Tags         incident
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int     true  "id"	minimum(0)
+// @Param		 data	body	incident.UpdateStateRequest	false	"data"
+// @Success      200	{object}	incident.Incident
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /incident/{id}/resolve [put]
+// @Security 	 BearerAuth
+func (h *Handler) incidentResolve(c *gin.Context) {
+	h.incidentUpdateState(c, h.Usecase.Incident.Resolve)
+}
+
+func (h *Handler) incidentUpdateState(c *gin.Context, update func(int, incident.UpdateStateRequest, int) (incident.Incident, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	var fromRequest incident.UpdateStateRequest
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBind(&fromRequest); err != nil {
+			h.Logger.Debug(err.Error())
+			c.Error(app_error.BadRequestError(err))
+			return
+		}
+	}
+
+	if errs := h.Validator.Struct(fromRequest); errs != nil {
+		h.Logger.Debug("Ошибки валидации", errs)
+		c.Error(app_error.ValidationError(errs))
+		return
+	}
+
+	userId := c.GetInt("userId")
+	if userId == 0 {
+		c.Error(app_error.InternalServerError(errors.New("can't get user")))
+		return
+	}
+
+	if data, err := update(id, fromRequest, userId); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
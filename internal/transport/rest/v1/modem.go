@@ -2,10 +2,15 @@ package v1
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"math"
 	"net/http"
+	"seal/internal/domain/battery"
+	"seal/internal/domain/command"
 	"seal/internal/domain/modem"
+	"seal/internal/domain/odometer"
+	"seal/internal/domain/stop"
 	"seal/internal/transport"
 	"seal/pkg/app_error"
 	"strconv"
@@ -42,11 +47,22 @@ func (h *Handler) registerModemHandler(api *gin.RouterGroup) {
 		group.PUT(":id", h.modemUpdate)
 		group.GET("", h.modemList)
 		group.GET("shipping-ready", h.modemListShippingReady)
+		group.GET("commands/catalog", h.modemCommandCatalog)
+		group.POST("commands/bulk", h.modemBulkCommand)
+		group.GET("commands/bulk/:job_id", h.modemBulkCommandJob)
 		group.POST(":id/command", h.modemSendCommand)
 		group.GET(":id/commands", h.modemListCommands)
+		group.POST(":id/schedules", h.modemCreateSchedule)
+		group.GET(":id/schedules", h.modemListSchedules)
+		group.DELETE(":id/schedules/:schedule_id", h.modemDeleteSchedule)
 		group.GET(":id/archive", h.modemArchive)
 		group.GET(":id/log-raw-telemetry", h.modemLogRawTelemetry)
 		group.GET(":id/track", h.modemTrack)
+		group.GET(":id/export/:format", h.modemExportTrack)
+		group.GET(":id/stops", h.modemStops)
+		group.GET(":id/odometer", h.modemOdometer)
+		group.GET(":id/availability", h.modemAvailability)
+		group.GET(":id/charges", h.modemCharges)
 		group.GET(":id/log", h.modemLog)
 	}
 }
@@ -77,7 +93,7 @@ func (h *Handler) modem(c *gin.Context) {
 		if data, err := h.Usecase.Modem.GetByImei(id); err != nil {
 			c.Error(err)
 		} else {
-			c.JSON(http.StatusOK, data)
+			c.JSON(http.StatusOK, h.Usecase.Modem.WithBattery(data))
 		}
 
 		return
@@ -86,7 +102,7 @@ func (h *Handler) modem(c *gin.Context) {
 	if data, err := h.Usecase.Modem.GetById(int(id)); err != nil {
 		c.Error(err)
 	} else {
-		c.JSON(http.StatusOK, data)
+		c.JSON(http.StatusOK, h.Usecase.Modem.WithBattery(data))
 	}
 
 }
@@ -100,6 +116,7 @@ func (h *Handler) modem(c *gin.Context) {
 // @Param        find_type    query     int     false  "search type (0 - '=', 1 - 'like', 2 = 'ilike')"	Enums(0, 1, 2)
 // @Param        limit        query     int     false  "limit"	minimum(0)	maximum (100)
 // @Param        offset       query     int     false  "offset"	minimum(0)	maximum (32767)
+// @Param        connectivity query     []string false "connectivity state"	Enums(online, late, offline, unknown)	collectionFormat(multi)
 // @Success      200	{object}	listModem
 // @Failure      400	{object}	app_error.AppError
 // @Failure      401	{object}	app_error.AppError
@@ -108,7 +125,7 @@ func (h *Handler) modem(c *gin.Context) {
 // @Router       /modem [get]
 // @Security 	 BearerAuth
 func (h *Handler) modemList(c *gin.Context) {
-	var queryParams transport.QueryParams
+	var queryParams modem.ListQueryParams
 	if err := c.ShouldBind(&queryParams); err != nil {
 		h.Logger.Debug(err.Error())
 		c.Error(app_error.BadRequestError(err))
@@ -195,7 +212,7 @@ func (h *Handler) modemSendCommand(c *gin.Context) {
 		return
 	}
 
-	if data, err := h.Usecase.Modem.SendCommand(id, fromRequest, user.Login); err != nil {
+	if data, err := h.Usecase.Modem.SendCommand(id, fromRequest, user.Login, user.Role); err != nil {
 		c.Error(err)
 	} else {
 		c.JSON(http.StatusOK, transport.SuccessResponse{Success: data})
@@ -208,13 +225,16 @@ func (h *Handler) modemSendCommand(c *gin.Context) {
 // @Tags         modem
 // @Accept       json
 // @Param        id       path     int     false  "id"	minimum(0)	maximum (32767)
-// @Param        find    	  query     string  false  "search string"
-// @Param        find_type    query     int     false  "search type (0 - '=', 1 - 'like', 2 = 'ilike')"	Enums(0, 1, 2)
-// @Param        limit        query     int     false  "limit"	minimum(0)	maximum (100)
+// @Param        limit        query     int     false  "limit, default 10"	minimum(0)	maximum (100)
 // @Param        offset       query     int     false  "offset"	minimum(0)	maximum (32767)
-// @Success      200	{object}	listModem
+// @Param        new_only     query     bool    false  "new_only"
+// @Param        from		  query	    string	false "from"
+// @Param        to	    	  query	    string	false "to"
+// @Param        status       query     []string false "status"	Enums(pending, delivered, failed, aborted)	collectionFormat(multi)
+// @Success      200	{object}	listCommand
 // @Failure      400	{object}	app_error.AppError
 // @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
 // @Failure      422	{object}	app_error.AppError
 // @Failure      500	{object}	app_error.AppError
 // @Router       /modem/{id}/commands [get]
@@ -228,7 +248,14 @@ func (h *Handler) modemListCommands(c *gin.Context) {
 		return
 	}
 
-	if list, err := h.Usecase.Modem.CommandsList(id); err != nil {
+	var queryParams command.HistoryQueryParams
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if list, err := h.Usecase.Modem.CommandsList(id, queryParams); err != nil {
 		c.Error(err)
 	} else {
 		c.JSON(http.StatusOK, list)
@@ -325,6 +352,8 @@ func (h *Handler) modemLogRawTelemetry(c *gin.Context) {
 // @Param        to	    	 query	 string	false "to"
 // @Param        limit		 query   int   	false "limit"
 // @Param        order_desc  query	 bool	false "order_desc"
+// @Param        tolerance   query   int     false "simplification tolerance, meters"	maximum (100000)
+// @Param        zoom        query   int     false "map zoom level, simplification tolerance is one pixel"	minimum(0)	maximum (22)
 // @Success      200	{object}	modem.TrackResponse
 // @Failure      400	{object}	app_error.AppError
 // @Failure      401	{object}	app_error.AppError
@@ -355,6 +384,221 @@ func (h *Handler) modemTrack(c *gin.Context) {
 	}
 }
 
+// ModemStops godoc
+// @Summary      Modem stops
+// @Description  places where the vehicle stood still: centroid (hidden in secret areas), start, end, duration in seconds, customs zone
+// @Tags         modem
+// @Accept       json
+// @Param        id			  path    int    true  "id"		minimum(0)		maximum (32767)
+// @Param        from		  query	  string true  "from"
+// @Param        to	    	  query	  string false "to"
+// @Param        min_duration query   int    false "minimal stop duration, minutes (default 5)"	maximum (1440)
+// @Param        radius       query   int    false "stop radius, meters (default 100)"	maximum (5000)
+// @Success      200	{object}	[]stop.Stop
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/{id}/stops [get]
+// @Security 	 BearerAuth
+func (h *Handler) modemStops(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	queryParams := stop.QueryParams{Id: id}
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if data, err := h.Usecase.Stop.ByModem(queryParams); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ModemOdometer godoc
+// @Summary      Modem distance traveled
+// @Description  distance (km) and moving time (seconds) by coordinates with daily breakdown, GPS jumps are filtered by hdop, satellites count and speed
+// @Tags         modem
+// @Accept       json
+// @Param        id			 path    int    true  "id"		minimum(0)		maximum (32767)
+// @Param        from		 query	 string	true  "from"
+// @Param        to	    	 query	 string	false "to"
+// @Success      200	{object}	odometer.Odometer
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/{id}/odometer [get]
+// @Security 	 BearerAuth
+func (h *Handler) modemOdometer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	queryParams := odometer.QueryParams{Id: id}
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if data, err := h.Usecase.Odometer.ByModem(queryParams); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ModemAvailability godoc
+// @Summary      Modem connectivity availability
+// @Description  percentage of sessions expected by connect_period that actually arrived, with gaps
+// @Tags         modem
+// @Accept       json
+// @Param        id			 path    int    true  "id"		minimum(0)		maximum (32767)
+// @Param        from		 query	 string	true  "from"
+// @Param        to	    	 query	 string	false "to"
+// @Success      200	{object}	modem.Availability
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/{id}/availability [get]
+// @Security 	 BearerAuth
+func (h *Handler) modemAvailability(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	queryParams := modem.AvailabilityQueryParams{Id: id}
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if data, err := h.Usecase.Modem.Availability(queryParams); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ModemCharges godoc
+// @Summary      Modem charge history
+// @Description  charging sessions recorded from battery level growth and shipments started without charging after the previous one, default period is 90 days, max 366 days
+// @Tags         modem
+// @Accept       json
+// @Param        id			 path    int    true  "id"		minimum(0)		maximum (32767)
+// @Param        from		 query	 string	false "from"
+// @Param        to	    	 query	 string	false "to"
+// @Success      200	{object}	battery.Charges
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/{id}/charges [get]
+// @Security 	 BearerAuth
+func (h *Handler) modemCharges(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	queryParams := battery.ChargesQueryParams{Id: id}
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if data, err := h.Usecase.Battery.ModemCharges(queryParams); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ExportTrackModem godoc
+// @Summary      Modem track export
+// @Description  modem coordinates track as GPX 1.1, KML or GeoJSON file. Secret areas split the track into segments
+// @Tags         modem
+// @Produce      application/gpx+xml,application/vnd.google-earth.kml+xml,application/geo+json
+// @Param        id			 path    int    true  "id"		minimum(0)		maximum (32767)
+// @Param        format      path    string true  "format"	Enums(gpx, kml, geojson)
+// @Param        from		 query	 string	true  "from"
+// @Param        to	    	 query	 string	false "to"
+// @Param        limit		 query   int   	false "limit"
+// @Param        tolerance   query   int     false "simplification tolerance, meters"	maximum (100000)
+// @Param        zoom        query   int     false "map zoom level, simplification tolerance is one pixel"	minimum(0)	maximum (22)
+// @Success      200	{file}	file
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/{id}/export/{format} [get]
+// @Security 	 BearerAuth
+func (h *Handler) modemExportTrack(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	format, err := trackExportFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	queryParams := modem.TrackQueryParams{Id: id}
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	item, err := h.Usecase.Modem.GetDbById(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	track, err := h.Usecase.Modem.ExportTrack(queryParams, fmt.Sprintf("%d", item.Imei))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.writeTrack(c, format, fmt.Sprintf("modem_%d", item.Imei), track)
+}
+
 // LogModem godoc
 // @Summary      Log modem
 // @Description  get modem packages log
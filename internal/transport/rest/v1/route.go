@@ -23,6 +23,8 @@ func (h *Handler) registerRouteHandler(api *gin.RouterGroup) {
 		group.GET(":id", h.route)
 		group.PUT(":id", h.routeUpdate)
 		group.DELETE(":id", h.routeDelete)
+		group.GET(":id/points", h.routePoints)
+		group.PUT(":id/points", h.routePointsUpdate)
 		group.GET("", h.routeList)
 		group.POST("", h.routeCreate)
 	}
@@ -198,3 +200,72 @@ func (h *Handler) routeDelete(c *gin.Context) {
 		c.Error(app_error.ErrNotFound)
 	}
 }
+
+// RoutePoints godoc
+// @Summary      Route points
+// @Description  route coords and customs of departure and destination (0 - not set)
+// @Tags         route
+// @Accept       json
+// @Param        id       path     int     false  "id"	minimum(0)	maximum (32767)
+// @Success      200	{object}	route.Points
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /route/{id}/points [get]
+// @Security 	 BearerAuth
+func (h *Handler) routePoints(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.Route.GetPoints(id); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// UpdateRoutePoints godoc
+// @Summary      Update route points
+// @Description  set customs of departure and destination (0 - not set), coords are replaced if not empty
+// @Tags         route
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int     false  "id"	minimum(0)	maximum (32767)
+// @Param		 data	body	route.Points	true	"data"
+// @Success      200	{object}	route.Points
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /route/{id}/points [put]
+// @Security 	 BearerAuth
+func (h *Handler) routePointsUpdate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	var fromRequest route.Points
+
+	if err := c.ShouldBind(&fromRequest); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if data, err := h.Usecase.Route.UpdatePoints(id, fromRequest); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
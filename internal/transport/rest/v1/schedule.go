@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"seal/internal/domain/schedule"
+	"seal/internal/transport"
+	"seal/pkg/app_error"
+	"strconv"
+)
+
+// CreateScheduleModem godoc
+// @Summary      Schedule command to modem
+// @Description  send command once at run_at, by cron expression (5 fields, time zone as CRON_TZ= prefix) or on start/end of modem shipping. Catalog params, model and user role are checked now and before each sending
+// @Tags         modem
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int     true  "id"	minimum(1)
+// @Param		 data	body	schedule.CreateRequest	true	"data"
+// @Success      201	{object}	schedule.Schedule
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/{id}/schedules [post]
+// @Security 	 BearerAuth
+func (h *Handler) modemCreateSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	var fromRequest schedule.CreateRequest
+	if err := c.ShouldBind(&fromRequest); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	userId := c.GetInt("userId")
+	if userId == 0 {
+		c.Error(app_error.InternalServerError(errors.New("can't get user")))
+		return
+	}
+	user, uerr := h.Usecase.User.GetById(userId)
+	if uerr != nil {
+		c.Error(app_error.InternalServerError(errors.New("can't get user by id")))
+		return
+	}
+
+	if data, err := h.Usecase.Schedule.Create(id, fromRequest, userId, user.Role); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusCreated, data)
+	}
+}
+
+// ListScheduleModem godoc
+// @Summary      Modem scheduled commands
+// @Description  scheduled commands of modem with last runs (status 1 - sent, 2 - failed). Inactive are one-shot commands already sent
+// @Tags         modem
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int     true  "id"	minimum(1)
+// @Success      200	{array}	schedule.Schedule
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/{id}/schedules [get]
+// @Security 	 BearerAuth
+func (h *Handler) modemListSchedules(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.Schedule.ListByModem(id); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// DeleteScheduleModem godoc
+// @Summary      Delete modem scheduled command
+// @Description  delete modem scheduled command with its runs
+// @Tags         modem
+// @Accept       json
+// @Param        id       path     int     true  "id"	minimum(1)
+// @Param        schedule_id       path     int     true  "schedule id"	minimum(1)
+// @Success      200	{object}	transport.DeleteResponse
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /modem/{id}/schedules/{schedule_id} [delete]
+// @Security 	 BearerAuth
+func (h *Handler) modemDeleteSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	scheduleId, err := strconv.Atoi(c.Param("schedule_id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if err := h.Usecase.Schedule.Delete(id, scheduleId); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, transport.DeleteResponse{Success: true})
+	}
+}
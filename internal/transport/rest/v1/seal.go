@@ -51,7 +51,7 @@ func (h *Handler) seal(c *gin.Context) {
 	if data, err := h.Usecase.Seal.GetById(id); err != nil {
 		c.Error(err)
 	} else {
-		c.JSON(http.StatusOK, data)
+		c.JSON(http.StatusOK, h.Usecase.Seal.WithBattery(data))
 	}
 }
 
@@ -1,21 +1,24 @@
 package v1
 
 import (
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"math"
+	"mime"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"path/filepath"
 	"seal/internal/domain/shipping"
+	"seal/internal/domain/stop"
 	"seal/internal/transport"
 	"seal/pkg/app_error"
+	"seal/pkg/spreadsheet"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,18 +37,36 @@ func (h *Handler) registerShippingHandler(api *gin.RouterGroup) {
 		group.GET("modem/:imei", h.shippingByModemImei)
 		group.GET(":id/route", h.shippingRoute)
 		group.POST(":id/files/:type", h.shippingUploadFiles)
+		group.GET(":id/files.zip", h.shippingDownloadBundle)
 		group.GET(":id/files/:name", h.shippingDownloadFile)
 		group.DELETE(":id/files/:name", h.shippingDeleteFile)
 		group.PUT(":id/files/:name", h.shippingUpdateFile)
+		group.POST(":id/upload-tokens", h.shippingUploadTokenCreate)
+		group.GET(":id/upload-tokens", h.shippingUploadTokens)
+		group.DELETE(":id/upload-tokens/:token_id", h.shippingUploadTokenRevoke)
 		group.PUT(":id/modem/:imei", h.shippingModemSet)
+		group.PUT(":id/seals/:seal_id", h.shippingSealAttach)
+		group.DELETE(":id/seals/:seal_id", h.shippingSealDetach)
 		group.PUT(":id", h.shippingUpdate)
 		group.PUT(":id/start", h.shippingStart)
 		group.GET(":id/coordinates", h.shippingCoordinates)
 		group.GET(":id/telemetry", h.shippingTelemetry)
+		group.GET(":id/export/:format", h.shippingExportTrack)
+		group.GET(":id/deviations", h.shippingDeviations)
+		group.GET(":id/custom-events", h.shippingCustomEvents)
+		group.GET(":id/stops", h.shippingStops)
+		group.GET(":id/odometer", h.shippingOdometer)
+		group.PUT(":id/suspend", h.shippingSuspend)
+		group.PUT(":id/resume", h.shippingResume)
 		group.PUT(":id/end", h.shippingEnd)
+		group.PUT(":id/cancel", h.shippingCancel)
+		group.GET(":id/history", h.shippingHistory)
+		group.GET(":id/pauses", h.shippingPauses)
+		group.GET(":id/report", h.shippingReport)
 		group.DELETE(":id", h.shippingDelete)
 		group.GET("", h.shippingList)
 		group.POST("", h.shippingCreate)
+		group.POST("import", h.shippingImport)
 	}
 }
 
@@ -116,7 +137,7 @@ func (h *Handler) shippingRoute(c *gin.Context) {
 // @Param        find_type    query     int     false  "search type (0 - '=', 1 - 'like', 2 = 'ilike')"	Enums(0, 1, 2)
 // @Param        limit        query     int     false  "limit"	minimum(0)	maximum (100)
 // @Param        offset       query     int     false  "offset"	minimum(0)	maximum (32767)
-// @Param        status       query     []string  false  "status"	collectionFormat(multi)	enums(0,1,2)
+// @Param        status       query     []string  false  "status"	collectionFormat(multi)	enums(0,1,2,3,4)
 // @Success      200	{object}	shippingList
 // @Failure      400	{object}	app_error.AppError
 // @Failure      401	{object}	app_error.AppError
@@ -232,6 +253,7 @@ func (h *Handler) shippingUpdate(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        id       path     int     false  "id"	minimum(0)	maximum (32767)
+// @Param		 data	body	shipping.TransitionRequest	false	"data"
 // @Success      200	{object}	shipping.Shipping
 // @Failure      400	{object}	app_error.AppError
 // @Failure      401	{object}	app_error.AppError
@@ -240,6 +262,86 @@ func (h *Handler) shippingUpdate(c *gin.Context) {
 // @Router       /shipping/{id}/start [put]
 // @Security 	 BearerAuth
 func (h *Handler) shippingStart(c *gin.Context) {
+	h.shippingTransit(c, h.Usecase.Shipping.Start)
+}
+
+// SuspendShipping godoc
+// @Summary      Suspend shipping
+// @Description  Suspend active shipping (e.g. customs hold)
+// @Tags         shipping
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int     false  "id"	minimum(0)	maximum (32767)
+// @Param		 data	body	shipping.TransitionRequest	false	"data"
+// @Success      200	{object}	shipping.Shipping
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/suspend [put]
+// @Security 	 BearerAuth
+func (h *Handler) shippingSuspend(c *gin.Context) {
+	h.shippingTransit(c, h.Usecase.Shipping.Suspend)
+}
+
+// ResumeShipping godoc
+// @Summary      Resume shipping
+// @Description  Resume suspended shipping
+// @Tags         shipping
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int     false  "id"	minimum(0)	maximum (32767)
+// @Param		 data	body	shipping.TransitionRequest	false	"data"
+// @Success      200	{object}	shipping.Shipping
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/resume [put]
+// @Security 	 BearerAuth
+func (h *Handler) shippingResume(c *gin.Context) {
+	h.shippingTransit(c, h.Usecase.Shipping.Resume)
+}
+
+// EndShipping godoc
+// @Summary      End shipping
+// @Description  End shipping
+// @Tags         shipping
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int     false  "id"	minimum(0)	maximum (32767)
+// @Param		 data	body	shipping.TransitionRequest	false	"data"
+// @Success      200	{object}	shipping.Shipping
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/end [put]
+// @Security 	 BearerAuth
+func (h *Handler) shippingEnd(c *gin.Context) {
+	h.shippingTransit(c, h.Usecase.Shipping.End)
+}
+
+// CancelShipping godoc
+// @Summary      Cancel shipping
+// @Description  Cancel shipping
+// @Tags         shipping
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int     false  "id"	minimum(0)	maximum (32767)
+// @Param		 data	body	shipping.TransitionRequest	false	"data"
+// @Success      200	{object}	shipping.Shipping
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/cancel [put]
+// @Security 	 BearerAuth
+func (h *Handler) shippingCancel(c *gin.Context) {
+	h.shippingTransit(c, h.Usecase.Shipping.Cancel)
+}
+
+func (h *Handler) shippingTransit(c *gin.Context, transit func(shipping.Db, int, string) (shipping.Shipping, error)) {
 	id, err := strconv.Atoi(c.Param("id"))
 
 	if err != nil {
@@ -248,35 +350,56 @@ func (h *Handler) shippingStart(c *gin.Context) {
 		return
 	}
 
-	var shipping shipping.Db
+	var fromRequest shipping.TransitionRequest
 
-	if shipping, err = h.Usecase.Shipping.GetDbById(id); err != nil {
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBind(&fromRequest); err != nil {
+			h.Logger.Debug(err.Error())
+			c.Error(app_error.BadRequestError(err))
+			return
+		}
+	}
+
+	if errs := h.Validator.Struct(fromRequest); errs != nil {
+		h.Logger.Debug("Ошибки валидации", errs)
+		c.Error(app_error.ValidationError(errs))
+		return
+	}
+
+	userId := c.GetInt("userId")
+	if userId == 0 {
+		c.Error(app_error.InternalServerError(errors.New("can't get user")))
+		return
+	}
+
+	var model shipping.Db
+
+	if model, err = h.Usecase.Shipping.GetDbById(id); err != nil {
 		c.Error(app_error.ErrNotFound)
 		return
 	}
 
-	if data, err := h.Usecase.Shipping.Start(shipping); err != nil {
+	if data, err := transit(model, userId, fromRequest.Reason); err != nil {
 		c.Error(err)
 	} else {
 		c.JSON(http.StatusOK, data)
 	}
 }
 
-// StartShipping godoc
-// @Summary      End shipping
-// @Description  End shipping
+// ShippingHistory godoc
+// @Summary      Shipping status history
+// @Description  shipping status transitions with author and reason
 // @Tags         shipping
 // @Accept       json
-// @Produce      json
-// @Param        id       path     int     false  "id"	minimum(0)	maximum (32767)
-// @Success      200	{object}	shipping.Shipping
+// @Param        id          path    int     true  "id"		minimum(0)	maximum (32767)
+// @Success      200	{object}	[]shipping.History
 // @Failure      400	{object}	app_error.AppError
 // @Failure      401	{object}	app_error.AppError
-// @Failure      422	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
 // @Failure      500	{object}	app_error.AppError
-// @Router       /shipping/{id}/end [put]
+// @Router       /shipping/{id}/history [get]
 // @Security 	 BearerAuth
-func (h *Handler) shippingEnd(c *gin.Context) {
+func (h *Handler) shippingHistory(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 
 	if err != nil {
@@ -285,14 +408,36 @@ func (h *Handler) shippingEnd(c *gin.Context) {
 		return
 	}
 
-	var shipping shipping.Db
+	if data, err := h.Usecase.Shipping.History(id); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ShippingPauses godoc
+// @Summary      Shipping pauses
+// @Description  suspend intervals derived from status history, duration in seconds
+// @Tags         shipping
+// @Accept       json
+// @Param        id          path    int     true  "id"		minimum(0)	maximum (32767)
+// @Success      200	{object}	[]shipping.Pause
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/pauses [get]
+// @Security 	 BearerAuth
+func (h *Handler) shippingPauses(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
 
-	if shipping, err = h.Usecase.Shipping.GetDbById(id); err != nil {
-		c.Error(app_error.ErrNotFound)
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
 		return
 	}
 
-	if data, err := h.Usecase.Shipping.End(shipping); err != nil {
+	if data, err := h.Usecase.Shipping.Pauses(id); err != nil {
 		c.Error(err)
 	} else {
 		c.JSON(http.StatusOK, data)
@@ -330,6 +475,75 @@ func (h *Handler) shippingDelete(c *gin.Context) {
 	}
 }
 
+// ImportShipping godoc
+// @Summary      Import shipping
+// @Description  bulk shipping import from csv or xlsx, shipping is created only if all rows are valid, columns: custom_number, create_date, number, transport (registration number or title), route (title)
+// @Tags         shipping
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        dry_run  formData bool    false "validate only"
+// @Param        file     formData file    true  "csv or xlsx file"
+// @Success      200	{object}	shipping.ImportResult
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/import [post]
+// @Security 	 BearerAuth
+func (h *Handler) shippingImport(c *gin.Context) {
+	var fromRequest shipping.ImportRequest
+	if err := c.ShouldBind(&fromRequest); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	if !spreadsheet.Supported(format) {
+		c.Error(app_error.ValidationError(map[string]string{"file": "Поддерживаются файлы csv и xlsx"}))
+		return
+	}
+
+	userId := c.GetInt("userId")
+	if userId == 0 {
+		c.Error(app_error.InternalServerError(errors.New("can't get user")))
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.Error(app_error.InternalServerError(err))
+		return
+	}
+	defer src.Close()
+
+	table, err := spreadsheet.Read(src, format)
+	if err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.ValidationError(map[string]string{"file": "Не удалось прочитать файл"}))
+		return
+	}
+
+	rows, err := shipping.ParseImportRows(table)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.Shipping.Import(rows, userId, fromRequest.DryRun); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
 // UploadShippingFiles godoc
 // @Summary      Upload shipping files
 // @Description  upload shipping files
@@ -348,113 +562,153 @@ func (h *Handler) shippingDelete(c *gin.Context) {
 // @Router       /shipping/{id}/files/{type} [post]
 // @Security 	 BearerAuth
 func (h *Handler) shippingUploadFiles(c *gin.Context) {
-	form, err := c.MultipartForm()
-
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		h.Logger.Error(err.Error())
 		c.Error(err)
 		return
 	}
 
-	files := form.File["file"]
-
-	id, err := strconv.Atoi(c.Param("id"))
+	fileType, err := strconv.Atoi(c.Param("type"))
 	if err != nil {
 		h.Logger.Error(err.Error())
 		c.Error(err)
 		return
 	}
 
-	fileType, err := strconv.Atoi(c.Param("type"))
+	if data, _, ok := h.uploadShippingFiles(c, id, fileType, 0); ok {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// uploadShippingFiles проверяет и сохраняет файлы из формы, ошибки пишутся в контекст.
+// uploadToken - ключ загрузки, по которому загружены файлы, 0 - загрузка пользователем
+func (h *Handler) uploadShippingFiles(c *gin.Context, id, fileType, uploadToken int) (shipping.Shipping, []shipping.File, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, shipping.FILE_MAX_REQUEST_SIZE)
+
+	form, err := c.MultipartForm()
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.Error(app_error.ValidationError(map[string]string{
+			"file": fmt.Sprintf("Размер запроса больше %d МБ", shipping.FILE_MAX_REQUEST_SIZE>>20),
+		}))
+		return shipping.Shipping{}, nil, false
+	}
+
 	if err != nil {
 		h.Logger.Error(err.Error())
 		c.Error(err)
-		return
+		return shipping.Shipping{}, nil, false
 	}
 
+	files := form.File["file"]
+
 	var fromRequest shipping.AddFileRequest
 
 	if err := c.ShouldBind(&fromRequest); err != nil {
 		h.Logger.Debug(err.Error())
 		c.Error(app_error.BadRequestError(err))
-		return
+		return shipping.Shipping{}, nil, false
 	}
 
-	filesDirectory := h.Usecase.Shipping.GetFilesDirectory(id)
+	uploads := make([]shipping.Upload, len(files))
+	for i, file := range files {
+		if uploads[i], err = readUpload(file); err != nil {
+			h.Logger.Error(fmt.Sprintf("Error read upload: %v", err))
+			c.Error(err)
+			return shipping.Shipping{}, nil, false
+		}
+	}
+
+	if err := h.Usecase.Shipping.CheckFileSeal(id, fromRequest.SealId); err != nil {
+		c.Error(err)
+		return shipping.Shipping{}, nil, false
+	}
+
+	if err := h.Usecase.Shipping.ValidateUploads(id, fileType, uploads); err != nil {
+		c.Error(err)
+		return shipping.Shipping{}, nil, false
+	}
 
 	var shippingFiles []shipping.File
-	for _, file := range files {
-		fileName, filePath, err := getFileName(file.Filename, filesDirectory, 0)
+	for i, file := range files {
+		fileName, err := h.Usecase.Shipping.NewFileName(id, file.Filename)
 		if err != nil {
+			h.Usecase.Shipping.RemoveFilesFromStorage(id, shippingFiles)
 			c.Error(err)
-			return
+			return shipping.Shipping{}, nil, false
 		}
 
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			h.Usecase.Shipping.RemoveFilesFromDisk(id, shippingFiles)
+		if err := h.saveUploadedFile(id, fileName, file, shipping.DetectMime(uploads[i].Head)); err != nil {
+			h.Usecase.Shipping.RemoveFilesFromStorage(id, shippingFiles)
 			c.Error(err)
-			return
-		} else {
-			checksum, err := crc(file)
-			if err != nil {
-				h.Logger.Error(fmt.Sprintf("Error calculate crc: %v", err))
-			}
-
-			shippingFiles = append(shippingFiles, shipping.File{
-				Title:    file.Filename,
-				Name:     fileName,
-				Type:     fileType,
-				SealId:   fromRequest.SealId,
-				Comment:  fromRequest.Comment,
-				Checksum: checksum,
-			})
+			return shipping.Shipping{}, nil, false
 		}
+
+		shippingFiles = append(shippingFiles, shipping.File{
+			Title:       file.Filename,
+			Name:        fileName,
+			Type:        fileType,
+			SealId:      fromRequest.SealId,
+			Comment:     fromRequest.Comment,
+			Checksum:    uploads[i].Checksum,
+			UploadToken: uploadToken,
+		})
 	}
 
-	if data, err := h.Usecase.Shipping.AddFiles(id, shippingFiles); err != nil {
-		h.Usecase.Shipping.RemoveFilesFromDisk(id, shippingFiles)
+	data, err := h.Usecase.Shipping.AddFiles(id, shippingFiles)
+	if err != nil {
+		h.Usecase.Shipping.RemoveFilesFromStorage(id, shippingFiles)
 		c.Error(err)
-	} else {
-		c.JSON(http.StatusOK, data)
+		return shipping.Shipping{}, nil, false
 	}
+
+	return data, shippingFiles, true
 }
 
-func getFileName(fileName, filesDirectory string, i int) (string, string, error) {
-	now := time.Now().UnixNano()
-	ext := filepath.Ext(fileName)
-	name := fmt.Sprintf("%d%s", now, ext)
+func (h *Handler) saveUploadedFile(id int, name string, file *multipart.FileHeader, contentType string) error {
+	f, err := file.Open()
 
-	if i > 0 {
-		name = fmt.Sprintf("%d_%d%s", now, i, ext)
+	if err != nil {
+		return err
 	}
 
-	filePath := fmt.Sprintf("%s/%s", filesDirectory, name)
-
-	if _, err := os.Stat(filePath); errors.Is(err, os.ErrNotExist) {
-		return name, filePath, nil
-	} else if i > 500 {
-		return "", "", fmt.Errorf("file exists %s", filePath)
-	}
+	defer f.Close()
 
-	return getFileName(fileName, filesDirectory, i+1)
+	return h.Usecase.Shipping.UploadFile(id, name, f, file.Size, contentType)
 }
 
-func crc(file *multipart.FileHeader) (string, error) {
+// readUpload начало файла для определения типа и md5 всего содержимого
+func readUpload(file *multipart.FileHeader) (shipping.Upload, error) {
 	f, err := file.Open()
 
 	if err != nil {
-		return "", err
+		return shipping.Upload{}, err
 	}
 
 	defer f.Close()
 
+	head := make([]byte, shipping.FILE_SNIFF_LEN)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return shipping.Upload{}, err
+	}
+	head = head[:n]
+
 	crc := md5.New()
+	crc.Write(head)
 
 	if _, err := io.Copy(crc, f); err != nil {
-		return "", err
+		return shipping.Upload{}, err
 	}
 
-	return hex.EncodeToString(crc.Sum(nil)), nil
+	return shipping.Upload{
+		Title:    file.Filename,
+		Size:     file.Size,
+		Head:     head,
+		Checksum: hex.EncodeToString(crc.Sum(nil)),
+	}, nil
 }
 
 // ShippingDownloadFile godoc
@@ -492,9 +746,72 @@ func (h *Handler) shippingDownloadFile(c *gin.Context) {
 		return
 	}
 
-	filesDirectory := h.Usecase.Shipping.GetFilesDirectory(id)
-	filePath := fmt.Sprintf("%s/%s", filesDirectory, fileName)
-	c.FileAttachment(filePath, fileInfo.Title)
+	reader, size, err := h.Usecase.Shipping.OpenFile(id, fileName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	defer reader.Close()
+
+	contentType := mime.TypeByExtension(filepath.Ext(fileName))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	c.DataFromReader(http.StatusOK, size, contentType, reader, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": fileInfo.Title}),
+	})
+}
+
+// ShippingDownloadBundle godoc
+// @Summary      Download shipping files as zip
+// @Description  zip of shipping files with manifest.json (titles, comments, seal ids, checksums), streamed
+// @Tags         shipping
+// @Accept       json
+// @Produce      application/zip
+// @Param        id       path     int     true  "id"		minimum(0)	maximum (32767)
+// @Param        type     query    int     false "file type"
+// @Param        seal_id  query    int     false "seal id"
+// @Success      200	{file} 		binary
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/files.zip [get]
+// @Security 	 BearerAuth
+func (h *Handler) shippingDownloadBundle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	var fromRequest shipping.BundleQueryParams
+
+	if err := c.ShouldBind(&fromRequest); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	files, err := h.Usecase.Shipping.BundleFiles(id, fromRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fmt.Sprintf("shipping_%d.zip", id),
+	}))
+	c.Status(http.StatusOK)
+
+	if err := h.Usecase.Shipping.WriteBundle(c.Writer, id, files); err != nil {
+		h.Logger.Error(err.Error())
+	}
 }
 
 // ShippingDeleteFile godoc
@@ -632,6 +949,86 @@ func (h *Handler) shippingModemSet(c *gin.Context) {
 	}
 }
 
+// ShippingSealAttach godoc
+// @Summary      Attach seal to shipping
+// @Description  attach seal of the shipping modem to the new shipping
+// @Tags         shipping
+// @Accept       json
+// @Param        id       path     int     true  "id"		minimum(0)	maximum (32767)
+// @Param        seal_id  path     int     true  "seal id"	minimum(0)
+// @Success      200	{object}	shipping.Shipping
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/seals/{seal_id} [put]
+// @Security 	 BearerAuth
+func (h *Handler) shippingSealAttach(c *gin.Context) {
+	id, sealId, err := shippingSealParams(c)
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	userId := c.GetInt("userId")
+	if userId == 0 {
+		c.Error(app_error.InternalServerError(errors.New("can't get user")))
+		return
+	}
+
+	if data, err := h.Usecase.Shipping.AttachSeal(id, sealId, userId); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ShippingSealDetach godoc
+// @Summary      Detach seal from shipping
+// @Description  detach seal from the new shipping
+// @Tags         shipping
+// @Accept       json
+// @Param        id       path     int     true  "id"		minimum(0)	maximum (32767)
+// @Param        seal_id  path     int     true  "seal id"	minimum(0)
+// @Success      200	{object}	shipping.Shipping
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/seals/{seal_id} [delete]
+// @Security 	 BearerAuth
+func (h *Handler) shippingSealDetach(c *gin.Context) {
+	id, sealId, err := shippingSealParams(c)
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.Shipping.DetachSeal(id, sealId); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+func shippingSealParams(c *gin.Context) (int, int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sealId, err := strconv.Atoi(c.Param("seal_id"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return id, sealId, nil
+}
+
 // ShippingCoordinates godoc
 // @Summary      Shipping coordinates
 // @Description  shipping coordinates
@@ -641,6 +1038,8 @@ func (h *Handler) shippingModemSet(c *gin.Context) {
 // @Param        from	     query	 string	 false "from"
 // @Param        limit       query	 int	 false "limit"
 // @Param        order_desc  query	 bool	 false "order_desc"
+// @Param        tolerance   query   int     false "simplification tolerance, meters"	maximum (100000)
+// @Param        zoom        query   int     false "map zoom level, simplification tolerance is one pixel"	minimum(0)	maximum (22)
 // @Success      200	{object}	[]shipping.trackResponseCoordinate
 // @Failure      400	{object}	app_error.AppError
 // @Failure      401	{object}	app_error.AppError
@@ -677,6 +1076,102 @@ func (h *Handler) shippingCoordinates(c *gin.Context) {
 	}
 }
 
+// ShippingExportTrack godoc
+// @Summary      Shipping track export
+// @Description  shipping track with planned route as GPX 1.1, KML or GeoJSON file. Secret areas split the track into segments
+// @Tags         shipping
+// @Produce      application/gpx+xml,application/vnd.google-earth.kml+xml,application/geo+json
+// @Param        id          path    int     true  "id"		minimum(0)	maximum (32767)
+// @Param        format      path    string  true  "format"	Enums(gpx, kml, geojson)
+// @Param        from	     query	 string	 false "from"
+// @Param        limit       query	 int	 false "limit"
+// @Param        tolerance   query   int     false "simplification tolerance, meters"	maximum (100000)
+// @Param        zoom        query   int     false "map zoom level, simplification tolerance is one pixel"	minimum(0)	maximum (22)
+// @Success      200	{file}	file
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/export/{format} [get]
+// @Security 	 BearerAuth
+func (h *Handler) shippingExportTrack(c *gin.Context) {
+	var fromRequest shipping.TrackQueryParams
+	var err error
+
+	if fromRequest.Id, err = strconv.Atoi(c.Param("id")); err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	format, err := trackExportFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := c.ShouldBind(&fromRequest); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if errs := h.Validator.Struct(fromRequest); errs != nil {
+		h.Logger.Debug("Ошибки валидации", errs)
+		c.Error(app_error.ValidationError(errs))
+		return
+	}
+
+	track, err := h.Usecase.Shipping.ExportTrack(fromRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.writeTrack(c, format, fmt.Sprintf("shipping_%d", fromRequest.Id), track)
+}
+
+// ShippingReport godoc
+// @Summary      Shipping trip report
+// @Description  printable HTML trip report for finished shipping: header, timeline, seals, deviations, files and track map
+// @Tags         shipping
+// @Produce      html
+// @Param        id          path    int     true  "id"		minimum(0)	maximum (32767)
+// @Success      200	{string}	string
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/report [get]
+// @Security 	 BearerAuth
+func (h *Handler) shippingReport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	report, err := h.Usecase.Report.Shipping(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// отчёт собирается целиком до ответа, чтобы ошибка шаблона не пришла после статуса 200
+	var buf bytes.Buffer
+	if err := h.Usecase.Report.Write(&buf, report); err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(app_error.InternalServerError(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
 // ShippingTelemetry godoc
 // @Summary      Shipping telemetry
 // @Description  shipping telemetry
@@ -722,6 +1217,135 @@ func (h *Handler) shippingTelemetry(c *gin.Context) {
 	}
 }
 
+// ShippingDeviations godoc
+// @Summary      Shipping route deviations
+// @Description  shipping route deviations (leaving the route corridor)
+// @Tags         shipping
+// @Accept       json
+// @Param        id          path    int     true  "id"		minimum(0)	maximum (32767)
+// @Success      200	{object}	[]deviation.Deviation
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/deviations [get]
+// @Security 	 BearerAuth
+func (h *Handler) shippingDeviations(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.Deviation.ListByShipping(id); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ShippingCustomEvents godoc
+// @Summary      Shipping customs zones events
+// @Description  entering and leaving the departure (zone 0) and destination (zone 1) customs zones; type 0 - enter, 1 - exit
+// @Tags         shipping
+// @Accept       json
+// @Param        id          path    int     true  "id"		minimum(0)	maximum (32767)
+// @Success      200	{object}	[]custom_event.CustomEvent
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/custom-events [get]
+// @Security 	 BearerAuth
+func (h *Handler) shippingCustomEvents(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.CustomEvent.ListByShipping(id); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ShippingStops godoc
+// @Summary      Shipping stops
+// @Description  places where the vehicle stood still during the trip: centroid (hidden in secret areas), start, end, duration in seconds, customs zone
+// @Tags         shipping
+// @Accept       json
+// @Param        id           path    int     true  "id"		minimum(0)	maximum (32767)
+// @Param        from		  query	  string  false "from"
+// @Param        to	    	  query	  string  false "to"
+// @Param        min_duration query   int     false "minimal stop duration, minutes (default 5)"	maximum (1440)
+// @Param        radius       query   int     false "stop radius, meters (default 100)"	maximum (5000)
+// @Success      200	{object}	[]stop.Stop
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/stops [get]
+// @Security 	 BearerAuth
+func (h *Handler) shippingStops(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	queryParams := stop.QueryParams{Id: id}
+	if err := c.ShouldBind(&queryParams); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	if data, err := h.Usecase.Stop.ByShipping(queryParams); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ShippingOdometer godoc
+// @Summary      Shipping distance traveled
+// @Description  driven distance (km) and moving time (seconds) with daily breakdown compared with the route length (planned_distance, km), calculated in background up to checked_at
+// @Tags         shipping
+// @Accept       json
+// @Param        id          path    int     true  "id"		minimum(0)	maximum (32767)
+// @Success      200	{object}	odometer.ShippingOdometer
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/odometer [get]
+// @Security 	 BearerAuth
+func (h *Handler) shippingOdometer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.Odometer.ByShipping(id); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
 // ActiveShippingByModemImei godoc
 // @Summary      Get active shipping by modem imei
 // @Description  get active shipping by modem imei
@@ -1,31 +1,180 @@
 package v1
 
 import (
+	"errors"
+	"net/http"
+	"seal/internal/domain/upload_token"
+	"seal/pkg/app_error"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 )
 
+// Загрузка файлов перевозки водителями по ключу загрузки, без учётной записи
 func (h *Handler) registerShippingTempHandler(api *gin.RouterGroup) {
 	group := api.Group("/shipping-temp")
 	{
-		group.POST(":id/files/:type", h.shippingUploadFiles)
+		group.POST(":id/files/:type", h.shippingTempUploadFiles)
 	}
 }
 
-// UploadShippingFiles godoc
-// @Summary      Upload shipping files
-// @Description  upload shipping files
+// ShippingTempUploadFiles godoc
+// @Summary      Upload shipping files by upload token
+// @Description  upload shipping files without user account, token from POST /shipping/{id}/upload-tokens
 // @Tags         shipping-temp
 // @Accept       multipart/form-data
 // @Param        id       path     int     true  "id"		minimum(0)	maximum (32767)
 // @Param        type     path     int     true  "type" 	minimum(0)	maximum (3)
+// @Param        X-Upload-Token  header  string  true  "upload token"
+// @Param        seal_id  formData int     false "seal attached to the shipping"
+// @Param        comment  formData string  false "comment"
 // @Param        file   formData    []file true  "upload files"
-// @Success      200	{object}	shipping.Shipping
+// @Success      200	{object}	[]shipping.File
 // @Failure      400	{object}	app_error.AppError
 // @Failure      401	{object}	app_error.AppError
 // @Failure      422	{object}	app_error.AppError
 // @Failure      500	{object}	app_error.AppError
 // @Router       /shipping-temp/{id}/files/{type} [post]
+func (h *Handler) shippingTempUploadFiles(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	fileType, err := strconv.Atoi(c.Param("type"))
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	tokenId, err := h.Usecase.UploadToken.Acquire(c.GetHeader(upload_token.HEADER), id, fileType)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// водителю возвращаются только загруженные файлы, без данных перевозки
+	if _, files, ok := h.uploadShippingFiles(c, id, fileType, tokenId); ok {
+		c.JSON(http.StatusOK, files)
+	} else if err := h.Usecase.UploadToken.Release(tokenId); err != nil {
+		h.Logger.Error(err.Error())
+	}
+}
+
+// ShippingUploadTokenCreate godoc
+// @Summary      Create shipping upload token
+// @Description  time-limited token to upload files of given types to the shipping without user account
+// @Tags         shipping
+// @Accept       json
+// @Param        id       path     int     true  "id"		minimum(0)	maximum (32767)
+// @Param        request  body     upload_token.CreateRequest true "query params"
+// @Success      200	{object}	upload_token.Created
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/upload-tokens [post]
+// @Security 	 BearerAuth
+func (h *Handler) shippingUploadTokenCreate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	var fromRequest upload_token.CreateRequest
+
+	if err := c.ShouldBind(&fromRequest); err != nil {
+		h.Logger.Debug(err.Error())
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	userId := c.GetInt("userId")
+	if userId == 0 {
+		c.Error(app_error.InternalServerError(errors.New("can't get user")))
+		return
+	}
+
+	if data, err := h.Usecase.UploadToken.Create(id, fromRequest, userId); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
+
+// ShippingUploadTokens godoc
+// @Summary      Shipping upload tokens
+// @Description  upload tokens of the shipping, tokens themselves are not returned
+// @Tags         shipping
+// @Accept       json
+// @Param        id       path     int     true  "id"		minimum(0)	maximum (32767)
+// @Success      200	{object}	[]upload_token.UploadToken
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/upload-tokens [get]
 // @Security 	 BearerAuth
-func (h *Handler) f1(c *gin.Context) {
+func (h *Handler) shippingUploadTokens(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	if data, err := h.Usecase.UploadToken.ListByShipping(id); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
+}
 
+// ShippingUploadTokenRevoke godoc
+// @Summary      Revoke shipping upload token
+// @Description  revoke shipping upload token
+// @Tags         shipping
+// @Accept       json
+// @Param        id        path     int     true  "id"		minimum(0)	maximum (32767)
+// @Param        token_id  path     int     true  "upload token id"	minimum(0)
+// @Success      200	{object}	upload_token.UploadToken
+// @Failure      400	{object}	app_error.AppError
+// @Failure      401	{object}	app_error.AppError
+// @Failure      404	{object}	app_error.AppError
+// @Failure      422	{object}	app_error.AppError
+// @Failure      500	{object}	app_error.AppError
+// @Router       /shipping/{id}/upload-tokens/{token_id} [delete]
+// @Security 	 BearerAuth
+func (h *Handler) shippingUploadTokenRevoke(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	tokenId, err := strconv.Atoi(c.Param("token_id"))
+	if err != nil {
+		h.Logger.Error(err.Error())
+		c.Error(err)
+		return
+	}
+
+	userId := c.GetInt("userId")
+	if userId == 0 {
+		c.Error(app_error.InternalServerError(errors.New("can't get user")))
+		return
+	}
+
+	if data, err := h.Usecase.UploadToken.Revoke(id, tokenId, userId); err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, data)
+	}
 }
@@ -0,0 +1,271 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"seal/internal/app/usecase"
+	"seal/internal/domain/shipping"
+	"seal/internal/domain/upload_token"
+	"seal/internal/transport/commands/commandstest"
+	"seal/internal/transport/rest/middleware"
+	"seal/pkg/app_error"
+	"seal/pkg/track_export"
+	"seal/pkg/validator"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUploadToken = "secret"
+
+type fakeShipping struct {
+	shipping.Usecase
+	reason  string
+	userId  int
+	rows    []shipping.ImportRow
+	dryRun  bool
+	files   []shipping.File
+	uploads map[string]string
+}
+
+func (f *fakeShipping) GetDbById(id int) (shipping.Db, error) {
+	if id != 1 {
+		return shipping.Db{}, app_error.ErrNotFound
+	}
+
+	return shipping.Db{Id: 1, Status: shipping.STATUS_ACTIVE}, nil
+}
+
+func (f *fakeShipping) Suspend(sh shipping.Db, userId int, reason string) (shipping.Shipping, error) {
+	f.userId, f.reason = userId, reason
+	sh.Status = shipping.STATUS_SUSPENDED
+
+	return shipping.Shipping{Db: sh}, nil
+}
+
+func (f *fakeShipping) Resume(sh shipping.Db, userId int, reason string) (shipping.Shipping, error) {
+	return shipping.Shipping{}, app_error.ValidationError(`Действие "Возобновить" недоступно`)
+}
+
+func (f *fakeShipping) Import(rows []shipping.ImportRow, userId int, dryRun bool) (shipping.ImportResult, error) {
+	f.rows, f.dryRun = rows, dryRun
+	return shipping.ImportResult{DryRun: dryRun, Total: len(rows), Valid: len(rows)}, nil
+}
+
+func (f *fakeShipping) ExportTrack(params shipping.TrackQueryParams) (track_export.Track, error) {
+	return track_export.Track{Name: "Перевозка 1", Segments: [][]track_export.Point{{
+		{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), Latitude: 55.75, Longitude: 37.62},
+	}}}, nil
+}
+
+func (f *fakeShipping) CheckFileSeal(id int, sealId int) error {
+	return nil
+}
+
+func (f *fakeShipping) ValidateUploads(id int, fileType int, uploads []shipping.Upload) error {
+	return nil
+}
+
+func (f *fakeShipping) NewFileName(id int, title string) (string, error) {
+	return "stored_" + title, nil
+}
+
+func (f *fakeShipping) UploadFile(id int, name string, r io.Reader, size int64, contentType string) error {
+	content, err := io.ReadAll(r)
+	f.uploads[name] = string(content)
+
+	return err
+}
+
+func (f *fakeShipping) AddFiles(id int, files []shipping.File) (shipping.Shipping, error) {
+	f.files = append(f.files, files...)
+	return shipping.Shipping{}, nil
+}
+
+type fakeUploadToken struct {
+	upload_token.Usecase
+}
+
+func (f *fakeUploadToken) Acquire(token string, shippingId int, fileType int) (int, error) {
+	if token != testUploadToken {
+		return 0, app_error.ErrUnauthorized
+	}
+
+	return 5, nil
+}
+
+func (f *fakeUploadToken) Release(id int) error {
+	return nil
+}
+
+func newShippingRouter(ship *fakeShipping, token *fakeUploadToken) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	h := &Handler{
+		Usecase:   &usecase.Usecase{Shipping: ship, UploadToken: token},
+		Logger:    commandstest.Logger{},
+		Validator: validator.NewValidator(),
+	}
+
+	router := gin.New()
+	router.Use(middleware.Error())
+
+	api := router.Group("/v1")
+	h.registerShippingTempHandler(api)
+
+	api.Use(func(c *gin.Context) { c.Set("userId", 7) })
+	h.registerShippingHandler(api)
+
+	return router
+}
+
+func multipartBody(t *testing.T, fields map[string]string, fileName, content string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	for k, v := range fields {
+		require.NoError(t, w.WriteField(k, v))
+	}
+
+	fw, err := w.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return &body, w.FormDataContentType()
+}
+
+func TestShippingTempUpload(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		header string
+		code   int
+	}{
+		{"ключ в заголовке", "/v1/shipping-temp/1/files/1", testUploadToken, http.StatusOK},
+		{"ключ в адресе не принимается", "/v1/shipping-temp/1/files/1?token=" + testUploadToken, "", http.StatusUnauthorized},
+		{"неверный ключ", "/v1/shipping-temp/1/files/1", "wrong", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ship := &fakeShipping{uploads: map[string]string{}}
+			router := newShippingRouter(ship, &fakeUploadToken{})
+
+			body, contentType := multipartBody(t, map[string]string{"comment": "пломба"}, "seal.jpg", "jpeg")
+			req := httptest.NewRequest(http.MethodPost, tt.url, body)
+			req.Header.Set("Content-Type", contentType)
+			if tt.header != "" {
+				req.Header.Set(upload_token.HEADER, tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+
+			if tt.code != http.StatusOK {
+				assert.Empty(t, ship.files)
+				return
+			}
+
+			var files []shipping.File
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &files))
+			require.Len(t, files, 1)
+			assert.Equal(t, 5, files[0].UploadToken)
+			assert.Equal(t, "пломба", files[0].Comment)
+			assert.Equal(t, "jpeg", ship.uploads["stored_seal.jpg"])
+		})
+	}
+}
+
+func TestShippingTransit(t *testing.T) {
+	ship := &fakeShipping{}
+	router := newShippingRouter(ship, &fakeUploadToken{})
+
+	put := func(url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := put("/v1/shipping/1/suspend", `{"reason": "таможенный досмотр"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var data shipping.Shipping
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+	assert.Equal(t, shipping.STATUS_SUSPENDED, data.Status)
+	assert.Equal(t, "таможенный досмотр", ship.reason)
+	assert.Equal(t, 7, ship.userId)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, put("/v1/shipping/1/suspend", `{"reason": "`+strings.Repeat("a", 1025)+`"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, put("/v1/shipping/1/resume", "").Code)
+	assert.Equal(t, http.StatusNotFound, put("/v1/shipping/2/suspend", "").Code)
+}
+
+func TestShippingImport(t *testing.T) {
+	csv := "custom_number;create_date;number;transport;route\n00000001;010124;1;A001AA;Москва\n;;;;\n"
+
+	t.Run("csv", func(t *testing.T) {
+		ship := &fakeShipping{}
+		router := newShippingRouter(ship, &fakeUploadToken{})
+
+		body, contentType := multipartBody(t, map[string]string{"dry_run": "true"}, "import.csv", csv)
+		req := httptest.NewRequest(http.MethodPost, "/v1/shipping/import", body)
+		req.Header.Set("Content-Type", contentType)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, ship.dryRun)
+		require.Len(t, ship.rows, 1)
+		assert.Equal(t, shipping.ImportRow{Line: 2, CustomNumber: "00000001", CreateDate: "010124", Number: "1",
+			Transport: "A001AA", Route: "Москва"}, ship.rows[0])
+	})
+
+	t.Run("неподдерживаемый формат", func(t *testing.T) {
+		ship := &fakeShipping{}
+		router := newShippingRouter(ship, &fakeUploadToken{})
+
+		body, contentType := multipartBody(t, nil, "import.txt", csv)
+		req := httptest.NewRequest(http.MethodPost, "/v1/shipping/import", body)
+		req.Header.Set("Content-Type", contentType)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Nil(t, ship.rows)
+	})
+}
+
+func TestShippingExportTrack(t *testing.T) {
+	router := newShippingRouter(&fakeShipping{}, &fakeUploadToken{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/shipping/1/export/GPX", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/gpx+xml", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "shipping_1.gpx")
+	assert.Contains(t, w.Body.String(), `lat="55.750000"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/shipping/1/export/xyz", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
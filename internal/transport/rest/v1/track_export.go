@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"seal/pkg/app_error"
+	"seal/pkg/track_export"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func trackExportFormat(c *gin.Context) (string, error) {
+	format := strings.ToLower(c.Param("format"))
+
+	if !slices.Contains(track_export.Formats(), format) {
+		return "", app_error.ValidationError(map[string]string{
+			"format": fmt.Sprintf("Допустимые форматы: %s", strings.Join(track_export.Formats(), ", ")),
+		})
+	}
+
+	return format, nil
+}
+
+// writeTrack отдаёт трек файлом, записывая его в ответ по мере формирования
+func (h *Handler) writeTrack(c *gin.Context, format, fileName string, track track_export.Track) {
+	contentType, err := track_export.ContentType(format)
+	if err != nil {
+		c.Error(app_error.BadRequestError(err))
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fmt.Sprintf("%s.%s", fileName, format),
+	}))
+	c.Status(http.StatusOK)
+
+	if err := track_export.Write(c.Writer, format, track); err != nil {
+		h.Logger.Error(err.Error())
+	}
+}
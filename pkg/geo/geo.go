@@ -0,0 +1,18 @@
+package geo
+
+import "math"
+
+// EARTH_RADIUS средний радиус Земли, м
+const EARTH_RADIUS = 6371008.8
+
+// Distance расстояние между точками по большому кругу (гаверсинус), м
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+
+	return 2 * EARTH_RADIUS * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,79 @@
+package geo
+
+import "math"
+
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Simplify индексы точек ломаной, оставшихся после упрощения алгоритмом Дугласа-Пекера с допуском tolerance, м.
+// Первая, последняя и точки, для которых keep возвращает true, сохраняются всегда; упрощаются участки между ними
+func Simplify(points []Point, tolerance float64, keep func(i int) bool) []int {
+	marked := make([]bool, len(points))
+
+	for i := range points {
+		marked[i] = i == 0 || i == len(points)-1 || keep != nil && keep(i)
+	}
+
+	first := 0
+	for i := 1; i < len(points); i++ {
+		if marked[i] {
+			douglasPeucker(points, first, i, tolerance, marked)
+			first = i
+		}
+	}
+
+	result := make([]int, 0, len(points))
+	for i, ok := range marked {
+		if ok {
+			result = append(result, i)
+		}
+	}
+
+	return result
+}
+
+func douglasPeucker(points []Point, first, last int, tolerance float64, marked []bool) {
+	stack := [][2]int{{first, last}}
+
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if span[1]-span[0] < 2 {
+			continue
+		}
+
+		index, maxDistance := 0, 0.0
+		for i := span[0] + 1; i < span[1]; i++ {
+			if d := segmentDistance(points[i], points[span[0]], points[span[1]]); d > maxDistance {
+				index, maxDistance = i, d
+			}
+		}
+
+		if maxDistance > tolerance {
+			marked[index] = true
+			stack = append(stack, [2]int{span[0], index}, [2]int{index, span[1]})
+		}
+	}
+}
+
+// segmentDistance расстояние от точки p до отрезка ab, м. Для коротких отрезков трека достаточно
+// равнопромежуточной проекции с центром в точке a
+func segmentDistance(p, a, b Point) float64 {
+	k := math.Pi / 180 * EARTH_RADIUS
+	cos := math.Cos(a.Latitude * math.Pi / 180)
+
+	px, py := (p.Longitude-a.Longitude)*cos*k, (p.Latitude-a.Latitude)*k
+	bx, by := (b.Longitude-a.Longitude)*cos*k, (b.Latitude-a.Latitude)*k
+
+	length := bx*bx + by*by
+	if length == 0 {
+		return math.Hypot(px, py)
+	}
+
+	t := math.Max(0, math.Min(1, (px*bx+py*by)/length))
+
+	return math.Hypot(px-t*bx, py-t*by)
+}
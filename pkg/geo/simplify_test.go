@@ -0,0 +1,65 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// point точка, смещённая на east и north метров от (55, 37)
+func point(east, north float64) Point {
+	k := 180 / math.Pi / EARTH_RADIUS
+	return Point{Latitude: 55 + north*k, Longitude: 37 + east*k/math.Cos(55*math.Pi/180)}
+}
+
+func TestSimplify(t *testing.T) {
+	line := []Point{point(0, 0), point(100, 1), point(200, -1), point(300, 1), point(400, 0)}
+	spike := []Point{point(0, 0), point(100, 0), point(200, 100), point(300, 0), point(400, 0)}
+
+	tests := []struct {
+		name      string
+		points    []Point
+		tolerance float64
+		keep      func(i int) bool
+		want      []int
+	}{
+		{"нет точек", nil, 10, nil, []int{}},
+		{"одна точка", []Point{point(0, 0)}, 10, nil, []int{0}},
+		{"две точки", []Point{point(0, 0), point(100, 0)}, 10, nil, []int{0, 1}},
+		{"почти прямая", line, 10, nil, []int{0, 4}},
+		{"допуск меньше отклонений", line, 0.5, nil, []int{0, 1, 2, 3, 4}},
+		{"выброс больше допуска", spike, 50, nil, []int{0, 2, 4}},
+		{"выброс меньше допуска", spike, 150, nil, []int{0, 4}},
+		{"обязательная точка", line, 10, func(i int) bool { return i == 3 }, []int{0, 3, 4}},
+		{"упрощение между обязательными точками", spike, 150, func(i int) bool { return i == 1 }, []int{0, 1, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Simplify(tt.points, tt.tolerance, tt.keep))
+		})
+	}
+}
+
+func TestSegmentDistance(t *testing.T) {
+	a, b := point(0, 0), point(1000, 0)
+
+	tests := []struct {
+		name string
+		p    Point
+		a, b Point
+		want float64
+	}{
+		{"над серединой отрезка", point(500, 100), a, b, 100},
+		{"за концом отрезка", point(1300, 400), a, b, 500},
+		{"перед началом отрезка", point(-30, 40), a, b, 50},
+		{"вырожденный отрезок", point(30, 40), a, a, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, segmentDistance(tt.p, tt.a, tt.b), 0.5)
+		})
+	}
+}
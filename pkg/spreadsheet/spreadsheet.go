@@ -0,0 +1,73 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const FORMAT_CSV = "csv"
+const FORMAT_XLSX = "xlsx"
+
+var ErrUnknownFormat = errors.New("unknown spreadsheet format")
+
+// Supported поддерживается ли формат файла
+func Supported(format string) bool {
+	return format == FORMAT_CSV || format == FORMAT_XLSX
+}
+
+// Read строки таблицы из CSV (разделитель "," или ";") или первого листа XLSX
+func Read(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case FORMAT_CSV:
+		return readCsv(r)
+	case FORMAT_XLSX:
+		return readXlsx(r)
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func readCsv(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+
+	// BOM, который добавляет Excel при сохранении в UTF-8
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		br.Discard(3)
+	}
+
+	buffered, err := br.Peek(br.Buffered())
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	if line, _, _ := strings.Cut(string(buffered), "\n"); strings.Count(line, ";") > strings.Count(line, ",") {
+		reader.Comma = ';'
+	}
+
+	return reader.ReadAll()
+}
+
+func readXlsx(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+
+	return f.GetRows(sheets[0])
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type local struct {
+	root string
+}
+
+// NewLocal хранилище в каталоге root файловой системы
+func NewLocal(root string) Storage {
+	return &local{root}
+}
+
+func (s *local) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (s *local) Put(key string, r io.Reader, _ int64, _ string) error {
+	filePath := s.path(key)
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0750); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(filePath)
+		return err
+	}
+
+	return f.Close()
+}
+
+func (s *local) Get(key string) (io.ReadCloser, Info, error) {
+	info, err := s.Stat(key)
+	if err != nil {
+		return nil, info, err
+	}
+
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, info, ErrNotExist
+	}
+
+	return f, info, err
+}
+
+func (s *local) Stat(key string) (Info, error) {
+	stat, err := os.Stat(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) || err == nil && stat.IsDir() {
+		return Info{}, ErrNotExist
+	} else if err != nil {
+		return Info{}, err
+	}
+
+	return Info{Key: key, Size: stat.Size(), ModTime: stat.ModTime()}, nil
+}
+
+func (s *local) Exists(key string) (bool, error) {
+	if _, err := s.Stat(key); errors.Is(err, ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *local) Delete(key string) error {
+	if err := os.Remove(s.path(key)); errors.Is(err, fs.ErrNotExist) {
+		return ErrNotExist
+	} else {
+		return err
+	}
+}
+
+func (s *local) DeletePrefix(prefix string) error {
+	if path.Clean("/"+prefix) == "/" {
+		return errors.New("storage: empty prefix")
+	}
+
+	return os.RemoveAll(s.path(prefix))
+}
+
+func (s *local) List(prefix string) ([]Info, error) {
+	var list []Info
+
+	err := filepath.WalkDir(s.root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && filePath == s.root {
+				return fs.SkipAll
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		stat, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		list = append(list, Info{Key: key, Size: stat.Size(), ModTime: stat.ModTime()})
+
+		return nil
+	})
+
+	return list, err
+}
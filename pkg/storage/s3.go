@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Params struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	Prefix    string
+	UseSSL    bool
+}
+
+type s3 struct {
+	ctx    context.Context
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3 хранилище в S3-совместимом объектном хранилище (MinIO, Ceph, Yandex Object Storage и т.п.).
+// Ключи хранятся с префиксом params.Prefix
+func NewS3(ctx context.Context, params S3Params) (Storage, error) {
+	client, err := minio.New(params.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(params.AccessKey, params.SecretKey, ""),
+		Secure: params.UseSSL,
+		Region: params.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3{ctx, client, params.Bucket, strings.Trim(params.Prefix, "/")}, nil
+}
+
+func (s *s3) key(key string) string {
+	return strings.TrimPrefix(path.Join(s.prefix, path.Clean("/"+key)), "/")
+}
+
+func (s *s3) Put(key string, r io.Reader, size int64, contentType string) error {
+	if size <= 0 {
+		size = -1
+	}
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	_, err := s.client.PutObject(s.ctx, s.bucket, s.key(key), r, size, minio.PutObjectOptions{ContentType: contentType})
+
+	return err
+}
+
+func (s *s3) Get(key string) (io.ReadCloser, Info, error) {
+	info, err := s.Stat(key)
+	if err != nil {
+		return nil, info, err
+	}
+
+	object, err := s.client.GetObject(s.ctx, s.bucket, s.key(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, info, notExist(err)
+	}
+
+	return object, info, nil
+}
+
+func (s *s3) Stat(key string) (Info, error) {
+	stat, err := s.client.StatObject(s.ctx, s.bucket, s.key(key), minio.StatObjectOptions{})
+	if err != nil {
+		return Info{}, notExist(err)
+	}
+
+	return Info{Key: key, Size: stat.Size, ModTime: stat.LastModified}, nil
+}
+
+func (s *s3) Exists(key string) (bool, error) {
+	if _, err := s.Stat(key); errors.Is(err, ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *s3) Delete(key string) error {
+	return s.client.RemoveObject(s.ctx, s.bucket, s.key(key), minio.RemoveObjectOptions{})
+}
+
+func (s *s3) DeletePrefix(prefix string) error {
+	list, err := s.List(prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range list {
+		if err := s.Delete(info.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *s3) List(prefix string) ([]Info, error) {
+	var list []Info
+
+	root := ""
+	if s.prefix != "" {
+		root = s.prefix + "/"
+	}
+
+	for object := range s.client.ListObjects(s.ctx, s.bucket, minio.ListObjectsOptions{Prefix: root + prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+
+		list = append(list, Info{Key: strings.TrimPrefix(object.Key, root), Size: object.Size, ModTime: object.LastModified})
+	}
+
+	return list, nil
+}
+
+func notExist(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotExist
+	}
+
+	return err
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+const TYPE_LOCAL = "local"
+const TYPE_S3 = "s3"
+
+var ErrNotExist = errors.New("storage: object does not exist")
+var ErrUnknownType = errors.New("storage: unknown type")
+
+type Info struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Storage хранилище файлов. Ключ - путь вида <каталог>/<имя>, разделитель "/" для всех реализаций
+type Storage interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, Info, error)
+	Stat(key string) (Info, error)
+	Exists(key string) (bool, error)
+	Delete(key string) error
+	DeletePrefix(prefix string) error
+	List(prefix string) ([]Info, error)
+}
+
+type Params struct {
+	Type string
+	Path string
+	S3   S3Params
+}
+
+// New хранилище по типу: TYPE_LOCAL - каталог params.Path, TYPE_S3 - объектное хранилище params.S3
+func New(ctx context.Context, params Params) (Storage, error) {
+	switch params.Type {
+	case TYPE_LOCAL, "":
+		return NewLocal(params.Path), nil
+	case TYPE_S3:
+		return NewS3(ctx, params.S3)
+	}
+
+	return nil, ErrUnknownType
+}
+
+// Migrate копирует все объекты с префиксом prefix из from в to. Если remove, скопированные объекты удаляются из from
+func Migrate(from, to Storage, prefix string, remove bool, log func(key string, err error)) (int, error) {
+	list, err := from.List(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, info := range list {
+		err := copyObject(from, to, info)
+		if err == nil && remove {
+			err = from.Delete(info.Key)
+		}
+
+		log(info.Key, err)
+
+		if err == nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func copyObject(from, to Storage, info Info) error {
+	r, info, err := from.Get(info.Key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return to.Put(info.Key, r, info.Size, "")
+}
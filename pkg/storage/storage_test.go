@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"seal/pkg/storage/storagetest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkStorage общая проверка реализаций Storage
+func checkStorage(t *testing.T, s Storage) {
+	list, err := s.List("")
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, s.Put("1/a.txt", strings.NewReader("aaa"), 3, "text/plain"))
+	require.NoError(t, s.Put("1/b.txt", strings.NewReader("bb"), 2, ""))
+	require.NoError(t, s.Put("2/c.txt", strings.NewReader("c"), 1, ""))
+
+	r, info, err := s.Get("1/a.txt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "aaa", string(data))
+	assert.Equal(t, Info{Key: "1/a.txt", Size: 3, ModTime: info.ModTime}, info)
+
+	info, err = s.Stat("1/b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), info.Size)
+
+	exists, err := s.Exists("1/b.txt")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = s.Exists("1/none.txt")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, _, err = s.Get("1/none.txt")
+	assert.ErrorIs(t, err, ErrNotExist)
+
+	_, err = s.Stat("1")
+	assert.ErrorIs(t, err, ErrNotExist, "каталог не объект")
+
+	list, err = s.List("1/")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1/a.txt", "1/b.txt"}, keys(list))
+
+	assert.NoError(t, s.Delete("1/a.txt"))
+	exists, _ = s.Exists("1/a.txt")
+	assert.False(t, exists)
+
+	assert.NoError(t, s.DeletePrefix("1"))
+	list, err = s.List("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2/c.txt"}, keys(list))
+}
+
+func keys(list []Info) []string {
+	res := []string{}
+	for _, info := range list {
+		res = append(res, info.Key)
+	}
+
+	return res
+}
+
+func TestLocal(t *testing.T) {
+	root := t.TempDir()
+	s := NewLocal(filepath.Join(root, "files"))
+
+	checkStorage(t, s)
+
+	t.Run("ключ не выходит за корень", func(t *testing.T) {
+		require.NoError(t, s.Put("../../escape.txt", strings.NewReader("x"), 1, ""))
+
+		_, err := os.Stat(filepath.Join(root, "escape.txt"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		_, err = os.Stat(filepath.Join(root, "files", "escape.txt"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("пустой префикс не удаляется", func(t *testing.T) {
+		assert.Error(t, s.DeletePrefix("/"))
+	})
+
+	t.Run("удаление отсутствующего", func(t *testing.T) {
+		assert.ErrorIs(t, s.Delete("none.txt"), ErrNotExist)
+	})
+}
+
+func TestS3(t *testing.T) {
+	server := storagetest.NewS3()
+	defer server.Close()
+
+	s, err := New(context.Background(), Params{Type: TYPE_S3, S3: S3Params{
+		Endpoint: server.Endpoint(), AccessKey: "key", SecretKey: "secret", Bucket: "files", Region: "us-east-1", Prefix: "/shipping/",
+	}})
+	require.NoError(t, err)
+
+	checkStorage(t, s)
+	assert.Equal(t, []string{"shipping/2/c.txt"}, server.Keys("files"), "ключи хранятся с префиксом")
+}
+
+func TestMigrate(t *testing.T) {
+	server := storagetest.NewS3()
+	defer server.Close()
+
+	from := NewLocal(t.TempDir())
+	to, err := NewS3(context.Background(), S3Params{Endpoint: server.Endpoint(), Bucket: "files", Region: "us-east-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, from.Put("1/a.txt", strings.NewReader("aaa"), 3, ""))
+	require.NoError(t, from.Put("2/b.txt", strings.NewReader("bb"), 2, ""))
+
+	var logged []string
+	count, err := Migrate(from, to, "", true, func(key string, err error) {
+		assert.NoError(t, err)
+		logged = append(logged, key)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.ElementsMatch(t, []string{"1/a.txt", "2/b.txt"}, logged)
+	assert.Equal(t, []string{"1/a.txt", "2/b.txt"}, server.Keys("files"))
+
+	list, err := from.List("")
+	assert.NoError(t, err)
+	assert.Empty(t, list, "перенесённые файлы удалены")
+
+	_, err = New(context.Background(), Params{Type: "ftp"})
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
@@ -0,0 +1,176 @@
+// Package storagetest поднимает поддельное S3-совместимое хранилище на httptest
+// для проверки storage.NewS3 без сети и настоящего сервиса.
+// Поддерживаются только запросы, которые выполняет storage: put, get, head, delete объекта и list-type=2
+package storagetest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type object struct {
+	data    []byte
+	modTime time.Time
+}
+
+// S3 хранилище в памяти, ключ объекта - "<bucket>/<key>"
+type S3 struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	objects map[string]object
+}
+
+func NewS3() *S3 {
+	s := &S3{objects: map[string]object{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// Endpoint адрес для storage.S3Params.Endpoint, без схемы
+func (s *S3) Endpoint() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// Keys ключи объектов бакета по возрастанию
+func (s *S3) Keys(bucket string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []string
+	for k := range s.objects {
+		if key, ok := strings.CutPrefix(k, bucket+"/"); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (s *S3) handle(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && key == "" && r.URL.Query().Get("list-type") == "2":
+		s.list(w, bucket, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodPut && key != "":
+		data, err := readBody(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.objects[bucket+"/"+key] = object{data, time.Now().UTC().Truncate(time.Second)}
+		w.Header().Set("ETag", `"etag"`)
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && key != "":
+		obj, ok := s.objects[bucket+"/"+key]
+		if !ok {
+			notFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("Last-Modified", obj.modTime.Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case r.Method == http.MethodDelete && key != "":
+		delete(s.objects, bucket+"/"+key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (s *S3) list(w http.ResponseWriter, bucket, prefix string) {
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+		StorageClass string
+	}
+
+	result := struct {
+		XMLName     xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		MaxKeys     int
+		IsTruncated bool
+		Contents    []content
+	}{Name: bucket, Prefix: prefix, MaxKeys: 1000}
+
+	var keys []string
+	for k := range s.objects {
+		if key, ok := strings.CutPrefix(k, bucket+"/"); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		obj := s.objects[bucket+"/"+key]
+		result.Contents = append(result.Contents, content{key, obj.modTime.Format("2006-01-02T15:04:05.000Z"), `"etag"`, len(obj.data), "STANDARD"})
+	}
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotFound)
+	if r.Method != http.MethodHead {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+	}
+}
+
+// readBody тело запроса, клиент по http отправляет его в aws-chunked: <размер>;chunk-signature=...\r\n<данные>\r\n
+func readBody(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.ParseInt(strings.TrimSpace(strings.Split(line, ";")[0]), 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package track_export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+)
+
+// writeGeoJson пишет FeatureCollection: маршрут - LineString, трек - MultiLineString,
+// время точек передаётся в свойстве coordTimes по участкам
+func writeGeoJson(w *bufio.Writer, track Track) error {
+	name, err := json.Marshal(track.Name)
+	if err != nil {
+		return err
+	}
+
+	w.WriteString(`{"type":"FeatureCollection","features":[`)
+
+	if len(track.Route) > 0 {
+		w.WriteString(`{"type":"Feature","properties":{"layer":"route","name":"Маршрут"},"geometry":{"type":"LineString","coordinates":[`)
+		for i, p := range track.Route {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "[%f,%f]", p.Longitude, p.Latitude)
+		}
+		w.WriteString("]}},\n")
+	}
+
+	fmt.Fprintf(w, `{"type":"Feature","properties":{"layer":"track","name":%s,"coordTimes":[`, name)
+	for i, segment := range track.Segments {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteByte('[')
+		for j, p := range segment {
+			if j > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `"%s"`, p.Time.UTC().Format(timeFormat))
+		}
+		w.WriteByte(']')
+	}
+
+	w.WriteString(`]},"geometry":{"type":"MultiLineString","coordinates":[`)
+	for i, segment := range track.Segments {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteByte('[')
+		for j, p := range segment {
+			if j > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "[%f,%f,%d]", p.Longitude, p.Latitude, p.Altitude)
+		}
+		w.WriteString("]\n")
+	}
+
+	_, err = w.WriteString("]}}]}\n")
+
+	return err
+}
@@ -0,0 +1,42 @@
+package track_export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+func writeGpx(w *bufio.Writer, track Track) error {
+	w.WriteString(xml.Header)
+	w.WriteString(`<gpx version="1.1" creator="seal" xmlns="http://www.topografix.com/GPX/1/1">` + "\n")
+
+	w.WriteString("<metadata><name>")
+	xml.EscapeText(w, []byte(track.Name))
+	fmt.Fprintf(w, "</name><time>%s</time></metadata>\n", time.Now().UTC().Format(timeFormat))
+
+	if len(track.Route) > 0 {
+		w.WriteString("<rte><name>Маршрут</name>\n")
+		for _, p := range track.Route {
+			fmt.Fprintf(w, `<rtept lat="%f" lon="%f"></rtept>`+"\n", p.Latitude, p.Longitude)
+		}
+		w.WriteString("</rte>\n")
+	}
+
+	w.WriteString("<trk><name>")
+	xml.EscapeText(w, []byte(track.Name))
+	w.WriteString("</name>\n")
+
+	for _, segment := range track.Segments {
+		w.WriteString("<trkseg>\n")
+		for _, p := range segment {
+			fmt.Fprintf(w, `<trkpt lat="%f" lon="%f"><ele>%d</ele><time>%s</time></trkpt>`+"\n",
+				p.Latitude, p.Longitude, p.Altitude, p.Time.UTC().Format(timeFormat))
+		}
+		w.WriteString("</trkseg>\n")
+	}
+
+	_, err := w.WriteString("</trk>\n</gpx>\n")
+
+	return err
+}
@@ -0,0 +1,43 @@
+package track_export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+)
+
+func writeKml(w *bufio.Writer, track Track) error {
+	w.WriteString(xml.Header)
+	w.WriteString(`<kml xmlns="http://www.opengis.net/kml/2.2">` + "\n<Document><name>")
+	xml.EscapeText(w, []byte(track.Name))
+	w.WriteString("</name>\n")
+
+	if len(track.Route) > 0 {
+		w.WriteString("<Folder><name>Маршрут</name><Placemark><name>Маршрут</name><LineString><tessellate>1</tessellate><coordinates>\n")
+		for _, p := range track.Route {
+			fmt.Fprintf(w, "%f,%f\n", p.Longitude, p.Latitude)
+		}
+		w.WriteString("</coordinates></LineString></Placemark></Folder>\n")
+	}
+
+	w.WriteString("<Folder><name>Трек</name>\n")
+
+	for i, segment := range track.Segments {
+		fmt.Fprintf(w, "<Placemark><name>Участок %d</name>", i+1)
+
+		if len(segment) > 0 {
+			fmt.Fprintf(w, "<TimeSpan><begin>%s</begin><end>%s</end></TimeSpan>",
+				segment[0].Time.UTC().Format(timeFormat), segment[len(segment)-1].Time.UTC().Format(timeFormat))
+		}
+
+		w.WriteString("<LineString><tessellate>1</tessellate><coordinates>\n")
+		for _, p := range segment {
+			fmt.Fprintf(w, "%f,%f,%d\n", p.Longitude, p.Latitude, p.Altitude)
+		}
+		w.WriteString("</coordinates></LineString></Placemark>\n")
+	}
+
+	_, err := w.WriteString("</Folder>\n</Document>\n</kml>\n")
+
+	return err
+}
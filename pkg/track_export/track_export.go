@@ -0,0 +1,74 @@
+package track_export
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"time"
+)
+
+const FORMAT_GPX = "gpx"
+const FORMAT_KML = "kml"
+const FORMAT_GEOJSON = "geojson"
+
+const timeFormat = time.RFC3339
+
+var ErrUnknownFormat = errors.New("unknown track format")
+
+type Point struct {
+	Time      time.Time
+	Latitude  float64
+	Longitude float64
+	Altitude  int32
+}
+
+// Track трек для выгрузки. Segments - непрерывные участки движения,
+// разрывы между ними соответствуют секретным зонам и пропускам координат.
+// Route - плановый маршрут, выгружается отдельным слоем
+type Track struct {
+	Name     string
+	Segments [][]Point
+	Route    []Point
+}
+
+var contentTypes = map[string]string{
+	FORMAT_GPX:     "application/gpx+xml",
+	FORMAT_KML:     "application/vnd.google-earth.kml+xml",
+	FORMAT_GEOJSON: "application/geo+json",
+}
+
+func Formats() []string {
+	return []string{FORMAT_GPX, FORMAT_KML, FORMAT_GEOJSON}
+}
+
+func ContentType(format string) (string, error) {
+	if t, ok := contentTypes[format]; ok {
+		return t, nil
+	}
+
+	return "", ErrUnknownFormat
+}
+
+// Write последовательно пишет трек в w в указанном формате, не собирая документ в памяти
+func Write(w io.Writer, format string, track Track) error {
+	bw := bufio.NewWriter(w)
+
+	var err error
+
+	switch format {
+	case FORMAT_GPX:
+		err = writeGpx(bw, track)
+	case FORMAT_KML:
+		err = writeKml(bw, track)
+	case FORMAT_GEOJSON:
+		err = writeGeoJson(bw, track)
+	default:
+		return ErrUnknownFormat
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}